@@ -0,0 +1,186 @@
+package analytics
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+// day returns the given day of January 2015 at noon, the 5th is a Monday.
+func day(d int) *time.Time {
+	t := time.Date(2015, 1, d, 12, 0, 0, 0, time.UTC)
+	return &t
+}
+
+var testingItems = []sprintly.Item{
+	{
+		Number:    1,
+		Type:      "story",
+		Score:     sprintly.ItemScoreMedium,
+		Status:    sprintly.ItemStatusAccepted,
+		Tags:      []string{"api"},
+		CreatedAt: day(1),
+		Progress: &sprintly.ItemProgress{
+			TriagedAt:  day(2),
+			StartedAt:  day(5),
+			ClosedAt:   day(7),
+			AcceptedAt: day(8),
+		},
+		AssignedTo: &sprintly.User{Email: "joe@joestump.net"},
+	},
+	{
+		Number:    2,
+		Type:      "task",
+		Score:     sprintly.ItemScoreSmall,
+		Status:    sprintly.ItemStatusCompleted,
+		Tags:      []string{"api", "ui"},
+		CreatedAt: day(3),
+		Progress: &sprintly.ItemProgress{
+			StartedAt: day(6),
+			ClosedAt:  day(13),
+		},
+	},
+	{
+		Number:    3,
+		Type:      "task",
+		Score:     sprintly.ItemScoreLarge,
+		Status:    sprintly.ItemStatusCompleted,
+		CreatedAt: day(4),
+		Progress: &sprintly.ItemProgress{
+			StartedAt: day(10),
+			ClosedAt:  day(20),
+		},
+	},
+	{
+		Number:    4,
+		Type:      "defect",
+		Status:    sprintly.ItemStatusInProgress,
+		CreatedAt: day(4),
+		Progress:  &sprintly.ItemProgress{StartedAt: day(6)},
+	},
+}
+
+func midnight(d int) time.Time {
+	return time.Date(2015, 1, d, 0, 0, 0, 0, time.UTC)
+}
+
+const dayDuration = 24 * time.Hour
+
+func TestStatusAt(t *testing.T) {
+	item := &testingItems[0]
+	tests := []struct {
+		at   *time.Time
+		want sprintly.ItemStatus
+	}{
+		{day(0), ""},
+		{day(1), sprintly.ItemStatusSomeday},
+		{day(3), sprintly.ItemStatusBacklog},
+		{day(6), sprintly.ItemStatusInProgress},
+		{day(7), sprintly.ItemStatusCompleted},
+		{day(30), sprintly.ItemStatusAccepted},
+	}
+	for _, test := range tests {
+		if got := StatusAt(item, *test.at); got != test.want {
+			t.Errorf("StatusAt(%v) = %q, want %q", test.at.Day(), got, test.want)
+		}
+	}
+
+	// Items without TriagedAt are created in the backlog.
+	if got := StatusAt(&testingItems[1], *day(4)); got != sprintly.ItemStatusBacklog {
+		t.Errorf("StatusAt = %q, want backlog", got)
+	}
+}
+
+func TestFlow(t *testing.T) {
+	flow := Flow(slices.Values(testingItems))
+
+	if flow.Items != 4 {
+		t.Errorf("Items = %v, want 4", flow.Items)
+	}
+	if flow.LeadTime.Count != 3 || flow.LeadTime.P50 != 10*dayDuration || flow.LeadTime.Max != 16*dayDuration {
+		t.Errorf("LeadTime = %+v", flow.LeadTime)
+	}
+	if flow.CycleTime.Min != 2*dayDuration || flow.CycleTime.P95 != 10*dayDuration {
+		t.Errorf("CycleTime = %+v", flow.CycleTime)
+	}
+
+	inProgress := flow.TimeInStatus[sprintly.ItemStatusInProgress]
+	if inProgress.Count != 3 || inProgress.Mean != (2+7+10)*dayDuration/3 {
+		t.Errorf("TimeInStatus[in-progress] = %+v", inProgress)
+	}
+	if someday := flow.TimeInStatus[sprintly.ItemStatusSomeday]; someday.Count != 1 || someday.Max != dayDuration {
+		t.Errorf("TimeInStatus[someday] = %+v", someday)
+	}
+}
+
+func TestFlowBy(t *testing.T) {
+	flow := FlowBy(slices.Values(testingItems), ByTag)
+	if flow["api"].Items != 2 || flow["ui"].Items != 1 || flow["untagged"].Items != 2 {
+		t.Errorf("unexpected groups: api %v, ui %v, untagged %v", flow["api"].Items, flow["ui"].Items, flow["untagged"].Items)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	var samples []time.Duration
+	for i := 10; i >= 1; i-- {
+		samples = append(samples, time.Duration(i))
+	}
+	dist := NewDistribution(samples)
+
+	tests := map[float64]time.Duration{0: 1, 10: 1, 50: 5, 85: 9, 95: 10, 100: 10}
+	for p, want := range tests {
+		if got := dist.Percentile(p); got != want {
+			t.Errorf("Percentile(%v) = %v, want %v", p, got, want)
+		}
+	}
+	if samples[0] != 10 {
+		t.Error("NewDistribution modified the samples")
+	}
+}
+
+func TestVelocityBy(t *testing.T) {
+	velocity := VelocityBy(slices.Values(testingItems), ByType, nil)
+
+	want := map[string][]Week{
+		"story": {
+			{Start: midnight(5), Items: 1, Points: 3},
+			{Start: midnight(12)},
+			{Start: midnight(19)},
+		},
+		"task": {
+			{Start: midnight(5)},
+			{Start: midnight(12), Items: 1, Points: 1},
+			{Start: midnight(19), Items: 1, Points: 5},
+		},
+	}
+	if len(velocity) != len(want) {
+		t.Fatalf("got %v groups, want %v", len(velocity), len(want))
+	}
+	for group, weeks := range want {
+		if !slices.Equal(velocity[group], weeks) {
+			t.Errorf("velocity[%v] = %+v, want %+v", group, velocity[group], weeks)
+		}
+	}
+
+	if total := Velocity(slices.Values(testingItems), nil); len(total) != 3 || total[0].Points != 3 {
+		t.Errorf("Velocity = %+v", total)
+	}
+}
+
+func TestWeekStart(t *testing.T) {
+	prague, err := time.LoadLocation("Europe/Prague")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	// Sunday late evening UTC is Monday in Prague.
+	at := time.Date(2015, 1, 11, 23, 30, 0, 0, time.UTC)
+	if got := WeekStart(at, time.UTC); !got.Equal(midnight(5)) {
+		t.Errorf("WeekStart(UTC) = %v", got)
+	}
+	if got := WeekStart(at, prague); !got.Equal(time.Date(2015, 1, 12, 0, 0, 0, 0, prague)) {
+		t.Errorf("WeekStart(Prague) = %v", got)
+	}
+}
@@ -0,0 +1,61 @@
+package analytics
+
+import (
+	"math"
+	"slices"
+	"time"
+)
+
+// Distribution summarises a set of durations.
+type Distribution struct {
+	Count int           `json:"count"`
+	Min   time.Duration `json:"min"`
+	Max   time.Duration `json:"max"`
+	Mean  time.Duration `json:"mean"`
+	P50   time.Duration `json:"p50"`
+	P75   time.Duration `json:"p75"`
+	P85   time.Duration `json:"p85"`
+	P95   time.Duration `json:"p95"`
+
+	samples []time.Duration
+}
+
+// NewDistribution computes the distribution of the given durations.
+// The slice is not modified.
+func NewDistribution(samples []time.Duration) Distribution {
+	if len(samples) == 0 {
+		return Distribution{}
+	}
+
+	sorted := slices.Clone(samples)
+	slices.Sort(sorted)
+
+	var sum float64
+	for _, d := range sorted {
+		sum += float64(d)
+	}
+
+	dist := Distribution{
+		Count:   len(sorted),
+		Min:     sorted[0],
+		Max:     sorted[len(sorted)-1],
+		Mean:    time.Duration(sum / float64(len(sorted))),
+		samples: sorted,
+	}
+	dist.P50 = dist.Percentile(50)
+	dist.P75 = dist.Percentile(75)
+	dist.P85 = dist.Percentile(85)
+	dist.P95 = dist.Percentile(95)
+	return dist
+}
+
+// Percentile returns the p-th percentile using the nearest-rank method,
+// i.e. the smallest duration not exceeded by p percent of the samples.
+func (dist Distribution) Percentile(p float64) time.Duration {
+	if len(dist.samples) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(dist.samples))))
+	rank = min(max(rank, 1), len(dist.samples))
+	return dist.samples[rank-1]
+}
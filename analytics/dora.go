@@ -0,0 +1,191 @@
+package analytics
+
+import (
+	"iter"
+	"slices"
+	"time"
+
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+// DefaultFailureWindow is the time after a deploy in which a new defect marks it failed.
+const DefaultFailureWindow = 72 * time.Hour
+
+// DORAOptions configure the DORA metrics computation.
+type DORAOptions struct {
+	// Environment selects the deploys, usually production. Required.
+	Environment string
+
+	// From and To delimit the period reported, From being inclusive and To
+	// exclusive. The first deploy time and the current time are used when zero,
+	// the period is empty when From is zero and there are no deploys.
+	From time.Time
+	To   time.Time
+
+	// Window splits the period into windows of the given length, e.g. a week.
+	// Only the whole period is reported when zero.
+	Window time.Duration
+
+	// FailureWindow is the time after a deploy in which a newly opened defect
+	// counts as a failure of the deploy, DefaultFailureWindow when zero.
+	// The failure window never reaches past the next deploy.
+	FailureWindow time.Duration
+}
+
+// DORAMetrics represent the DORA metrics of a period.
+type DORAMetrics struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+
+	// Deploys is the number of deploys, DeploysPerDay the deployment frequency.
+	Deploys       int     `json:"deploys"`
+	DeploysPerDay float64 `json:"deploys_per_day"`
+
+	// LeadTime measures the time from an item being started, or created when
+	// it was never started, to its first deploy to the environment.
+	LeadTime Distribution `json:"lead_time"`
+
+	// FailedDeploys is the number of deploys followed by a new defect,
+	// ChangeFailureRate their share of all the deploys.
+	FailedDeploys     int     `json:"failed_deploys"`
+	ChangeFailureRate float64 `json:"change_failure_rate"`
+}
+
+// DORAReport holds the DORA metrics of the whole period and of every window.
+type DORAReport struct {
+	Environment string         `json:"environment"`
+	Overall     *DORAMetrics   `json:"overall"`
+	Windows     []*DORAMetrics `json:"windows,omitempty"`
+}
+
+// DORA computes the deployment frequency, the lead time for changes and
+// the change failure rate from the deploys and the items of a product.
+//
+// The items are used to look up the progress of the items deployed, falling
+// back to the version recorded by the deploy, and to find the defects opened
+// after the deploys. Deploys without a time are ignored.
+func DORA(deploys []sprintly.Deploy, items iter.Seq[sprintly.Item], opts *DORAOptions) *DORAReport {
+	failureWindow := opts.FailureWindow
+	if failureWindow <= 0 {
+		failureWindow = DefaultFailureWindow
+	}
+
+	var selected []sprintly.Deploy
+	for _, deploy := range deploys {
+		if deploy.Environment == opts.Environment && deploy.CreatedAt != nil {
+			selected = append(selected, deploy)
+		}
+	}
+	slices.SortStableFunc(selected, func(a, b sprintly.Deploy) int {
+		return a.CreatedAt.Compare(*b.CreatedAt)
+	})
+
+	from, to := opts.From, opts.To
+	if from.IsZero() && len(selected) != 0 {
+		from = *selected[0].CreatedAt
+	}
+	if to.IsZero() {
+		to = time.Now()
+	}
+	// Without any deploy, the period would start at the zero time.
+	if from.IsZero() {
+		from = to
+	}
+
+	byNumber := make(map[int]sprintly.Item)
+	var defects []time.Time
+	for item := range items {
+		byNumber[item.Number] = item
+		if item.Type == string(sprintly.ItemTypeDefect) && item.CreatedAt != nil {
+			defects = append(defects, *item.CreatedAt)
+		}
+	}
+
+	// Evaluate every deploy: the lead times of the items deployed
+	// for the first time and whether it failed.
+	type deployStats struct {
+		at        time.Time
+		leadTimes []time.Duration
+		failed    bool
+	}
+	var (
+		stats    []deployStats
+		deployed = make(map[int]bool)
+	)
+	for i, deploy := range selected {
+		ds := deployStats{at: *deploy.CreatedAt}
+
+		for _, item := range deploy.Items {
+			if deployed[item.Number] {
+				continue
+			}
+			deployed[item.Number] = true
+
+			if current, ok := byNumber[item.Number]; ok {
+				item = current
+			}
+			if start := changeStart(&item); start != nil && !start.After(ds.at) {
+				ds.leadTimes = append(ds.leadTimes, ds.at.Sub(*start))
+			}
+		}
+
+		until := ds.at.Add(failureWindow)
+		if i+1 < len(selected) && selected[i+1].CreatedAt.Before(until) {
+			until = *selected[i+1].CreatedAt
+		}
+		for _, opened := range defects {
+			if opened.After(ds.at) && !opened.After(until) {
+				ds.failed = true
+				break
+			}
+		}
+
+		stats = append(stats, ds)
+	}
+
+	metrics := func(from, to time.Time) *DORAMetrics {
+		m := &DORAMetrics{From: from, To: to}
+		var leadTimes []time.Duration
+		for _, ds := range stats {
+			if ds.at.Before(from) || !ds.at.Before(to) {
+				continue
+			}
+			m.Deploys++
+			leadTimes = append(leadTimes, ds.leadTimes...)
+			if ds.failed {
+				m.FailedDeploys++
+			}
+		}
+		if days := to.Sub(from).Hours() / 24; days > 0 {
+			m.DeploysPerDay = float64(m.Deploys) / days
+		}
+		if m.Deploys != 0 {
+			m.ChangeFailureRate = float64(m.FailedDeploys) / float64(m.Deploys)
+		}
+		m.LeadTime = NewDistribution(leadTimes)
+		return m
+	}
+
+	report := &DORAReport{
+		Environment: opts.Environment,
+		Overall:     metrics(from, to),
+	}
+	if opts.Window > 0 {
+		for start := from; start.Before(to); start = start.Add(opts.Window) {
+			end := start.Add(opts.Window)
+			if end.After(to) {
+				end = to
+			}
+			report.Windows = append(report.Windows, metrics(start, end))
+		}
+	}
+	return report
+}
+
+// changeStart returns when the work on the item started.
+func changeStart(item *sprintly.Item) *time.Time {
+	if item.Progress != nil && item.Progress.StartedAt != nil {
+		return item.Progress.StartedAt
+	}
+	return item.CreatedAt
+}
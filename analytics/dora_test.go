@@ -0,0 +1,86 @@
+package analytics
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+func TestDORA(t *testing.T) {
+	// Deploys on the 6th, 8th and 13th, the last one to staging only.
+	deploys := []sprintly.Deploy{
+		{Environment: "production", CreatedAt: day(8), Items: []sprintly.Item{{Number: 2}, {Number: 1}}},
+		{Environment: "production", CreatedAt: day(6), Items: []sprintly.Item{{Number: 1}}},
+		{Environment: "staging", CreatedAt: day(13), Items: []sprintly.Item{{Number: 3}}},
+		{Environment: "production", Items: []sprintly.Item{{Number: 3}}},
+	}
+	items := []sprintly.Item{
+		{Number: 1, Type: "story", CreatedAt: day(1), Progress: &sprintly.ItemProgress{StartedAt: day(5)}},
+		{Number: 2, Type: "task", CreatedAt: day(4)},
+		// Opened a day after the first deploy, but after the second one too.
+		{Number: 3, Type: "defect", CreatedAt: day(9)},
+	}
+
+	report := DORA(deploys, slices.Values(items), &DORAOptions{
+		Environment: "production",
+		From:        midnight(5),
+		To:          midnight(19),
+		Window:      7 * dayDuration,
+	})
+
+	overall := report.Overall
+	if overall.Deploys != 2 || overall.DeploysPerDay != 2.0/14 {
+		t.Errorf("deployment frequency = %v deploys, %v per day", overall.Deploys, overall.DeploysPerDay)
+	}
+	if overall.LeadTime.Count != 2 || overall.LeadTime.Min != dayDuration || overall.LeadTime.Max != 4*dayDuration {
+		t.Errorf("LeadTime = %+v", overall.LeadTime)
+	}
+	if overall.FailedDeploys != 1 || overall.ChangeFailureRate != 0.5 {
+		t.Errorf("change failure rate = %v failed, %v", overall.FailedDeploys, overall.ChangeFailureRate)
+	}
+
+	if len(report.Windows) != 2 {
+		t.Fatalf("got %v windows, want 2", len(report.Windows))
+	}
+	if w := report.Windows[0]; w.Deploys != 2 || !w.To.Equal(midnight(12)) {
+		t.Errorf("first window = %+v", w)
+	}
+	if w := report.Windows[1]; w.Deploys != 0 || w.ChangeFailureRate != 0 || w.LeadTime.Count != 0 {
+		t.Errorf("second window = %+v", w)
+	}
+
+	content, err := json.Marshal(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), `"change_failure_rate":0.5`) {
+		t.Errorf("unexpected JSON: %s", content)
+	}
+}
+
+func TestDORA_FailureWindow(t *testing.T) {
+	deploys := []sprintly.Deploy{{Environment: "production", CreatedAt: day(5)}}
+	items := []sprintly.Item{{Number: 1, Type: "defect", CreatedAt: day(7)}}
+
+	opts := &DORAOptions{Environment: "production", To: midnight(10), FailureWindow: 24 * time.Hour}
+	if report := DORA(deploys, slices.Values(items), opts); report.Overall.FailedDeploys != 0 {
+		t.Error("defect outside of the failure window counted")
+	}
+
+	opts.FailureWindow = 0
+	if report := DORA(deploys, slices.Values(items), opts); report.Overall.FailedDeploys != 1 {
+		t.Error("defect within the default failure window not counted")
+	}
+}
+
+func TestDORA_NoDeploys(t *testing.T) {
+	opts := &DORAOptions{Environment: "production", Window: 7 * 24 * time.Hour}
+	report := DORA(nil, slices.Values([]sprintly.Item{}), opts)
+	if len(report.Windows) != 0 || report.Overall.Deploys != 0 || !report.Overall.From.Equal(report.Overall.To) {
+		t.Errorf("unexpected report: %+v with %v windows", report.Overall, len(report.Windows))
+	}
+}
@@ -0,0 +1,173 @@
+// Package analytics computes flow metrics from the progress timestamps of Sprintly items
+// and DORA metrics from the deploys.
+//
+// All the functions accept an iterator, so the items can be passed in as
+// a slice using slices.Values or streamed from elsewhere, e.g. a local mirror:
+//
+//	items, _, err := client.Items.ListAll(productId, &sprintly.ItemListArgs{
+//		Status: []sprintly.ItemStatus{sprintly.ItemStatusCompleted, sprintly.ItemStatusAccepted},
+//	})
+//	flow := analytics.Flow(slices.Values(items))
+//	fmt.Println(flow.CycleTime.P85)
+//
+// The history is reconstructed from ItemProgress, which only records when an item
+// was first triaged, started, closed and accepted. Items moved back are not visible.
+package analytics
+
+import (
+	"iter"
+	"time"
+
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+// Transition represents an item entering a status.
+type Transition struct {
+	Status sprintly.ItemStatus
+	At     time.Time
+}
+
+// Transitions returns the statuses the item went through, in order.
+//
+// An item without TriagedAt that has left someday is considered
+// to be created in the backlog. Timestamps going back in time are dropped.
+func Transitions(item *sprintly.Item) []Transition {
+	if item.CreatedAt == nil {
+		return nil
+	}
+
+	var progress sprintly.ItemProgress
+	if item.Progress != nil {
+		progress = *item.Progress
+	}
+
+	first := sprintly.ItemStatusSomeday
+	if progress.TriagedAt == nil && item.Status != sprintly.ItemStatusSomeday {
+		first = sprintly.ItemStatusBacklog
+	}
+
+	ts := []Transition{{first, *item.CreatedAt}}
+	add := func(status sprintly.ItemStatus, at *time.Time) {
+		if at != nil && !at.Before(ts[len(ts)-1].At) {
+			ts = append(ts, Transition{status, *at})
+		}
+	}
+	if first == sprintly.ItemStatusSomeday {
+		add(sprintly.ItemStatusBacklog, progress.TriagedAt)
+	}
+	add(sprintly.ItemStatusInProgress, progress.StartedAt)
+	add(sprintly.ItemStatusCompleted, progress.ClosedAt)
+	add(sprintly.ItemStatusAccepted, progress.AcceptedAt)
+	return ts
+}
+
+// StatusAt returns the status of the item at the given time,
+// an empty status when the item did not exist yet.
+func StatusAt(item *sprintly.Item, t time.Time) sprintly.ItemStatus {
+	var status sprintly.ItemStatus
+	for _, tr := range Transitions(item) {
+		if tr.At.After(t) {
+			break
+		}
+		status = tr.Status
+	}
+	return status
+}
+
+// DoneAt returns when the item was completed, nil when it is not done.
+// Items accepted without being closed first are done when accepted.
+func DoneAt(item *sprintly.Item) *time.Time {
+	if item.Progress == nil {
+		return nil
+	}
+	if item.Progress.ClosedAt != nil {
+		return item.Progress.ClosedAt
+	}
+	return item.Progress.AcceptedAt
+}
+
+// FlowStats summarises how the items flow through the statuses.
+type FlowStats struct {
+	// Items is the number of items taken into account.
+	Items int
+
+	// LeadTime measures the time from an item being created to it being done.
+	LeadTime Distribution
+
+	// CycleTime measures the time from an item being started to it being done.
+	CycleTime Distribution
+
+	// TimeInStatus measures the time spent in every status the items have left.
+	TimeInStatus map[sprintly.ItemStatus]Distribution
+}
+
+// Flow computes the flow statistics of the given items.
+func Flow(items iter.Seq[sprintly.Item]) *FlowStats {
+	var acc flowAccumulator
+	for item := range items {
+		acc.add(&item)
+	}
+	return acc.stats()
+}
+
+// FlowBy computes the flow statistics for every group of items.
+func FlowBy(items iter.Seq[sprintly.Item], group GroupFunc) map[string]*FlowStats {
+	accs := make(map[string]*flowAccumulator)
+	for item := range items {
+		for _, key := range group(&item) {
+			acc, ok := accs[key]
+			if !ok {
+				acc = &flowAccumulator{}
+				accs[key] = acc
+			}
+			acc.add(&item)
+		}
+	}
+
+	stats := make(map[string]*FlowStats, len(accs))
+	for key, acc := range accs {
+		stats[key] = acc.stats()
+	}
+	return stats
+}
+
+type flowAccumulator struct {
+	items     int
+	leadTime  []time.Duration
+	cycleTime []time.Duration
+	inStatus  map[sprintly.ItemStatus][]time.Duration
+}
+
+func (acc *flowAccumulator) add(item *sprintly.Item) {
+	acc.items++
+
+	if done := DoneAt(item); done != nil {
+		if item.CreatedAt != nil && !done.Before(*item.CreatedAt) {
+			acc.leadTime = append(acc.leadTime, done.Sub(*item.CreatedAt))
+		}
+		if started := item.Progress.StartedAt; started != nil && !done.Before(*started) {
+			acc.cycleTime = append(acc.cycleTime, done.Sub(*started))
+		}
+	}
+
+	ts := Transitions(item)
+	for i := 0; i+1 < len(ts); i++ {
+		if acc.inStatus == nil {
+			acc.inStatus = make(map[sprintly.ItemStatus][]time.Duration)
+		}
+		acc.inStatus[ts[i].Status] = append(acc.inStatus[ts[i].Status], ts[i+1].At.Sub(ts[i].At))
+	}
+}
+
+func (acc *flowAccumulator) stats() *FlowStats {
+	stats := &FlowStats{
+		Items:        acc.items,
+		LeadTime:     NewDistribution(acc.leadTime),
+		CycleTime:    NewDistribution(acc.cycleTime),
+		TimeInStatus: make(map[sprintly.ItemStatus]Distribution, len(acc.inStatus)),
+	}
+	for status, samples := range acc.inStatus {
+		stats.TimeInStatus[status] = NewDistribution(samples)
+	}
+	return stats
+}
@@ -0,0 +1,120 @@
+package analytics
+
+import (
+	"iter"
+	"strings"
+	"time"
+
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+// GroupFunc returns the groups an item belongs to.
+type GroupFunc func(item *sprintly.Item) []string
+
+// ByType groups the items by type.
+func ByType(item *sprintly.Item) []string {
+	return []string{item.Type}
+}
+
+// ByTag groups the items by tag, an item with several tags belongs to several groups.
+// Items without tags form the "untagged" group.
+func ByTag(item *sprintly.Item) []string {
+	if len(item.Tags) == 0 {
+		return []string{"untagged"}
+	}
+	return item.Tags
+}
+
+// ByAssignee groups the items by the assignee email, the name when the email is
+// not known. Items not assigned to anybody form the "unassigned" group.
+func ByAssignee(item *sprintly.Item) []string {
+	user := item.AssignedTo
+	if user == nil {
+		return []string{"unassigned"}
+	}
+	if user.Email != "" {
+		return []string{user.Email}
+	}
+	if name := strings.TrimSpace(user.FirstName + " " + user.LastName); name != "" {
+		return []string{name}
+	}
+	return []string{"unassigned"}
+}
+
+// Week represents the items done within a single week.
+type Week struct {
+	// Start is the midnight starting the week, on Monday.
+	Start  time.Time `json:"start"`
+	Items  int       `json:"items"`
+	Points int       `json:"points"`
+}
+
+// Velocity returns the items done per week, in order. The weeks start on Monday
+// in the given location, UTC when nil. Weeks with nothing done are included.
+func Velocity(items iter.Seq[sprintly.Item], loc *time.Location) []Week {
+	velocity := VelocityBy(items, func(*sprintly.Item) []string { return []string{""} }, loc)
+	return velocity[""]
+}
+
+// VelocityBy returns the items done per week for every group of items.
+// All the groups span the same weeks, so they can be compared side by side.
+func VelocityBy(items iter.Seq[sprintly.Item], group GroupFunc, loc *time.Location) map[string][]Week {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	type key struct {
+		group string
+		week  time.Time
+	}
+	var (
+		weeks       = make(map[key]*Week)
+		groups      = make(map[string]bool)
+		first, last time.Time
+	)
+	for item := range items {
+		done := DoneAt(&item)
+		if done == nil {
+			continue
+		}
+		start := WeekStart(*done, loc)
+		if first.IsZero() || start.Before(first) {
+			first = start
+		}
+		if start.After(last) {
+			last = start
+		}
+
+		for _, g := range group(&item) {
+			groups[g] = true
+			w, ok := weeks[key{g, start}]
+			if !ok {
+				w = &Week{Start: start}
+				weeks[key{g, start}] = w
+			}
+			w.Items++
+			w.Points += item.Score.Points()
+		}
+	}
+
+	velocity := make(map[string][]Week, len(groups))
+	for g := range groups {
+		var series []Week
+		for start := first; !start.After(last); start = start.AddDate(0, 0, 7) {
+			if w, ok := weeks[key{g, start}]; ok {
+				series = append(series, *w)
+			} else {
+				series = append(series, Week{Start: start})
+			}
+		}
+		velocity[g] = series
+	}
+	return velocity
+}
+
+// WeekStart returns the Monday midnight starting the week of the given time.
+func WeekStart(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	days := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-days, 0, 0, 0, 0, loc)
+}
@@ -0,0 +1,352 @@
+// Package bulk applies a change to many Sprintly items at once.
+//
+// The items are selected by number or using an ItemListArgs filter, the change
+// is a Mutation modifying a copy of every item selected. Only the fields the
+// mutation actually changes are sent to Items.UpdateIf, see sprintly.DiffItems.
+// An item modified by someone else since it was read is not updated,
+// its outcome holds a *sprintly.ErrItemConflict.
+// Moving the backlog of one person to another person looks like this:
+//
+//	res, err := bulk.Run(client, productId, &bulk.Selection{
+//		Filter: &sprintly.ItemListArgs{
+//			AssignedTo: joe,
+//			Status:     []sprintly.ItemStatus{sprintly.ItemStatusBacklog},
+//		},
+//	}, bulk.Reassign(ondra), &bulk.Options{Concurrency: 8, RequestsPerSecond: 5})
+//
+// The updates are independent, so a failed update does not stop the others.
+// The result reports the outcome for every item.
+package bulk
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/salsita/go-sprintly/internal/errjson"
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+// DefaultConcurrency is the number of workers used when Options.Concurrency is not set.
+const DefaultConcurrency = 4
+
+// Selection selects the items to change, either by number or using a filter.
+type Selection struct {
+	Numbers []int
+
+	// Filter selects all the items matching, the default statuses
+	// of Items.List apply unless Status is set.
+	Filter *sprintly.ItemListArgs
+}
+
+// Mutation changes the item passed in, which is a copy of the item selected.
+// Items the mutation does not change are skipped.
+type Mutation func(item *sprintly.Item) error
+
+// RenameTag returns a mutation renaming the given tag.
+// Items tagged with both the old and the new tag end up with the new tag only.
+func RenameTag(old, new string) Mutation {
+	return func(item *sprintly.Item) error {
+		i := slices.Index(item.Tags, old)
+		if i == -1 {
+			return nil
+		}
+		if slices.Contains(item.Tags, new) {
+			item.Tags = slices.Delete(item.Tags, i, i+1)
+		} else {
+			item.Tags[i] = new
+		}
+		return nil
+	}
+}
+
+// Reassign returns a mutation assigning the items to the given user.
+func Reassign(userId int) Mutation {
+	return func(item *sprintly.Item) error {
+		item.AssignedTo = &sprintly.User{Id: userId}
+		return nil
+	}
+}
+
+// Unassign returns a mutation clearing the assignee of the items.
+func Unassign() Mutation {
+	return func(item *sprintly.Item) error {
+		item.AssignedTo = nil
+		return nil
+	}
+}
+
+// Options configure a bulk operation.
+type Options struct {
+	// Concurrency is the number of items processed in parallel, DefaultConcurrency when zero.
+	Concurrency int
+
+	// RequestsPerSecond limits the rate of the API requests, no limit when zero.
+	RequestsPerSecond float64
+
+	// DryRun only computes the changes, nothing is updated.
+	DryRun bool
+
+	// Progress is called once an item is processed, the calls are serialized.
+	Progress func(outcome *Outcome, done, total int)
+}
+
+// Outcome is the outcome of a bulk operation for a single item.
+type Outcome struct {
+	Number int `json:"number"`
+
+	// Changes are the changes made, or to be made in case of a dry run.
+	// Changes are nil when the item could not be fetched.
+	Changes *sprintly.ItemChanges `json:"changes,omitempty"`
+
+	// Item is the updated item, nil in case of a dry run or a failure.
+	Item *sprintly.Item `json:"item,omitempty"`
+
+	Err error `json:"-"`
+}
+
+// MarshalJSON encodes the outcome including the error message.
+func (outcome *Outcome) MarshalJSON() ([]byte, error) {
+	type plain Outcome
+	return errjson.Marshal((*plain)(outcome), outcome.Err)
+}
+
+// Skipped returns true when the mutation did not change the item.
+func (outcome *Outcome) Skipped() bool {
+	return outcome.Err == nil && (outcome.Changes == nil || outcome.Changes.Empty())
+}
+
+// Result holds the outcomes of a bulk operation, ordered by item number.
+type Result struct {
+	DryRun   bool       `json:"dry_run"`
+	Outcomes []*Outcome `json:"outcomes"`
+}
+
+// Changed returns the outcomes of the items changed, or to be changed in case of a dry run.
+func (res *Result) Changed() []*Outcome {
+	return res.filter(func(outcome *Outcome) bool {
+		return outcome.Err == nil && !outcome.Skipped()
+	})
+}
+
+// Failed returns the outcomes of the items that could not be changed.
+func (res *Result) Failed() []*Outcome {
+	return res.filter(func(outcome *Outcome) bool {
+		return outcome.Err != nil
+	})
+}
+
+func (res *Result) filter(keep func(*Outcome) bool) []*Outcome {
+	var outcomes []*Outcome
+	for _, outcome := range res.Outcomes {
+		if keep(outcome) {
+			outcomes = append(outcomes, outcome)
+		}
+	}
+	return outcomes
+}
+
+// PartialError is returned by Run when some of the items could not be changed.
+type PartialError struct {
+	Failed []*Outcome
+	Total  int
+}
+
+func (err *PartialError) Error() string {
+	var numbers []string
+	for _, outcome := range err.Failed {
+		numbers = append(numbers, fmt.Sprintf("#%v", outcome.Number))
+	}
+	return fmt.Sprintf("bulk: %v of %v items failed: %v (first error: %v)",
+		len(err.Failed), err.Total, strings.Join(numbers, ", "), err.Failed[0].Err)
+}
+
+// Run applies the mutation to the items selected.
+//
+// The result is returned even when an error is returned. A *PartialError
+// is returned when some of the items failed, the error of every item
+// being recorded in its outcome.
+func Run(
+	client *sprintly.Client,
+	productId int,
+	sel *Selection,
+	mutate Mutation,
+	opts *Options,
+) (*Result, error) {
+
+	var o Options
+	if opts != nil {
+		o = *opts
+	}
+	concurrency := o.Concurrency
+	if concurrency < 1 {
+		concurrency = DefaultConcurrency
+	}
+
+	r := &runner{
+		client:    client,
+		productId: productId,
+		mutate:    mutate,
+		opts:      o,
+	}
+	if o.RequestsPerSecond > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / o.RequestsPerSecond))
+		defer ticker.Stop()
+		r.limit = ticker.C
+	}
+
+	res := &Result{DryRun: o.DryRun}
+
+	// Items selected by a filter are fetched upfront, items selected by number one by one.
+	type job struct {
+		number int
+		item   *sprintly.Item
+	}
+	var jobs []job
+	if sel.Filter != nil {
+		r.wait()
+		items, _, err := client.Items.ListAll(productId, sel.Filter)
+		if err != nil {
+			return res, err
+		}
+		for i := range items {
+			jobs = append(jobs, job{items[i].Number, &items[i]})
+		}
+	}
+	for _, number := range sel.Numbers {
+		if !slices.ContainsFunc(jobs, func(j job) bool { return j.number == number }) {
+			jobs = append(jobs, job{number: number})
+		}
+	}
+	slices.SortFunc(jobs, func(a, b job) int { return a.number - b.number })
+
+	res.Outcomes = make([]*Outcome, len(jobs))
+	var (
+		next int
+		done int
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+	)
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				mu.Lock()
+				i := next
+				next++
+				mu.Unlock()
+				if i >= len(jobs) {
+					return
+				}
+
+				outcome := r.process(jobs[i].number, jobs[i].item)
+
+				mu.Lock()
+				res.Outcomes[i] = outcome
+				done++
+				if o.Progress != nil {
+					o.Progress(outcome, done, len(jobs))
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if failed := res.Failed(); len(failed) != 0 {
+		return res, &PartialError{Failed: failed, Total: len(jobs)}
+	}
+	return res, nil
+}
+
+type runner struct {
+	client    *sprintly.Client
+	productId int
+	mutate    Mutation
+	opts      Options
+
+	// limit delivers a tick for every request allowed, nil when not limited.
+	limit <-chan time.Time
+}
+
+// wait blocks until another request is allowed.
+func (r *runner) wait() {
+	if r.limit != nil {
+		<-r.limit
+	}
+}
+
+// process applies the mutation to a single item, fetching it first when not known.
+func (r *runner) process(number int, item *sprintly.Item) *Outcome {
+	outcome := &Outcome{Number: number}
+
+	if item == nil {
+		r.wait()
+		fetched, _, err := r.client.Items.Get(r.productId, number)
+		if err != nil {
+			outcome.Err = err
+			return outcome
+		}
+		item = fetched
+	}
+
+	mutated := cloneItem(item)
+	if err := r.mutate(mutated); err != nil {
+		outcome.Err = err
+		return outcome
+	}
+	changes, err := sprintly.DiffItems(item, mutated)
+	if err != nil {
+		outcome.Err = err
+		return outcome
+	}
+	outcome.Changes = changes
+	if changes.Empty() {
+		return outcome
+	}
+
+	// Items.Update cannot clear the assignee, Items.Unassign is used instead.
+	rest := *changes
+	unassign := changes.AssignedTo != nil && changes.AssignedTo.New == 0
+	if unassign {
+		rest.AssignedTo = nil
+	}
+	args, err := rest.UpdateArgs()
+	if err != nil {
+		outcome.Err = err
+		return outcome
+	}
+	if r.opts.DryRun {
+		return outcome
+	}
+
+	// The item must not have changed since it was read, conflicts are failures.
+	expected := item.LastModified
+	if unassign {
+		r.wait()
+		if outcome.Item, _, outcome.Err = r.client.Items.UnassignIf(r.productId, number, expected); outcome.Err != nil {
+			return outcome
+		}
+		if rest.Empty() {
+			return outcome
+		}
+		expected = outcome.Item.LastModified
+	}
+	r.wait()
+	outcome.Item, _, outcome.Err = r.client.Items.UpdateIf(r.productId, number, expected, args)
+	return outcome
+}
+
+// cloneItem returns a copy of the item the mutations cannot use to modify the original.
+func cloneItem(item *sprintly.Item) *sprintly.Item {
+	clone := *item
+	clone.Tags = slices.Clone(item.Tags)
+	if item.AssignedTo != nil {
+		user := *item.AssignedTo
+		clone.AssignedTo = &user
+	}
+	return &clone
+}
@@ -0,0 +1,168 @@
+package bulk
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/salsita/go-sprintly/internal/sprintlytest"
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+// newServer serves the items of product 1, item #13 cannot be updated.
+func newServer(t *testing.T) *sprintlytest.Server {
+	s := sprintlytest.NewServer(t)
+	s.AddItems(1,
+		sprintly.Item{Number: 10, Type: "task", Title: "Login form", Tags: []string{"frontend", "auth"}, AssignedTo: &sprintly.User{Id: 7}},
+		sprintly.Item{Number: 11, Type: "task", Title: "Logo", Tags: []string{"frontend", "web"}, AssignedTo: &sprintly.User{Id: 7}},
+		sprintly.Item{Number: 12, Type: "task", Title: "Database", Tags: []string{"backend"}, AssignedTo: &sprintly.User{Id: 8}},
+		sprintly.Item{Number: 13, Type: "task", Title: "Footer", Tags: []string{"frontend"}, AssignedTo: &sprintly.User{Id: 7}},
+	)
+	s.Intercept = func(r *http.Request) error {
+		if r.Method == "POST" && r.URL.Path == "/products/1/items/13.json" {
+			return errors.New("item locked")
+		}
+		return nil
+	}
+	return s
+}
+
+// updates returns the numbers of the items updated.
+func updates(s *sprintlytest.Server) []int {
+	var numbers []int
+	for _, req := range s.Requests() {
+		if path, ok := strings.CutPrefix(req, "POST /products/1/items/"); ok {
+			number, _ := strconv.Atoi(strings.TrimSuffix(path, ".json"))
+			numbers = append(numbers, number)
+		}
+	}
+	return numbers
+}
+
+func TestRun_RenameTag(t *testing.T) {
+	s := newServer(t)
+	client := s.Client
+
+	var progress []int
+	sel := &Selection{Filter: &sprintly.ItemListArgs{Tags: []string{"frontend"}}}
+	res, err := Run(client, 1, sel, RenameTag("frontend", "web"), &Options{
+		Concurrency:       2,
+		RequestsPerSecond: 1000,
+		Progress: func(outcome *Outcome, done, total int) {
+			if total != 3 {
+				t.Errorf("total = %v, want 3", total)
+			}
+			progress = append(progress, done)
+		},
+	})
+
+	var perr *PartialError
+	if !errors.As(err, &perr) || len(perr.Failed) != 1 || perr.Failed[0].Number != 13 {
+		t.Fatalf("Run should have failed for #13 only, err = %v", err)
+	}
+	if !slices.Equal(progress, []int{1, 2, 3}) {
+		t.Errorf("progress = %v", progress)
+	}
+
+	var changed []int
+	for _, outcome := range res.Changed() {
+		changed = append(changed, outcome.Number)
+	}
+	if !slices.Equal(changed, []int{10, 11}) {
+		t.Errorf("changed = %v, want [10 11]", changed)
+	}
+	if tags := s.Item(1, 10).Tags; !slices.Equal(tags, []string{"web", "auth"}) {
+		t.Errorf("#10 tags = %v", tags)
+	}
+	if tags := s.Item(1, 11).Tags; !slices.Equal(tags, []string{"web"}) {
+		t.Errorf("#11 tags = %v", tags)
+	}
+	if outcome := res.Outcomes[0]; outcome.Item == nil || outcome.Changes.Tags == nil {
+		t.Errorf("unexpected outcome: %+v", outcome)
+	}
+}
+
+func TestRun_Reassign(t *testing.T) {
+	s := newServer(t)
+	client := s.Client
+
+	// #12 is assigned to the user already, #99 does not exist.
+	sel := &Selection{Numbers: []int{12, 99, 11}}
+	res, err := Run(client, 1, sel, Reassign(8), nil)
+	if err == nil {
+		t.Fatal("Run should have failed for #99")
+	}
+	if got := updates(s); !slices.Equal(got, []int{11}) {
+		t.Errorf("updated %v, want [11]", got)
+	}
+	if len(res.Outcomes) != 3 || !res.Outcomes[1].Skipped() || res.Outcomes[2].Err == nil {
+		t.Errorf("unexpected outcomes: %+v", res.Outcomes)
+	}
+	if s.Item(1, 11).AssignedTo.Id != 8 {
+		t.Errorf("#11 not reassigned")
+	}
+}
+
+func TestRun_Unassign(t *testing.T) {
+	s := newServer(t)
+	client := s.Client
+
+	res, err := Run(client, 1, &Selection{Numbers: []int{10}}, Unassign(), nil)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if s.Item(1, 10).AssignedTo != nil || res.Outcomes[0].Item == nil {
+		t.Errorf("#10 not unassigned: %+v", res.Outcomes[0])
+	}
+}
+
+func TestRun_Conflict(t *testing.T) {
+	// #11 is modified by someone else once fetched.
+	s := newServer(t)
+	s.Intercept = func(r *http.Request) error {
+		if r.Method == "GET" && r.URL.Path == "/products/1/items/11.json" {
+			s.Touch(1, 11)
+		}
+		return nil
+	}
+	client := s.Client
+
+	sel := &Selection{Filter: &sprintly.ItemListArgs{Tags: []string{"web"}}}
+	res, err := Run(client, 1, sel, RenameTag("web", "site"), nil)
+	var conflict *sprintly.ErrItemConflict
+	if !errors.As(res.Outcomes[0].Err, &conflict) || err == nil {
+		t.Fatalf("Run should have failed with a conflict, err = %v", err)
+	}
+	if got := updates(s); len(got) != 0 {
+		t.Errorf("updated %v despite the conflict", got)
+	}
+}
+
+func TestRun_DryRun(t *testing.T) {
+	s := newServer(t)
+	client := s.Client
+
+	sel := &Selection{Numbers: []int{10, 13}, Filter: &sprintly.ItemListArgs{AssignedTo: 7}}
+	res, err := Run(client, 1, sel, Reassign(9), &Options{DryRun: true})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if got := updates(s); len(got) != 0 {
+		t.Errorf("updated %v in a dry run", got)
+	}
+	if changed := res.Changed(); len(changed) != 3 || changed[0].Item != nil || changed[0].Changes.AssignedTo == nil {
+		t.Errorf("unexpected changes: %+v", changed)
+	}
+
+	data, err := json.Marshal(res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"dry_run":true`) {
+		t.Errorf("unexpected JSON: %s", data)
+	}
+}
@@ -0,0 +1,142 @@
+package charts
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+func day(d, hour int) *time.Time {
+	t := time.Date(2015, 1, d, hour, 0, 0, 0, time.UTC)
+	return &t
+}
+
+var testingItems = []sprintly.Item{
+	{
+		Number:    1,
+		Score:     sprintly.ItemScoreMedium,
+		Status:    sprintly.ItemStatusAccepted,
+		CreatedAt: day(1, 9),
+		Progress: &sprintly.ItemProgress{
+			StartedAt:  day(2, 9),
+			ClosedAt:   day(3, 9),
+			AcceptedAt: day(4, 9),
+		},
+	},
+	{
+		Number:    2,
+		Score:     sprintly.ItemScoreLarge,
+		Status:    sprintly.ItemStatusInProgress,
+		CreatedAt: day(1, 10),
+		Progress:  &sprintly.ItemProgress{StartedAt: day(3, 10)},
+	},
+	{
+		Number:    3,
+		Score:     sprintly.ItemScoreSmall,
+		Status:    sprintly.ItemStatusSomeday,
+		CreatedAt: day(3, 23),
+	},
+}
+
+func TestDaily(t *testing.T) {
+	days := Daily(slices.Values(testingItems), *day(1, 0), *day(4, 23), nil)
+	if len(days) != 4 {
+		t.Fatalf("got %v days, want 4", len(days))
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, days); err != nil {
+		t.Fatal(err)
+	}
+	want := `date,someday,backlog,in-progress,completed,accepted,remaining_points,remaining_items
+2015-01-01,0,2,0,0,0,8,2
+2015-01-02,0,1,1,0,0,8,2
+2015-01-03,1,0,1,1,0,6,2
+2015-01-04,1,0,1,0,1,6,2
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestDaily_Location(t *testing.T) {
+	// The third item is created on the 4th in UTC+2.
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	days := Daily(slices.Values(testingItems), *day(3, 12), *day(4, 12), loc)
+	if got := days[0].Counts[sprintly.ItemStatusSomeday]; got != 0 {
+		t.Errorf("someday items on the 3rd = %v, want 0", got)
+	}
+	if got := days[1].Counts[sprintly.ItemStatusSomeday]; got != 1 {
+		t.Errorf("someday items on the 4th = %v, want 1", got)
+	}
+}
+
+// elements parses the SVG and counts the elements by name.
+func elements(t *testing.T, svg string) map[string]int {
+	counts := make(map[string]int)
+	dec := xml.NewDecoder(strings.NewReader(svg))
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return counts
+		}
+		if err != nil {
+			t.Fatalf("invalid SVG: %v\n%v", err, svg)
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			counts[start.Name.Local]++
+		}
+	}
+}
+
+func TestBurndown(t *testing.T) {
+	days := Daily(slices.Values(testingItems), *day(1, 0), *day(4, 0), nil)
+
+	var buf bytes.Buffer
+	if err := Burndown(&buf, days, &Options{Title: "Sprint <1> & co"}); err != nil {
+		t.Fatal(err)
+	}
+	svg := buf.String()
+
+	counts := elements(t, svg)
+	if counts["svg"] != 1 || counts["polyline"] != 2 {
+		t.Errorf("unexpected elements: %v", counts)
+	}
+	if !strings.Contains(svg, "Sprint &lt;1&gt; &amp; co") {
+		t.Error("title not escaped")
+	}
+	if !strings.Contains(svg, "Remaining points") || !strings.Contains(svg, ">Jan 4<") {
+		t.Errorf("labels missing:\n%v", svg)
+	}
+}
+
+func TestCumulativeFlow(t *testing.T) {
+	days := Daily(slices.Values(testingItems), *day(1, 0), *day(4, 0), nil)
+
+	var buf bytes.Buffer
+	if err := CumulativeFlow(&buf, days, nil); err != nil {
+		t.Fatal(err)
+	}
+	if counts := elements(t, buf.String()); counts["polygon"] != len(sprintly.ItemStatuses) {
+		t.Errorf("got %v bands, want %v", counts["polygon"], len(sprintly.ItemStatuses))
+	}
+
+	if err := CumulativeFlow(&buf, nil, nil); err != ErrNoData {
+		t.Errorf("expected ErrNoData, got %v", err)
+	}
+}
+
+func TestNiceCeil(t *testing.T) {
+	tests := map[float64]float64{0: 1, 0.3: 0.5, 1: 1, 3: 5, 8: 10, 11: 20, 230: 500}
+	for v, want := range tests {
+		if got := niceCeil(v); got != want {
+			t.Errorf("niceCeil(%v) = %v, want %v", v, got, want)
+		}
+	}
+}
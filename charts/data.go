@@ -0,0 +1,91 @@
+// Package charts renders burndown charts and cumulative flow diagrams as standalone SVG.
+//
+// The daily data are derived from the item progress timestamps using
+// analytics.StatusAt, the charts are drawn from the data without any external service:
+//
+//	days := charts.Daily(slices.Values(items), from, to, time.Local)
+//	err := charts.Burndown(w, days, &charts.Options{Title: "Sprint 12"})
+package charts
+
+import (
+	"encoding/csv"
+	"io"
+	"iter"
+	"strconv"
+	"time"
+
+	"github.com/salsita/go-sprintly/analytics"
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+// Day represents the state of the items at the end of a day.
+type Day struct {
+	Date time.Time
+
+	// Counts maps the statuses to the number of items in them.
+	Counts map[sprintly.ItemStatus]int
+
+	// RemainingPoints and RemainingItems cover the items not completed yet.
+	RemainingPoints int
+	RemainingItems  int
+}
+
+// Daily returns the state of the items at the end of every day from the day of from
+// to the day of to, both included. The days are taken in the given location, UTC when nil.
+func Daily(items iter.Seq[sprintly.Item], from, to time.Time, loc *time.Location) []Day {
+	if loc == nil {
+		loc = time.UTC
+	}
+	from = midnight(from, loc)
+	to = midnight(to, loc)
+
+	var days []Day
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		days = append(days, Day{Date: d, Counts: make(map[sprintly.ItemStatus]int)})
+	}
+
+	for item := range items {
+		for i := range days {
+			end := days[i].Date.AddDate(0, 0, 1).Add(-time.Nanosecond)
+			status := analytics.StatusAt(&item, end)
+			if status == "" {
+				continue
+			}
+			days[i].Counts[status]++
+			if status != sprintly.ItemStatusCompleted && status != sprintly.ItemStatusAccepted {
+				days[i].RemainingPoints += item.Score.Points()
+				days[i].RemainingItems++
+			}
+		}
+	}
+	return days
+}
+
+func midnight(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// WriteCSV writes the daily data, a row per day.
+func WriteCSV(w io.Writer, days []Day) error {
+	cw := csv.NewWriter(w)
+
+	header := []string{"date"}
+	for _, status := range sprintly.ItemStatuses {
+		header = append(header, string(status))
+	}
+	header = append(header, "remaining_points", "remaining_items")
+	cw.Write(header)
+
+	for _, day := range days {
+		row := []string{day.Date.Format("2006-01-02")}
+		for _, status := range sprintly.ItemStatuses {
+			row = append(row, strconv.Itoa(day.Counts[status]))
+		}
+		row = append(row, strconv.Itoa(day.RemainingPoints), strconv.Itoa(day.RemainingItems))
+		cw.Write(row)
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
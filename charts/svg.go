@@ -0,0 +1,255 @@
+package charts
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+// Options customise the chart rendering.
+type Options struct {
+	Title string
+
+	// Width and Height are the size of the chart in pixels, 800x400 when zero.
+	Width  int
+	Height int
+
+	// Items makes the burndown chart count the items instead of the points.
+	Items bool
+}
+
+// Colors maps the statuses to the colors used in the cumulative flow diagram.
+var Colors = map[sprintly.ItemStatus]string{
+	sprintly.ItemStatusSomeday:    "#b0b7bf",
+	sprintly.ItemStatusBacklog:    "#f2b134",
+	sprintly.ItemStatusInProgress: "#3c8dbc",
+	sprintly.ItemStatusCompleted:  "#5cb85c",
+	sprintly.ItemStatusAccepted:   "#2e6b30",
+}
+
+const (
+	marginLeft   = 50
+	marginRight  = 130
+	marginTop    = 40
+	marginBottom = 40
+	maxLabels    = 8
+)
+
+// ErrNoData is returned when there are no days to be charted.
+var ErrNoData = errors.New("charts: no data")
+
+// Burndown renders the remaining points, or items, per day together with
+// the ideal burndown going from the first day value to zero on the last day.
+func Burndown(w io.Writer, days []Day, opts *Options) error {
+	if len(days) == 0 {
+		return ErrNoData
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	values := make([]float64, len(days))
+	var top float64
+	for i, day := range days {
+		if opts.Items {
+			values[i] = float64(day.RemainingItems)
+		} else {
+			values[i] = float64(day.RemainingPoints)
+		}
+		top = math.Max(top, values[i])
+	}
+
+	c := newCanvas(days, top, opts)
+	ideal := []point{{c.x(0), c.y(values[0])}, {c.x(len(days) - 1), c.y(0)}}
+	fmt.Fprintf(&c.buf, `<polyline points="%v" fill="none" stroke="#999999" stroke-width="1.5" stroke-dasharray="6 4"/>`+"\n", points(ideal))
+
+	actual := make([]point, len(values))
+	for i, v := range values {
+		actual[i] = point{c.x(i), c.y(v)}
+	}
+	fmt.Fprintf(&c.buf, `<polyline points="%v" fill="none" stroke="#d9534f" stroke-width="2.5"/>`+"\n", points(actual))
+
+	unit := "Points"
+	if opts.Items {
+		unit = "Items"
+	}
+	c.legend([]string{"Remaining " + strings.ToLower(unit), "Ideal"}, []string{"#d9534f", "#999999"})
+	return c.finish(w)
+}
+
+// CumulativeFlow renders the number of items in every status per day,
+// stacked with the most advanced status at the bottom.
+func CumulativeFlow(w io.Writer, days []Day, opts *Options) error {
+	if len(days) == 0 {
+		return ErrNoData
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	var top float64
+	for _, day := range days {
+		var total int
+		for _, status := range sprintly.ItemStatuses {
+			total += day.Counts[status]
+		}
+		top = math.Max(top, float64(total))
+	}
+
+	c := newCanvas(days, top, opts)
+	lower := make([]int, len(days))
+	for i := len(sprintly.ItemStatuses) - 1; i >= 0; i-- {
+		status := sprintly.ItemStatuses[i]
+
+		var band []point
+		upper := make([]int, len(days))
+		for d, day := range days {
+			upper[d] = lower[d] + day.Counts[status]
+			band = append(band, point{c.x(d), c.y(float64(upper[d]))})
+		}
+		for d := len(days) - 1; d >= 0; d-- {
+			band = append(band, point{c.x(d), c.y(float64(lower[d]))})
+		}
+		fmt.Fprintf(&c.buf, `<polygon points="%v" fill="%v" stroke="%v"><title>%v</title></polygon>`+"\n",
+			points(band), Colors[status], Colors[status], status)
+		lower = upper
+	}
+
+	labels := make([]string, len(sprintly.ItemStatuses))
+	colors := make([]string, len(sprintly.ItemStatuses))
+	for i, status := range sprintly.ItemStatuses {
+		labels[i] = string(status)
+		colors[i] = Colors[status]
+	}
+	c.legend(labels, colors)
+	return c.finish(w)
+}
+
+type point struct {
+	x, y float64
+}
+
+func points(ps []point) string {
+	parts := make([]string, len(ps))
+	for i, p := range ps {
+		parts[i] = fmt.Sprintf("%.1f,%.1f", p.x, p.y)
+	}
+	return strings.Join(parts, " ")
+}
+
+// canvas draws the common parts of the charts, the data go in between.
+type canvas struct {
+	buf           bytes.Buffer
+	width, height int
+	days          []Day
+	top           float64
+}
+
+func newCanvas(days []Day, top float64, opts *Options) *canvas {
+	c := &canvas{
+		width:  opts.Width,
+		height: opts.Height,
+		days:   days,
+		top:    niceCeil(top),
+	}
+	if c.width <= 0 {
+		c.width = 800
+	}
+	if c.height <= 0 {
+		c.height = 400
+	}
+
+	fmt.Fprintf(&c.buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%v" height="%v" viewBox="0 0 %v %v" font-family="sans-serif" font-size="12">`+"\n",
+		c.width, c.height, c.width, c.height)
+	fmt.Fprintf(&c.buf, `<rect width="100%%" height="100%%" fill="#ffffff"/>`+"\n")
+	if opts.Title != "" {
+		fmt.Fprintf(&c.buf, `<text x="%v" y="24" font-size="16" font-weight="bold">%v</text>`+"\n", marginLeft, escape(opts.Title))
+	}
+	c.axes()
+	return c
+}
+
+func (c *canvas) plotWidth() float64  { return float64(c.width - marginLeft - marginRight) }
+func (c *canvas) plotHeight() float64 { return float64(c.height - marginTop - marginBottom) }
+
+func (c *canvas) x(i int) float64 {
+	if len(c.days) == 1 {
+		return marginLeft + c.plotWidth()/2
+	}
+	return marginLeft + c.plotWidth()*float64(i)/float64(len(c.days)-1)
+}
+
+func (c *canvas) y(v float64) float64 {
+	return marginTop + c.plotHeight()*(1-v/c.top)
+}
+
+func (c *canvas) axes() {
+	bottom := c.y(0)
+	right := marginLeft + c.plotWidth()
+
+	// Horizontal grid lines with the value labels.
+	const ticks = 5
+	for i := 0; i <= ticks; i++ {
+		v := c.top * float64(i) / ticks
+		fmt.Fprintf(&c.buf, `<line x1="%v" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#e5e5e5"/>`+"\n", marginLeft, c.y(v), right, c.y(v))
+		fmt.Fprintf(&c.buf, `<text x="%v" y="%.1f" text-anchor="end">%v</text>`+"\n", marginLeft-6, c.y(v)+4, formatValue(v))
+	}
+
+	// Date labels, at most maxLabels of them.
+	step := (len(c.days) + maxLabels - 1) / maxLabels
+	for i := 0; i < len(c.days); i += step {
+		fmt.Fprintf(&c.buf, `<text x="%.1f" y="%.1f" text-anchor="middle">%v</text>`+"\n",
+			c.x(i), bottom+18, c.days[i].Date.Format("Jan 2"))
+	}
+
+	fmt.Fprintf(&c.buf, `<line x1="%v" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#333333"/>`+"\n", marginLeft, bottom, right, bottom)
+	fmt.Fprintf(&c.buf, `<line x1="%v" y1="%v" x2="%v" y2="%.1f" stroke="#333333"/>`+"\n", marginLeft, marginTop, marginLeft, bottom)
+}
+
+func (c *canvas) legend(labels, colors []string) {
+	x := c.width - marginRight + 16
+	for i, label := range labels {
+		y := marginTop + i*20
+		fmt.Fprintf(&c.buf, `<rect x="%v" y="%v" width="12" height="12" fill="%v"/>`+"\n", x, y, colors[i])
+		fmt.Fprintf(&c.buf, `<text x="%v" y="%v">%v</text>`+"\n", x+18, y+10, escape(label))
+	}
+}
+
+func (c *canvas) finish(w io.Writer) error {
+	c.buf.WriteString("</svg>\n")
+	_, err := c.buf.WriteTo(w)
+	return err
+}
+
+// niceCeil rounds the value up to 1, 2 or 5 times a power of ten.
+func niceCeil(v float64) float64 {
+	if v <= 0 {
+		return 1
+	}
+	exp := math.Pow(10, math.Floor(math.Log10(v)))
+	for _, m := range []float64{1, 2, 5, 10} {
+		if m*exp >= v {
+			return m * exp
+		}
+	}
+	return 10 * exp
+}
+
+func formatValue(v float64) string {
+	if v == math.Trunc(v) {
+		return fmt.Sprintf("%.0f", v)
+	}
+	return fmt.Sprintf("%.1f", v)
+}
+
+func escape(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/salsita/go-sprintly/bulk"
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+func itemsBulk(e *env, args []string) error {
+	var (
+		filter   sprintly.ItemListArgs
+		statuses listFlag
+		tags     listFlag
+		opts     bulk.Options
+	)
+	flags := e.flags()
+	flags.Var(&statuses, "status", "select the items with the comma-separated statuses")
+	flags.Var(&tags, "tags", "select the items with the comma-separated tags")
+	flags.IntVar(&filter.AssignedTo, "assigned-to", 0, "select the items assigned to the user ID")
+	renameTag := flags.String("rename-tag", "", "rename a tag, old=new")
+	reassign := flags.Int("reassign", 0, "assign the items to the user ID")
+	flags.IntVar(&opts.Concurrency, "concurrency", bulk.DefaultConcurrency, "number of items updated in parallel")
+	flags.Float64Var(&opts.RequestsPerSecond, "rate", 0, "maximum number of requests per second, 0 for no limit")
+	flags.BoolVar(&opts.DryRun, "dry-run", false, "only report what would be changed")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var sel bulk.Selection
+	for _, arg := range flags.Args() {
+		number, err := parseItemNumber(arg)
+		if err != nil {
+			return err
+		}
+		sel.Numbers = append(sel.Numbers, number)
+	}
+	if len(statuses) != 0 || len(tags) != 0 || filter.AssignedTo != 0 {
+		for _, status := range statuses {
+			filter.Status = append(filter.Status, sprintly.ItemStatus(status))
+		}
+		filter.Tags = tags
+		sel.Filter = &filter
+	}
+	if sel.Filter == nil && len(sel.Numbers) == 0 {
+		flags.Usage()
+		return fmt.Errorf("no items selected")
+	}
+
+	var mutations []bulk.Mutation
+	if *renameTag != "" {
+		old, new, ok := strings.Cut(*renameTag, "=")
+		if !ok || old == "" || new == "" {
+			return fmt.Errorf("invalid tag rename, old=new expected: %v", *renameTag)
+		}
+		mutations = append(mutations, bulk.RenameTag(old, new))
+	}
+	if *reassign != 0 {
+		mutations = append(mutations, bulk.Reassign(*reassign))
+	}
+	if len(mutations) == 0 {
+		flags.Usage()
+		return fmt.Errorf("no change requested")
+	}
+	if err := e.requireProduct(); err != nil {
+		return err
+	}
+
+	opts.Progress = func(outcome *bulk.Outcome, done, total int) {
+		if outcome.Err != nil {
+			fmt.Fprintf(e.stderr, "[%v/%v] #%v failed: %v\n", done, total, outcome.Number, outcome.Err)
+		}
+	}
+	res, err := bulk.Run(e.client, e.productId, &sel, func(item *sprintly.Item) error {
+		for _, mutate := range mutations {
+			if err := mutate(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, &opts)
+
+	// The failures are reported by the progress callback and listed in the output,
+	// the error is only returned to make the command fail.
+	var perr *bulk.PartialError
+	if errors.As(err, &perr) {
+		err = fmt.Errorf("%v of %v items failed", len(perr.Failed), perr.Total)
+	}
+	if res != nil {
+		if werr := printBulk(e, res); err == nil {
+			err = werr
+		}
+	}
+	return err
+}
+
+func printBulk(e *env, res *bulk.Result) error {
+	header := []string{"number", "result", "changes"}
+	var rows [][]string
+	for _, outcome := range res.Outcomes {
+		var result, changes string
+		switch {
+		case outcome.Err != nil:
+			result, changes = "failed", outcome.Err.Error()
+		case outcome.Skipped():
+			result = "unchanged"
+		case res.DryRun:
+			result, changes = "would change", strings.ReplaceAll(outcome.Changes.String(), "\n", "; ")
+		default:
+			result, changes = "changed", strings.ReplaceAll(outcome.Changes.String(), "\n", "; ")
+		}
+		rows = append(rows, []string{strconv.Itoa(outcome.Number), result, changes})
+	}
+	return e.out.print(res, header, rows)
+}
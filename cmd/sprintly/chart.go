@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/salsita/go-sprintly/charts"
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+func itemsChart(e *env, args []string) error {
+	var tags listFlag
+	flags := e.flags()
+	kind := flags.String("type", "burndown", "chart type: burndown or cfd")
+	from := flags.String("from", "", "first day, YYYY-MM-DD")
+	to := flags.String("to", "", "last day, YYYY-MM-DD, defaults to today")
+	flags.Var(&tags, "tags", "comma-separated tags the items must have")
+	title := flags.String("title", "", "chart title")
+	countItems := flags.Bool("items", false, "burn down the item count instead of the points")
+	asCSV := flags.Bool("csv", false, "write the daily data as CSV instead of the chart")
+	output := flags.String("o", "", "output file, defaults to the standard output")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *from == "" {
+		flags.Usage()
+		return fmt.Errorf("first day required")
+	}
+	start, err := time.ParseInLocation("2006-01-02", *from, time.Local)
+	if err != nil {
+		return err
+	}
+	end := time.Now()
+	if *to != "" {
+		if end, err = time.ParseInLocation("2006-01-02", *to, time.Local); err != nil {
+			return err
+		}
+	}
+	if end.Before(start) {
+		return fmt.Errorf("the last day precedes the first day")
+	}
+
+	var render func(io.Writer, []charts.Day, *charts.Options) error
+	switch *kind {
+	case "burndown":
+		render = charts.Burndown
+	case "cfd":
+		render = charts.CumulativeFlow
+	default:
+		return fmt.Errorf("unknown chart type: %v", *kind)
+	}
+	if err := e.requireProduct(); err != nil {
+		return err
+	}
+
+	items, _, err := e.client.Items.ListAll(e.productId, &sprintly.ItemListArgs{
+		Status:   sprintly.ItemStatuses,
+		Tags:     tags,
+		Children: true,
+	})
+	if err != nil {
+		return err
+	}
+	days := charts.Daily(slices.Values(items), start, end, time.Local)
+
+	w := e.stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if *asCSV {
+		return charts.WriteCSV(w, days)
+	}
+	return render(w, days, &charts.Options{Title: *title, Items: *countItems})
+}
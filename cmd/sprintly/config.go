@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+// config represents the sprintly configuration file.
+type config struct {
+	Username string `json:"username,omitempty"`
+	Token    string `json:"token,omitempty"`
+	Product  int    `json:"product,omitempty"`
+	URL      string `json:"url,omitempty"`
+}
+
+// loadConfig reads the config file, if any, and applies the environment on top of it.
+//
+// The file at the given path must exist, the default location is optional.
+func loadConfig(path string) (*config, error) {
+	var cfg config
+
+	required := true
+	if path == "" {
+		path = os.Getenv("SPRINTLY_CONFIG")
+	}
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err == nil {
+			path = filepath.Join(home, ".sprintly.json")
+			required = false
+		}
+	}
+
+	if path != "" {
+		content, err := os.ReadFile(path)
+		switch {
+		case err == nil:
+			if err := json.Unmarshal(content, &cfg); err != nil {
+				return nil, fmt.Errorf("failed to parse %v: %v", path, err)
+			}
+		case os.IsNotExist(err) && !required:
+		default:
+			return nil, err
+		}
+	}
+
+	if v := os.Getenv("SPRINTLY_USERNAME"); v != "" {
+		cfg.Username = v
+	}
+	if v := os.Getenv("SPRINTLY_TOKEN"); v != "" {
+		cfg.Token = v
+	}
+	if v := os.Getenv("SPRINTLY_URL"); v != "" {
+		cfg.URL = v
+	}
+	if v := os.Getenv("SPRINTLY_PRODUCT"); v != "" {
+		productId, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SPRINTLY_PRODUCT: %v", v)
+		}
+		cfg.Product = productId
+	}
+
+	return &cfg, nil
+}
+
+// newClient returns a Sprintly client configured according to the config.
+func (cfg *config) newClient() (*sprintly.Client, error) {
+	if cfg.Username == "" || cfg.Token == "" {
+		return nil, fmt.Errorf("credentials not set, use SPRINTLY_USERNAME and SPRINTLY_TOKEN")
+	}
+
+	client := sprintly.NewClient(cfg.Username, cfg.Token)
+	if cfg.URL != "" {
+		if err := client.SetBaseURL(cfg.URL); err != nil {
+			return nil, err
+		}
+	}
+	return client, nil
+}
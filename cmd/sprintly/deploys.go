@@ -0,0 +1,360 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/salsita/go-sprintly/analytics"
+	"github.com/salsita/go-sprintly/commits"
+	"github.com/salsita/go-sprintly/releasenotes"
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+var deploysCommands = map[string]*command{
+	"list": {
+		usage: "list [-environment env]",
+		help:  "List the product deploys.",
+		run:   deploysList,
+	},
+	"create": {
+		usage: "create -environment <env> <number>...",
+		help:  "Record a deploy of the given items.",
+		run:   deploysCreate,
+	},
+	"notes": {
+		usage: "notes -environment <env> [-since time] [-until time] [-last n] [-tasks] [-as markdown|html] [-template file]",
+		help:  "Generate release notes from the items deployed to the environment.",
+		run:   deploysNotes,
+	},
+	"drift": {
+		usage: "drift -from <env> -to <env>",
+		help:  "List the items deployed to one environment, but not to the other.",
+		run:   deploysDrift,
+	},
+	"promote": {
+		usage: "promote -from <env> -to <env> [-yes]",
+		help:  "Deploy to the target environment the items it is missing.",
+		run:   deploysPromote,
+	},
+	"record": {
+		usage: "record [-environment env] [-range from..to] [-dir repository] [-dry-run]",
+		help:  "Record a deploy of the items referenced in the revision range, the CI variables provide the defaults.",
+		run:   deploysRecord,
+	},
+	"dora": {
+		usage: "dora -environment <env> [-from time] [-to time] [-window-days n] [-failure-window-hours n]",
+		help:  "Compute the deployment frequency, lead time for changes and change failure rate.",
+		run:   deploysDORA,
+	},
+}
+
+func deploysList(e *env, args []string) error {
+	var listArgs sprintly.DeployListArgs
+	flags := e.flags()
+	flags.StringVar(&listArgs.Environment, "environment", "", "environment name")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := e.requireProduct(); err != nil {
+		return err
+	}
+
+	deploys, _, err := e.client.Deploys.List(e.productId, &listArgs)
+	if err != nil {
+		return err
+	}
+	return e.out.printDeploys(deploys)
+}
+
+func deploysCreate(e *env, args []string) error {
+	var createArgs sprintly.DeployCreateArgs
+	flags := e.flags()
+	flags.StringVar(&createArgs.Environment, "environment", "", "environment name")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if createArgs.Environment == "" || flags.NArg() == 0 {
+		flags.Usage()
+		return fmt.Errorf("environment and item numbers required")
+	}
+	for _, arg := range flags.Args() {
+		number, err := parseItemNumber(arg)
+		if err != nil {
+			return err
+		}
+		createArgs.ItemNumbers = append(createArgs.ItemNumbers, number)
+	}
+	if err := e.requireProduct(); err != nil {
+		return err
+	}
+
+	deploy, _, err := e.client.Deploys.Create(e.productId, &createArgs)
+	if err != nil {
+		return err
+	}
+	return e.out.printDeploys([]sprintly.Deploy{*deploy})
+}
+
+func deploysNotes(e *env, args []string) error {
+	var opts releasenotes.Options
+	flags := e.flags()
+	flags.StringVar(&opts.Environment, "environment", "", "environment name")
+	since := flags.String("since", "", "only deploys after the given time, RFC 3339 or YYYY-MM-DD")
+	until := flags.String("until", "", "only deploys until the given time, RFC 3339 or YYYY-MM-DD")
+	flags.IntVar(&opts.Last, "last", 0, "only the given number of the most recent deploys")
+	flags.BoolVar(&opts.IncludeTasks, "tasks", false, "list tasks and tests as well")
+	flags.StringVar(&opts.Title, "title", "", "release notes title")
+	format := flags.String("as", "markdown", "output format: markdown or html")
+	templatePath := flags.String("template", "", "template file overriding the format, html/template for .html files")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if opts.Environment == "" {
+		flags.Usage()
+		return fmt.Errorf("environment required")
+	}
+
+	var err error
+	if opts.Since, err = parseTime(*since); err != nil {
+		return err
+	}
+	if opts.Until, err = parseTime(*until); err != nil {
+		return err
+	}
+
+	var tmpl releasenotes.Executor
+	if *templatePath != "" {
+		tmpl, err = releasenotes.ParseTemplateFile(*templatePath)
+	} else {
+		tmpl, err = releasenotes.Template(*format)
+	}
+	if err != nil {
+		return err
+	}
+	if err := e.requireProduct(); err != nil {
+		return err
+	}
+
+	notes, err := releasenotes.Generate(e.client, e.productId, &opts)
+	if err != nil {
+		return err
+	}
+	return notes.Render(e.stdout, tmpl)
+}
+
+// parseTime parses an RFC 3339 time or a local date, the zero time when empty.
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q, RFC 3339 or YYYY-MM-DD expected", value)
+	}
+	return t, nil
+}
+
+// driftFlags registers the flags shared by deploys drift and deploys promote.
+func driftFlags(e *env) (*flag.FlagSet, *string, *string) {
+	flags := e.flags()
+	from := flags.String("from", "", "source environment, e.g. staging")
+	to := flags.String("to", "", "target environment, e.g. production")
+	return flags, from, to
+}
+
+func deploysDrift(e *env, args []string) error {
+	flags, from, to := driftFlags(e)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *from == "" || *to == "" {
+		flags.Usage()
+		return fmt.Errorf("both environments required")
+	}
+	if err := e.requireProduct(); err != nil {
+		return err
+	}
+
+	drift, _, err := e.client.Deploys.Compare(e.productId, *from, *to)
+	if err != nil {
+		return err
+	}
+	return e.out.printDrift(drift)
+}
+
+func deploysPromote(e *env, args []string) error {
+	flags, from, to := driftFlags(e)
+	yes := flags.Bool("yes", false, "promote without confirmation")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *from == "" || *to == "" {
+		flags.Usage()
+		return fmt.Errorf("both environments required")
+	}
+	if err := e.requireProduct(); err != nil {
+		return err
+	}
+
+	drift, _, err := e.client.Deploys.Compare(e.productId, *from, *to)
+	if err != nil {
+		return err
+	}
+	if len(drift.Ahead) == 0 {
+		e.out.message("Nothing to promote, %v carries all the items deployed to %v", *to, *from)
+		return nil
+	}
+	if !*yes {
+		for _, item := range drift.Ahead {
+			fmt.Fprintf(e.stderr, "#%v %v\n", item.Number, item.Title)
+		}
+		ok, err := e.confirm(fmt.Sprintf("Deploy these %v items to %v?", len(drift.Ahead), *to))
+		if err != nil || !ok {
+			return err
+		}
+	}
+
+	// Deploy exactly the items confirmed, Deploys.Promote would compare again.
+	createArgs := &sprintly.DeployCreateArgs{Environment: *to}
+	for _, item := range drift.Ahead {
+		createArgs.ItemNumbers = append(createArgs.ItemNumbers, item.Number)
+	}
+	deploy, _, err := e.client.Deploys.Create(e.productId, createArgs)
+	if err != nil {
+		return err
+	}
+	return e.out.printDeploys([]sprintly.Deploy{*deploy})
+}
+
+func deploysRecord(e *env, args []string) error {
+	flags := e.flags()
+	environment := flags.String("environment", "", "environment name, detected in CI by default")
+	revisionRange := flags.String("range", "", "git revision range, e.g. v1.0..v1.1, detected in CI by default")
+	dir := flags.String("dir", "", "git repository directory")
+	dryRun := flags.Bool("dry-run", false, "only list the items referenced")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *environment == "" || *revisionRange == "" {
+		// The range given overrides SPRINTLY_REVISION_RANGE, malformed or not.
+		getenv := os.Getenv
+		if *revisionRange != "" {
+			getenv = func(name string) string {
+				if name == "SPRINTLY_REVISION_RANGE" {
+					return ""
+				}
+				return os.Getenv(name)
+			}
+		}
+		ci, err := commits.DetectCI(getenv)
+		if err != nil {
+			return err
+		}
+		if *environment == "" {
+			*environment = ci.Environment
+		}
+		if *revisionRange == "" {
+			*revisionRange = ci.Range()
+		}
+	}
+	if *environment == "" || *revisionRange == "" {
+		flags.Usage()
+		return fmt.Errorf("environment and revision range required, they could not be detected")
+	}
+	if err := e.requireProduct(); err != nil {
+		return err
+	}
+
+	rec := &commits.DeployRecorder{
+		Client:    e.client,
+		ProductId: e.productId,
+		Dir:       *dir,
+	}
+
+	if *dryRun {
+		numbers, err := rec.Items(*revisionRange)
+		if err != nil {
+			return err
+		}
+		refs := make([]string, len(numbers))
+		for i, number := range numbers {
+			refs[i] = "#" + strconv.Itoa(number)
+		}
+		e.out.message("Items referenced in %v: %v", *revisionRange, strings.Join(refs, " "))
+		return nil
+	}
+
+	deploy, _, err := rec.Record(*environment, *revisionRange)
+	if err != nil {
+		return err
+	}
+	if deploy == nil {
+		e.out.message("No items referenced in %v, no deploy recorded", *revisionRange)
+		return nil
+	}
+	return e.out.printDeploys([]sprintly.Deploy{*deploy})
+}
+
+func deploysDORA(e *env, args []string) error {
+	var opts analytics.DORAOptions
+	flags := e.flags()
+	flags.StringVar(&opts.Environment, "environment", "production", "environment name")
+	from := flags.String("from", "", "period start, RFC 3339 or YYYY-MM-DD, defaults to the first deploy")
+	to := flags.String("to", "", "period end, RFC 3339 or YYYY-MM-DD, defaults to now")
+	windowDays := flags.Int("window-days", 7, "window length in days, 0 to report the whole period only")
+	failureHours := flags.Int("failure-window-hours", 0, "hours after a deploy in which a new defect fails it")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var err error
+	if opts.From, err = parseTime(*from); err != nil {
+		return err
+	}
+	if opts.To, err = parseTime(*to); err != nil {
+		return err
+	}
+	opts.Window = time.Duration(*windowDays) * 24 * time.Hour
+	opts.FailureWindow = time.Duration(*failureHours) * time.Hour
+	if err := e.requireProduct(); err != nil {
+		return err
+	}
+
+	deploys, _, err := e.client.Deploys.List(e.productId, &sprintly.DeployListArgs{Environment: opts.Environment})
+	if err != nil {
+		return err
+	}
+	items, _, err := e.client.Items.ListAll(e.productId, &sprintly.ItemListArgs{
+		Status:   sprintly.ItemStatuses,
+		Children: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	report := analytics.DORA(deploys, slices.Values(items), &opts)
+
+	windows := append([]*analytics.DORAMetrics{report.Overall}, report.Windows...)
+	rows := make([][]string, len(windows))
+	for i, m := range windows {
+		rows[i] = []string{
+			m.From.Format("2006-01-02"),
+			m.To.Format("2006-01-02"),
+			strconv.Itoa(m.Deploys),
+			strconv.FormatFloat(m.DeploysPerDay, 'f', 2, 64),
+			days(m.LeadTime.P50),
+			strconv.FormatFloat(m.ChangeFailureRate*100, 'f', 0, 64) + "%",
+		}
+	}
+	header := []string{"from", "to", "deploys", "per_day", "lead_time_p50", "failure_rate"}
+	return e.out.print(report, header, rows)
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/salsita/go-sprintly/export"
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+func itemsExport(e *env, args []string) error {
+	var (
+		listArgs sprintly.ItemListArgs
+		statuses listFlag
+		columns  listFlag
+	)
+	flags := e.flags()
+	format := flags.String("as", "markdown", "export format: jsonl, csv or markdown")
+	flags.Var(&columns, "columns", "comma-separated CSV columns")
+	flags.Var(&statuses, "status", "comma-separated statuses, all by default")
+	title := flags.String("title", "", "Markdown report title")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := e.requireProduct(); err != nil {
+		return err
+	}
+	for _, status := range statuses {
+		listArgs.Status = append(listArgs.Status, sprintly.ItemStatus(status))
+	}
+
+	var w export.Writer
+	switch *format {
+	case "jsonl":
+		w = export.NewJSONLinesWriter(e.stdout)
+	case "csv":
+		cols, err := export.Columns(columns...)
+		if err != nil {
+			return err
+		}
+		w = export.NewCSVWriter(e.stdout, cols)
+	case "markdown":
+		w = export.NewMarkdownWriter(e.stdout, *title)
+	default:
+		return fmt.Errorf("unknown export format: %v", *format)
+	}
+	return export.Export(e.client, e.productId, &listArgs, w)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/salsita/go-sprintly/importer"
+)
+
+func itemsImport(e *env, args []string) error {
+	var mappings listFlag
+	flags := e.flags()
+	path := flags.String("f", "", "CSV file")
+	flags.Var(&mappings, "map", "comma-separated field=header column mappings")
+	journalPath := flags.String("journal", "", "journal file, defaults to <file>.journal")
+	dryRun := flags.Bool("dry-run", false, "only report what would be imported")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *path == "" {
+		flags.Usage()
+		return fmt.Errorf("CSV file required")
+	}
+	if *journalPath == "" {
+		*journalPath = *path + ".journal"
+	}
+	if err := e.requireProduct(); err != nil {
+		return err
+	}
+
+	mapping, err := importer.ParseMapping(mappings)
+	if err != nil {
+		return err
+	}
+	file, err := os.Open(*path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	rows, err := importer.Read(file, mapping)
+	if err != nil {
+		return err
+	}
+
+	imp := &importer.Importer{
+		Client:    e.client,
+		ProductId: e.productId,
+		DryRun:    *dryRun,
+	}
+	// The dry run reads the journal to report the rows already imported.
+	if *dryRun {
+		imp.Journal, err = importer.ReadJournal(*journalPath)
+		if err != nil {
+			return err
+		}
+	} else {
+		journal, err := importer.OpenJournal(*journalPath)
+		if err != nil {
+			return err
+		}
+		defer journal.Close()
+		imp.Journal = journal
+	}
+
+	report, err := imp.Import(rows)
+	if werr := report.Write(e.stdout); err == nil {
+		err = werr
+	}
+	return err
+}
@@ -0,0 +1,341 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/salsita/go-sprintly/editor"
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+var itemsCommands = map[string]*command{
+	"list": {
+		usage: "list [-status s1,s2] [-type t1,t2] [-tags t1,t2] [-assigned-to id] [-all]",
+		help:  "List the product items.",
+		run:   itemsList,
+	},
+	"get": {
+		usage: "get <number>",
+		help:  "Show the given item.",
+		run:   itemsGet,
+	},
+	"create": {
+		usage: "create -type <type> [-title title | -who who -what what -why why] [-idempotency-key key] [flags]",
+		help:  "Create a new item.",
+		run:   itemsCreate,
+	},
+	"update": {
+		usage: "update [flags] [-last-modified time] <number>",
+		help:  "Update the given item, only the fields passed in are changed.",
+		run:   itemsUpdate,
+	},
+	"children": {
+		usage: "children <number>",
+		help:  "List the children of the given item.",
+		run:   itemsChildren,
+	},
+	"edit": {
+		usage: "edit [-yes] [-merge] <number>",
+		help:  "Edit the given item in $EDITOR and apply the fields changed.",
+		run:   itemsEdit,
+	},
+	"plan": {
+		usage: "plan -f <manifest> [-state file]",
+		help:  "Show the changes needed to bring the product items in sync with the manifest.",
+		run:   itemsPlan,
+	},
+	"apply": {
+		usage: "apply -f <manifest> [-state file] [-yes]",
+		help:  "Create and update the product items according to the manifest.",
+		run:   itemsApply,
+	},
+	"export": {
+		usage: "export [-as jsonl|csv|markdown] [-columns c1,c2] [-status s1,s2] [-title title]",
+		help:  "Export all the product items with the people resolved and the parents linked.",
+		run:   itemsExport,
+	},
+	"import": {
+		usage: "import -f <file.csv> [-map field=header,...] [-journal file] [-dry-run]",
+		help:  "Create items from the CSV file, a failed import can be run again to resume.",
+		run:   itemsImport,
+	},
+	"move": {
+		usage: "move -to <product> [-comments] [-link=false] [-yes] <number>",
+		help:  "Move the item and its children to another product, archiving the originals.",
+		run:   itemsMove,
+	},
+	"copy": {
+		usage: "copy -to <product> [-comments] [-link] <number>",
+		help:  "Copy the item and its children to another product.",
+		run:   itemsCopy,
+	},
+	"bulk": {
+		usage: "bulk [-status s1,s2] [-tags t1,t2] [-assigned-to id] [-rename-tag old=new] [-reassign id] [-dry-run] [flags] [number...]",
+		help:  "Change many items at once, selected by number or by the filter flags.",
+		run:   itemsBulk,
+	},
+	"stats": {
+		usage: "stats [-by type|tag|assignee] [-weeks n]",
+		help:  "Show the lead time, cycle time and weekly velocity of the product items.",
+		run:   itemsStats,
+	},
+	"chart": {
+		usage: "chart [-type burndown|cfd] -from <date> [-to date] [-tags t1,t2] [-csv] [-o file]",
+		help:  "Draw a burndown chart or a cumulative flow diagram as SVG.",
+		run:   itemsChart,
+	},
+}
+
+func itemsList(e *env, args []string) error {
+	var (
+		listArgs sprintly.ItemListArgs
+		statuses listFlag
+		types    listFlag
+		tags     listFlag
+	)
+	flags := e.flags()
+	flags.Var(&statuses, "status", "comma-separated statuses")
+	flags.Var(&types, "type", "comma-separated item types")
+	flags.Var(&tags, "tags", "comma-separated tags")
+	flags.IntVar(&listArgs.AssignedTo, "assigned-to", 0, "assignee user ID")
+	flags.IntVar(&listArgs.CreatedBy, "created-by", 0, "creator user ID")
+	flags.IntVar(&listArgs.Limit, "limit", 0, "maximum number of items to return")
+	flags.IntVar(&listArgs.Offset, "offset", 0, "number of items to skip")
+	flags.BoolVar(&listArgs.Children, "children", false, "include child items")
+	orderBy := flags.String("order-by", "", "ordering: oldest, newest, priority, recent, stale, active or abandoned")
+	all := flags.Bool("all", false, "fetch all pages")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := e.requireProduct(); err != nil {
+		return err
+	}
+
+	for _, status := range statuses {
+		listArgs.Status = append(listArgs.Status, sprintly.ItemStatus(status))
+	}
+	for _, typ := range types {
+		listArgs.Type = append(listArgs.Type, sprintly.ItemType(typ))
+	}
+	listArgs.Tags = tags
+	listArgs.OrderBy = sprintly.ItemOrdering(*orderBy)
+
+	var (
+		items []sprintly.Item
+		err   error
+	)
+	if *all {
+		items, _, err = e.client.Items.ListAll(e.productId, &listArgs)
+	} else {
+		items, _, err = e.client.Items.List(e.productId, &listArgs)
+	}
+	if err != nil {
+		return err
+	}
+	return e.out.printItems(items)
+}
+
+func itemsGet(e *env, args []string) error {
+	flags := e.flags()
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	number, err := itemNumberArg(flags)
+	if err != nil {
+		return err
+	}
+	if err := e.requireProduct(); err != nil {
+		return err
+	}
+
+	item, _, err := e.client.Items.Get(e.productId, number)
+	if err != nil {
+		return err
+	}
+	return e.out.printItem(item)
+}
+
+// itemFlags registers the flags shared by items create and items update.
+func itemFlags(flags *flag.FlagSet, args *sprintly.ItemUpdateArgs) *listFlag {
+	var tags listFlag
+	flags.StringVar(&args.Type, "type", "", "item type: story, task, defect or test")
+	flags.StringVar(&args.Title, "title", "", "item title, not used for stories")
+	flags.StringVar(&args.Who, "who", "", "story who")
+	flags.StringVar(&args.What, "what", "", "story what")
+	flags.StringVar(&args.Why, "why", "", "story why")
+	flags.StringVar(&args.Description, "description", "", "item description")
+	flags.StringVar((*string)(&args.Score), "score", "", "item score: ~, S, M, L or XL")
+	flags.StringVar((*string)(&args.Status), "status", "", "item status")
+	flags.IntVar(&args.AssignedTo, "assigned-to", 0, "assignee user ID")
+	flags.Var(&tags, "tags", "comma-separated tags")
+	return &tags
+}
+
+func itemsCreate(e *env, args []string) error {
+	var updateArgs sprintly.ItemUpdateArgs
+	flags := e.flags()
+	tags := itemFlags(flags, &updateArgs)
+	key := flags.String("idempotency-key", "", "create the item only once for the given key")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		flags.Usage()
+		return fmt.Errorf("unexpected arguments: %v", flags.Args())
+	}
+	if err := e.requireProduct(); err != nil {
+		return err
+	}
+
+	createArgs := &sprintly.ItemCreateArgs{
+		Type:        updateArgs.Type,
+		Title:       updateArgs.Title,
+		Who:         updateArgs.Who,
+		What:        updateArgs.What,
+		Why:         updateArgs.Why,
+		Description: updateArgs.Description,
+		Score:       updateArgs.Score,
+		Status:      updateArgs.Status,
+		AssignedTo:  updateArgs.AssignedTo,
+		Tags:        *tags,
+	}
+
+	var (
+		item *sprintly.Item
+		err  error
+	)
+	if *key != "" {
+		var created bool
+		item, created, _, err = e.client.Items.CreateIdempotent(e.productId, *key, createArgs)
+		if err == nil && !created {
+			fmt.Fprintf(e.stderr, "Item #%v already created with key %v\n", item.Number, *key)
+		}
+	} else {
+		item, _, err = e.client.Items.Create(e.productId, createArgs)
+	}
+	if err != nil {
+		return err
+	}
+	return e.out.printItem(item)
+}
+
+func itemsUpdate(e *env, args []string) error {
+	var updateArgs sprintly.ItemUpdateArgs
+	flags := e.flags()
+	tags := itemFlags(flags, &updateArgs)
+	flags.IntVar(&updateArgs.Parent, "parent", 0, "parent item number")
+	lastModified := flags.String("last-modified", "", "only update the item when last modified at the given time")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	number, err := itemNumberArg(flags)
+	if err != nil {
+		return err
+	}
+	if err := e.requireProduct(); err != nil {
+		return err
+	}
+
+	updateArgs.Tags = *tags
+
+	var item *sprintly.Item
+	if *lastModified != "" {
+		var expected time.Time
+		if expected, err = parseTime(*lastModified); err != nil {
+			return err
+		}
+		item, _, err = e.client.Items.UpdateIf(e.productId, number, &expected, &updateArgs)
+	} else {
+		item, _, err = e.client.Items.Update(e.productId, number, &updateArgs)
+	}
+	if err != nil {
+		return err
+	}
+	return e.out.printItem(item)
+}
+
+func itemsChildren(e *env, args []string) error {
+	flags := e.flags()
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	number, err := itemNumberArg(flags)
+	if err != nil {
+		return err
+	}
+	if err := e.requireProduct(); err != nil {
+		return err
+	}
+
+	items, _, err := e.client.Items.ListChildren(e.productId, number)
+	if err != nil {
+		return err
+	}
+	return e.out.printItems(items)
+}
+
+func itemsEdit(e *env, args []string) error {
+	flags := e.flags()
+	yes := flags.Bool("yes", false, "apply the changes without confirmation")
+	merge := flags.Bool("merge", false, "merge with the changes made by others while editing")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	number, err := itemNumberArg(flags)
+	if err != nil {
+		return err
+	}
+	if err := e.requireProduct(); err != nil {
+		return err
+	}
+
+	ed := &editor.Editor{
+		Client: e.client,
+		Launch: e.launchEditor,
+		Merge:  *merge,
+	}
+	if !*yes {
+		ed.Confirm = e.confirmChanges
+	}
+
+	item, changes, err := ed.Edit(e.productId, number)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		fmt.Fprintln(e.stderr, "No changes applied")
+	}
+	return e.out.printItem(item)
+}
+
+// confirmChanges prints the changes and asks the user to confirm them.
+func (e *env) confirmChanges(item *sprintly.Item, changes []editor.Change) (bool, error) {
+	fmt.Fprintf(e.stderr, "Changes to item #%v:\n", item.Number)
+	for _, change := range changes {
+		fmt.Fprintf(e.stderr, "  %v\n", change)
+	}
+	return e.confirm("Apply these changes?")
+}
+
+// itemNumberArg returns the only positional argument as an item number.
+func itemNumberArg(flags *flag.FlagSet) (int, error) {
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 0, fmt.Errorf("exactly one item number expected")
+	}
+	return parseItemNumber(flags.Arg(0))
+}
+
+// parseItemNumber accepts both 123 and #123.
+func parseItemNumber(arg string) (int, error) {
+	if len(arg) > 1 && arg[0] == '#' {
+		arg = arg[1:]
+	}
+	number, err := strconv.Atoi(arg)
+	if err != nil || number <= 0 {
+		return 0, fmt.Errorf("invalid item number: %v", arg)
+	}
+	return number, nil
+}
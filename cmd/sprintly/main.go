@@ -0,0 +1,205 @@
+// Command sprintly is a command line interface to the Sprintly API.
+//
+// Usage:
+//
+//	sprintly [global flags] <group> <command> [flags] [args]
+//
+// The credentials are read from the environment (SPRINTLY_USERNAME, SPRINTLY_TOKEN,
+// SPRINTLY_PRODUCT, SPRINTLY_URL) or from the JSON config file located at
+// $SPRINTLY_CONFIG or ~/.sprintly.json, the environment taking precedence.
+//
+// Run sprintly -h to get the list of available commands.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+// env holds everything the commands need to do their job.
+type env struct {
+	client    *sprintly.Client
+	config    *config
+	productId int
+	out       *printer
+	stdin     io.Reader
+	stdout    io.Writer
+	stderr    io.Writer
+
+	// The command being run, used to print the usage.
+	group string
+	cmd   *command
+
+	// launchEditor opens the given file in the editor, nil means $EDITOR.
+	launchEditor func(path string) error
+}
+
+// command represents a single sprintly subcommand.
+type command struct {
+	usage string
+	help  string
+	run   func(e *env, args []string) error
+}
+
+// groups lists all the commands available, grouped by the resource they manage.
+var groups = map[string]map[string]*command{
+	"items":   itemsCommands,
+	"people":  peopleCommands,
+	"deploys": deploysCommands,
+	"mirror":  mirrorCommands,
+}
+
+func main() {
+	err := run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+	if err == flag.ErrHelp {
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "sprintly: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("sprintly", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() { printUsage(stderr, flags) }
+
+	var (
+		configPath = flags.String("config", "", "config file path")
+		format     = flags.String("format", "table", "output format: table, json or csv")
+		productId  = flags.Int("product", 0, "product ID, overrides the configured one")
+	)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	args = flags.Args()
+	if len(args) < 2 {
+		flags.Usage()
+		return fmt.Errorf("group and command required")
+	}
+
+	cmds, ok := groups[args[0]]
+	if !ok {
+		flags.Usage()
+		return fmt.Errorf("unknown command group: %v", args[0])
+	}
+	cmd, ok := cmds[args[1]]
+	if !ok {
+		flags.Usage()
+		return fmt.Errorf("unknown %v command: %v", args[0], args[1])
+	}
+
+	out, err := newPrinter(*format, stdout)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	if *productId != 0 {
+		cfg.Product = *productId
+	}
+
+	client, err := cfg.newClient()
+	if err != nil {
+		return err
+	}
+
+	return cmd.run(&env{
+		client:    client,
+		config:    cfg,
+		productId: cfg.Product,
+		out:       out,
+		stdin:     stdin,
+		stdout:    stdout,
+		stderr:    stderr,
+		group:     args[0],
+		cmd:       cmd,
+	}, args[2:])
+}
+
+func printUsage(w io.Writer, flags *flag.FlagSet) {
+	fmt.Fprintln(w, "Usage: sprintly [global flags] <group> <command> [flags] [args]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Global flags:")
+	flags.PrintDefaults()
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+
+	var names []string
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, group := range names {
+		var cmds []string
+		for name := range groups[group] {
+			cmds = append(cmds, name)
+		}
+		sort.Strings(cmds)
+
+		for _, name := range cmds {
+			cmd := groups[group][name]
+			fmt.Fprintf(w, "  %v %v\n        %v\n", group, cmd.usage, cmd.help)
+		}
+	}
+}
+
+// flags returns a new flag set for the command being run.
+func (e *env) flags() *flag.FlagSet {
+	flags := flag.NewFlagSet(e.group, flag.ContinueOnError)
+	flags.SetOutput(e.stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(e.stderr, "Usage: sprintly %v %v\n\n%v\n\n", e.group, e.cmd.usage, e.cmd.help)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// requireProduct returns an error when no product ID is configured.
+func (e *env) requireProduct() error {
+	if e.productId == 0 {
+		return fmt.Errorf("product ID not set, use -product or SPRINTLY_PRODUCT")
+	}
+	return nil
+}
+
+// confirm asks the user the given yes/no question, no being the default.
+func (e *env) confirm(question string) (bool, error) {
+	fmt.Fprintf(e.stderr, "%v [y/N] ", question)
+
+	answer, err := bufio.NewReader(e.stdin).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
+
+// listFlag is a flag.Value holding a comma-separated list of strings.
+type listFlag []string
+
+func (list *listFlag) String() string {
+	return strings.Join(*list, ",")
+}
+
+func (list *listFlag) Set(value string) error {
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*list = append(*list, v)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,393 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setup starts a testing server and points the sprintly command at it.
+func setup(t *testing.T) *http.ServeMux {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("SPRINTLY_CONFIG", "")
+	t.Setenv("SPRINTLY_USERNAME", "krtecek")
+	t.Setenv("SPRINTLY_TOKEN", "secret")
+	t.Setenv("SPRINTLY_PRODUCT", "1")
+	t.Setenv("SPRINTLY_URL", server.URL)
+	return mux
+}
+
+// runCommand runs the sprintly command and returns what it printed.
+func runCommand(t *testing.T, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	err := run(args, strings.NewReader(""), &stdout, &stderr)
+	return stdout.String(), err
+}
+
+var testingItemsJson = `[
+	{
+		"number": 188,
+		"type": "task",
+		"status": "backlog",
+		"score": "M",
+		"title": "Don't let un-scored items out of the backlog.",
+		"assigned_to": {"id": 1, "first_name": "Joe", "last_name": "Stump"}
+	}
+]`
+
+func TestItemsList(t *testing.T) {
+	mux := setup(t)
+	mux.HandleFunc("/products/1/items.json", func(w http.ResponseWriter, r *http.Request) {
+		if status := r.URL.Query().Get("status"); status != "backlog,in-progress" {
+			t.Errorf("status = %q", status)
+		}
+		fmt.Fprint(w, testingItemsJson)
+	})
+
+	out, err := runCommand(t, "-format", "csv", "items", "list", "-status", "backlog,in-progress")
+	if err != nil {
+		t.Fatalf("items list failed: %v", err)
+	}
+
+	want := "number,type,status,score,assignee,title\n" +
+		"188,task,backlog,M,Joe Stump,Don't let un-scored items out of the backlog.\n"
+	if out != want {
+		t.Errorf("items list printed\n%v\nwant\n%v", out, want)
+	}
+
+	out, err = runCommand(t, "-format", "json", "items", "list", "-status", "backlog,in-progress")
+	if err != nil {
+		t.Fatalf("items list failed: %v", err)
+	}
+	if !strings.Contains(out, `"number": 188`) {
+		t.Errorf("items list printed invalid JSON output:\n%v", out)
+	}
+
+	out, err = runCommand(t, "items", "list", "-status", "backlog,in-progress")
+	if err != nil {
+		t.Fatalf("items list failed: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(out), "\n"); len(lines) != 2 || !strings.HasPrefix(lines[0], "NUMBER") {
+		t.Errorf("items list printed invalid table:\n%v", out)
+	}
+}
+
+func TestItemsUpdate_LastModified(t *testing.T) {
+	mux := setup(t)
+	mux.HandleFunc("/products/1/items/188.json", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			t.Error("the item was updated despite the conflict")
+		}
+		fmt.Fprint(w, `{"number": 188, "last_modified": "2015-01-01T10:05:00Z"}`)
+	})
+
+	_, err := runCommand(t, "items", "update", "-status", "in-progress", "-last-modified", "2015-01-01T10:00:00Z", "188")
+	if err == nil || !strings.Contains(err.Error(), "modified in the meantime") {
+		t.Errorf("items update should have failed with a conflict, err = %v", err)
+	}
+}
+
+func TestItemsCopy(t *testing.T) {
+	mux := setup(t)
+	mux.HandleFunc("/products/1/items/188.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"number": 188, "type": "task", "title": "Copy me"}`)
+	})
+	mux.HandleFunc("/products/1/items/188/children.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[]`)
+	})
+	mux.HandleFunc("/products/2/people.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[]`)
+	})
+	mux.HandleFunc("/products/2/items.json", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		fmt.Fprintf(w, `{"number": 7, "type": "task", "title": %q}`, r.PostForm.Get("title"))
+	})
+
+	out, err := runCommand(t, "-format", "csv", "items", "copy", "-to", "2", "188")
+	if err != nil {
+		t.Fatalf("items copy failed: %v", err)
+	}
+	if want := "original,number,type,title\n188,7,task,Copy me\n"; out != want {
+		t.Errorf("items copy printed\n%v\nwant\n%v", out, want)
+	}
+
+	if _, err := runCommand(t, "items", "copy", "188"); err == nil {
+		t.Error("items copy without a target product should have failed")
+	}
+}
+
+func TestItemsExport(t *testing.T) {
+	mux := setup(t)
+	mux.HandleFunc("/products/1/items.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, testingItemsJson)
+	})
+	mux.HandleFunc("/products/1/people.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"id": 1, "first_name": "Joe", "last_name": "Stump", "email": "joe@joestump.net"}]`)
+	})
+
+	out, err := runCommand(t, "items", "export", "-as", "csv", "-columns", "number,assignee_email")
+	if err != nil {
+		t.Fatalf("items export failed: %v", err)
+	}
+	if want := "number,assignee_email\n188,joe@joestump.net\n"; out != want {
+		t.Errorf("items export printed\n%v\nwant\n%v", out, want)
+	}
+
+	if _, err := runCommand(t, "items", "export", "-as", "pdf"); err == nil {
+		t.Error("items export accepted an unknown format")
+	}
+}
+
+func TestItemsImport_DryRun(t *testing.T) {
+	mux := setup(t)
+	mux.HandleFunc("/products/1/items.json", func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("dry run sent %v %v", r.Method, r.URL)
+	})
+
+	path := filepath.Join(t.TempDir(), "backlog.csv")
+	if err := os.WriteFile(path, []byte("Kind,Summary\ntask,Write docs\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := runCommand(t, "items", "import", "-f", path, "-map", "type=Kind,title=Summary", "-dry-run")
+	if err != nil {
+		t.Fatalf("items import failed: %v", err)
+	}
+	if !strings.Contains(out, "create  line 2  key row-345c6c1e4ffd  Write docs") {
+		t.Errorf("items import printed:\n%v", out)
+	}
+	if _, err := os.Stat(path + ".journal"); !os.IsNotExist(err) {
+		t.Errorf("dry run created the journal: %v", err)
+	}
+}
+
+func TestItemsStats(t *testing.T) {
+	mux := setup(t)
+	mux.HandleFunc("/products/1/items.json", func(w http.ResponseWriter, r *http.Request) {
+		if status := r.URL.Query().Get("status"); status != "someday,backlog,in-progress,completed,accepted" {
+			t.Errorf("status = %q", status)
+		}
+		fmt.Fprint(w, `[{"number": 1, "type": "task", "status": "completed", "score": "M",
+			"created_at": "2015-01-01T12:00:00Z",
+			"progress": {"started_at": "2015-01-02T12:00:00Z", "closed_at": "2015-01-05T00:00:00Z"}}]`)
+	})
+
+	out, err := runCommand(t, "-format", "csv", "items", "stats", "-by", "type")
+	if err != nil {
+		t.Fatalf("items stats failed: %v", err)
+	}
+	want := "group,items,lead_p50,lead_p85,cycle_p50,cycle_p85,points_per_week\n" +
+		"task,1,3.5d,3.5d,2.5d,2.5d,3.0\n"
+	if out != want {
+		t.Errorf("items stats printed\n%v\nwant\n%v", out, want)
+	}
+}
+
+func TestDeploysCreate(t *testing.T) {
+	mux := setup(t)
+	mux.HandleFunc("/products/1/deploys.json", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if env := r.PostForm.Get("environment"); env != "staging" {
+			t.Errorf("environment = %q", env)
+		}
+		if numbers := r.PostForm.Get("numbers"); numbers != "1,2" {
+			t.Errorf("numbers = %q", numbers)
+		}
+		fmt.Fprint(w, `{"environment": "staging", "items": [{"number": 1}, {"number": 2}]}`)
+	})
+
+	out, err := runCommand(t, "deploys", "create", "-environment", "staging", "1", "#2")
+	if err != nil {
+		t.Fatalf("deploys create failed: %v", err)
+	}
+	if !strings.Contains(out, "staging      #1 #2") {
+		t.Errorf("deploys create printed:\n%v", out)
+	}
+}
+
+func TestDeploysPromote(t *testing.T) {
+	mux := setup(t)
+	mux.HandleFunc("/products/1/deploys.json", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			r.ParseForm()
+			if numbers := r.PostForm.Get("numbers"); numbers != "2" {
+				t.Errorf("numbers = %q", numbers)
+			}
+			fmt.Fprint(w, `{"environment": "production", "items": [{"number": 2}]}`)
+			return
+		}
+		if r.URL.Query().Get("environment") == "staging" {
+			fmt.Fprint(w, `[{"environment": "staging", "items": [{"number": 1}, {"number": 2}]}]`)
+		} else {
+			fmt.Fprint(w, `[{"environment": "production", "items": [{"number": 1}]}]`)
+		}
+	})
+	mux.HandleFunc("/products/1/items/2.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"number": 2, "type": "task", "status": "completed", "title": "Ship it"}`)
+	})
+
+	out, err := runCommand(t, "-format", "csv", "deploys", "drift", "-from", "staging", "-to", "production")
+	if err != nil {
+		t.Fatalf("deploys drift failed: %v", err)
+	}
+	if want := "missing_in,number,type,status,title\nproduction,2,task,completed,Ship it\n"; out != want {
+		t.Errorf("deploys drift printed\n%v\nwant\n%v", out, want)
+	}
+
+	out, err = runCommand(t, "deploys", "promote", "-from", "staging", "-to", "production", "-yes")
+	if err != nil {
+		t.Fatalf("deploys promote failed: %v", err)
+	}
+	if !strings.Contains(out, "production   #2") {
+		t.Errorf("deploys promote printed:\n%v", out)
+	}
+}
+
+func TestDeploysRecord_RangeFlag(t *testing.T) {
+	setup(t)
+	t.Setenv("SPRINTLY_REVISION_RANGE", "v1.0")
+
+	if _, err := runCommand(t, "deploys", "record", "-h"); err != flag.ErrHelp {
+		t.Errorf("deploys record -h failed with %v", err)
+	}
+
+	// The range given overrides the malformed one, the command fails on git instead.
+	_, err := runCommand(t, "deploys", "record", "-environment", "production", "-range", "v1.0..v1.1", "-dir", t.TempDir())
+	if err == nil || strings.Contains(err.Error(), "SPRINTLY_REVISION_RANGE") {
+		t.Errorf("deploys record -range failed with %v", err)
+	}
+
+	if _, err := runCommand(t, "deploys", "record", "-environment", "production"); err == nil ||
+		!strings.Contains(err.Error(), "SPRINTLY_REVISION_RANGE") {
+		t.Errorf("deploys record accepted the malformed SPRINTLY_REVISION_RANGE, err = %v", err)
+	}
+}
+
+func TestRun_Errors(t *testing.T) {
+	setup(t)
+
+	cases := [][]string{
+		{},
+		{"items"},
+		{"items", "unknown"},
+		{"-format", "xml", "items", "list"},
+		{"items", "get", "abc"},
+	}
+	for _, args := range cases {
+		if _, err := runCommand(t, args...); err == nil {
+			t.Errorf("sprintly %v should have failed", args)
+		}
+	}
+
+	t.Setenv("SPRINTLY_TOKEN", "")
+	if _, err := runCommand(t, "items", "list"); err == nil {
+		t.Errorf("sprintly should have failed without credentials")
+	}
+}
+
+func TestItemsBulk_DryRun(t *testing.T) {
+	mux := setup(t)
+	mux.HandleFunc("/products/1/items.json", func(w http.ResponseWriter, r *http.Request) {
+		if tags := r.URL.Query().Get("tags"); tags != "frontend" {
+			t.Errorf("tags = %q", tags)
+		}
+		if r.URL.Query().Get("offset") != "" {
+			fmt.Fprint(w, `[]`)
+			return
+		}
+		fmt.Fprint(w, `[{"number": 188, "type": "task", "title": "Logo", "tags": ["frontend"]}]`)
+	})
+
+	out, err := runCommand(t, "-format", "csv", "items", "bulk", "-tags", "frontend", "-rename-tag", "frontend=web", "-dry-run")
+	if err != nil {
+		t.Fatalf("items bulk failed: %v", err)
+	}
+	if want := "number,result,changes\n188,would change,tags: +web -frontend\n"; out != want {
+		t.Errorf("items bulk printed\n%v\nwant\n%v", out, want)
+	}
+
+	if _, err := runCommand(t, "items", "bulk", "-rename-tag", "frontend=web"); err == nil {
+		t.Error("items bulk without a selection should have failed")
+	}
+}
+
+func TestPeopleOffboard(t *testing.T) {
+	mux := setup(t)
+	mux.HandleFunc("/products/1/people.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"id": 1, "first_name": "Joe"}]`)
+	})
+	mux.HandleFunc("/products/1/items.json", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("offset") != "" {
+			fmt.Fprint(w, `[]`)
+			return
+		}
+		fmt.Fprint(w, testingItemsJson)
+	})
+	mux.HandleFunc("/products/1/items/188.json", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil || r.PostForm.Get("assigned_to") != "" {
+			t.Errorf("the item was not unassigned")
+		}
+		fmt.Fprint(w, `{"number": 188}`)
+	})
+	mux.HandleFunc("/products/1/items/188/comments.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{}`)
+	})
+	removed := false
+	mux.HandleFunc("/products/1/people/1.json", func(w http.ResponseWriter, r *http.Request) {
+		removed = r.Method == "DELETE"
+	})
+
+	out, err := runCommand(t, "-format", "csv", "people", "offboard", "-yes", "1")
+	if err != nil {
+		t.Fatalf("people offboard failed: %v", err)
+	}
+	if want := "product,number,result,removed\n1,188,unassigned,true\n"; out != want {
+		t.Errorf("people offboard printed\n%v\nwant\n%v", out, want)
+	}
+	if !removed {
+		t.Error("the person was not removed")
+	}
+}
+
+func TestPeopleSync_DryRun(t *testing.T) {
+	mux := setup(t)
+	mux.HandleFunc("/products/1/people.json", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			t.Errorf("%v request in a dry run", r.Method)
+		}
+		fmt.Fprint(w, `[{"id": 1, "email": "joe@joestump.net", "first_name": "Joe"}, {"id": 2, "email": "krtecek"}]`)
+	})
+	mux.HandleFunc("/products/1/items.json", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("offset") != "" {
+			fmt.Fprint(w, `[]`)
+			return
+		}
+		fmt.Fprint(w, testingItemsJson)
+	})
+
+	path := filepath.Join(t.TempDir(), "team.csv")
+	if err := os.WriteFile(path, []byte("email,name\nondra@salsitasoft.com,Ondrej Kupka\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out, err := runCommand(t, "-format", "csv", "people", "sync", "-f", path, "-remove", "-dry-run")
+	if err != nil {
+		t.Fatalf("people sync failed: %v", err)
+	}
+	want := "product,email,change,result\n" +
+		"1,ondra@salsitasoft.com,invite,skipped\n" +
+		"1,joe@joestump.net,\"remove, open items: 1\",skipped\n" +
+		"1,krtecek,remove,\"skipped, authenticated user\"\n"
+	if out != want {
+		t.Errorf("people sync printed\n%v\nwant\n%v", out, want)
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/salsita/go-sprintly/manifest"
+)
+
+// manifestFlags registers the flags shared by items plan and items apply.
+func manifestFlags(flags *flag.FlagSet) (manifestPath, statePath *string) {
+	manifestPath = flags.String("f", "", "manifest file")
+	statePath = flags.String("state", "", "state file, defaults to <manifest>.state.json")
+	return
+}
+
+// loadPlan loads the manifest and the state and computes the plan.
+func loadPlan(e *env, manifestPath, statePath string) (*manifest.Plan, *manifest.State, string, error) {
+	if manifestPath == "" {
+		return nil, nil, "", fmt.Errorf("manifest file required")
+	}
+	if statePath == "" {
+		ext := filepath.Ext(manifestPath)
+		statePath = strings.TrimSuffix(manifestPath, ext) + ".state.json"
+	}
+	if err := e.requireProduct(); err != nil {
+		return nil, nil, "", err
+	}
+
+	m, err := manifest.Load(manifestPath)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	state, err := manifest.LoadState(statePath)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	plan, err := manifest.NewPlan(e.client, e.productId, m, state)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	return plan, state, statePath, nil
+}
+
+func itemsPlan(e *env, args []string) error {
+	flags := e.flags()
+	manifestPath, statePath := manifestFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	plan, _, _, err := loadPlan(e, *manifestPath, *statePath)
+	if err != nil {
+		return err
+	}
+	return plan.Write(e.stdout)
+}
+
+func itemsApply(e *env, args []string) error {
+	flags := e.flags()
+	manifestPath, statePath := manifestFlags(flags)
+	yes := flags.Bool("yes", false, "apply the plan without confirmation")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	plan, state, path, err := loadPlan(e, *manifestPath, *statePath)
+	if err != nil {
+		return err
+	}
+	if err := plan.Write(e.stdout); err != nil {
+		return err
+	}
+	if plan.Empty() {
+		return nil
+	}
+
+	if !*yes {
+		ok, err := e.confirm("Apply this plan?")
+		if err != nil || !ok {
+			return err
+		}
+	}
+
+	return plan.Apply(e.client, state, func(state *manifest.State) error {
+		return state.Save(path)
+	})
+}
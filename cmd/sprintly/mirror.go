@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/salsita/go-sprintly/mirror"
+)
+
+var mirrorCommands = map[string]*command{
+	"sync": {
+		usage: "sync -dir <directory>",
+		help:  "Mirror the product items, people and deploys into the given directory.",
+		run:   mirrorSync,
+	},
+}
+
+func mirrorSync(e *env, args []string) error {
+	flags := e.flags()
+	dir := flags.String("dir", "", "mirror directory")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *dir == "" {
+		flags.Usage()
+		return fmt.Errorf("mirror directory required")
+	}
+	if err := e.requireProduct(); err != nil {
+		return err
+	}
+
+	m := &mirror.Mirror{
+		Client:    e.client,
+		ProductId: e.productId,
+		Dir:       *dir,
+	}
+	if err := m.Sync(); err != nil {
+		return err
+	}
+
+	store, err := mirror.Open(*dir)
+	if err != nil {
+		return err
+	}
+	e.out.message("Mirrored %v items of product %v", len(store.Items(&mirror.Query{IncludeArchived: true})), e.productId)
+	return nil
+}
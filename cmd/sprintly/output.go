@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatCSV   = "csv"
+)
+
+// printer writes command results in the selected output format.
+type printer struct {
+	format string
+	w      io.Writer
+}
+
+func newPrinter(format string, w io.Writer) (*printer, error) {
+	switch format {
+	case formatTable, formatJSON, formatCSV:
+		return &printer{format, w}, nil
+	default:
+		return nil, fmt.Errorf("unknown output format: %v", format)
+	}
+}
+
+// print writes the given result.
+//
+// The value itself is encoded in the JSON format, the table format
+// and the CSV format print the given header and rows instead.
+func (p *printer) print(v interface{}, header []string, rows [][]string) error {
+	switch p.format {
+	case formatJSON:
+		enc := json.NewEncoder(p.w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+
+	case formatCSV:
+		w := csv.NewWriter(p.w)
+		w.Write(header)
+		w.WriteAll(rows)
+		return w.Error()
+
+	default:
+		w := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, strings.ToUpper(strings.Join(header, "\t")))
+		for _, row := range rows {
+			fmt.Fprintln(w, strings.Join(row, "\t"))
+		}
+		return w.Flush()
+	}
+}
+
+// message writes an informational line, in the table format only.
+func (p *printer) message(format string, args ...interface{}) {
+	if p.format == formatTable {
+		fmt.Fprintf(p.w, format+"\n", args...)
+	}
+}
+
+var itemHeader = []string{"number", "type", "status", "score", "assignee", "title"}
+
+func itemRow(item *sprintly.Item) []string {
+	return []string{
+		strconv.Itoa(item.Number),
+		item.Type,
+		string(item.Status),
+		string(item.Score),
+		userName(item.AssignedTo),
+		item.Title,
+	}
+}
+
+func (p *printer) printItems(items []sprintly.Item) error {
+	if items == nil {
+		items = []sprintly.Item{}
+	}
+	rows := make([][]string, len(items))
+	for i := range items {
+		rows[i] = itemRow(&items[i])
+	}
+	return p.print(items, itemHeader, rows)
+}
+
+func (p *printer) printItem(item *sprintly.Item) error {
+	return p.print(item, itemHeader, [][]string{itemRow(item)})
+}
+
+var userHeader = []string{"id", "email", "name", "admin", "revoked"}
+
+func (p *printer) printUsers(users []sprintly.User) error {
+	if users == nil {
+		users = []sprintly.User{}
+	}
+	rows := make([][]string, len(users))
+	for i, user := range users {
+		rows[i] = []string{
+			strconv.Itoa(user.Id),
+			user.Email,
+			userName(&user),
+			strconv.FormatBool(user.Admin),
+			strconv.FormatBool(user.Revoked),
+		}
+	}
+	return p.print(users, userHeader, rows)
+}
+
+var deployHeader = []string{"environment", "items"}
+
+func (p *printer) printDeploys(deploys []sprintly.Deploy) error {
+	if deploys == nil {
+		deploys = []sprintly.Deploy{}
+	}
+	rows := make([][]string, len(deploys))
+	for i, deploy := range deploys {
+		numbers := make([]string, len(deploy.Items))
+		for j, item := range deploy.Items {
+			numbers[j] = "#" + strconv.Itoa(item.Number)
+		}
+		rows[i] = []string{deploy.Environment, strings.Join(numbers, " ")}
+	}
+	return p.print(deploys, deployHeader, rows)
+}
+
+var driftHeader = []string{"missing_in", "number", "type", "status", "title"}
+
+func (p *printer) printDrift(drift *sprintly.EnvironmentDrift) error {
+	var rows [][]string
+	for _, item := range drift.Ahead {
+		rows = append(rows, []string{drift.To, strconv.Itoa(item.Number), item.Type, string(item.Status), item.Title})
+	}
+	for _, item := range drift.Behind {
+		rows = append(rows, []string{drift.From, strconv.Itoa(item.Number), item.Type, string(item.Status), item.Title})
+	}
+	if drift.InSync() {
+		p.message("Environments %v and %v are in sync", drift.From, drift.To)
+		if p.format == formatTable {
+			return nil
+		}
+	}
+	return p.print(drift, driftHeader, rows)
+}
+
+func userName(user *sprintly.User) string {
+	if user == nil {
+		return ""
+	}
+	return strings.TrimSpace(user.FirstName + " " + user.LastName)
+}
@@ -0,0 +1,298 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/salsita/go-sprintly/bulk"
+	"github.com/salsita/go-sprintly/offboard"
+	"github.com/salsita/go-sprintly/roster"
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+var peopleCommands = map[string]*command{
+	"list": {
+		usage: "list",
+		help:  "List the product members.",
+		run:   peopleList,
+	},
+	"invite": {
+		usage: "invite -email <email> [-first-name name] [-last-name name] [-admin]",
+		help:  "Invite a person to the product.",
+		run:   peopleInvite,
+	},
+	"remove": {
+		usage: "remove <user ID>",
+		help:  "Remove a person from the product.",
+		run:   peopleRemove,
+	},
+	"offboard": {
+		usage: "offboard [-reassign-to id] [-products p1,p2] [-dry-run] [-yes] <user ID>",
+		help:  "Reassign or unassign the open items of a person, then remove them from the products.",
+		run:   peopleOffboard,
+	},
+	"sync": {
+		usage: "sync -f <roster.yaml|roster.csv> [-products p1,p2] [-remove] [-reassign-to id] [-dry-run] [-yes]",
+		help:  "Invite the people listed in the roster and report the differences, optionally offboarding the others.",
+		run:   peopleSync,
+	},
+}
+
+func peopleList(e *env, args []string) error {
+	flags := e.flags()
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := e.requireProduct(); err != nil {
+		return err
+	}
+
+	users, _, err := e.client.People.List(e.productId)
+	if err != nil {
+		return err
+	}
+	return e.out.printUsers(users)
+}
+
+func peopleInvite(e *env, args []string) error {
+	var invitation sprintly.Invitation
+	flags := e.flags()
+	flags.StringVar(&invitation.Email, "email", "", "email address")
+	flags.StringVar(&invitation.FirstName, "first-name", "", "first name")
+	flags.StringVar(&invitation.LastName, "last-name", "", "last name")
+	flags.BoolVar(&invitation.Admin, "admin", false, "make the person a product admin")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if invitation.Email == "" {
+		flags.Usage()
+		return fmt.Errorf("email required")
+	}
+	if err := e.requireProduct(); err != nil {
+		return err
+	}
+
+	if _, err := e.client.People.Invite(e.productId, &invitation); err != nil {
+		return err
+	}
+	e.out.message("Invited %v", invitation.Email)
+	return nil
+}
+
+func peopleRemove(e *env, args []string) error {
+	flags := e.flags()
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("exactly one user ID expected")
+	}
+	userId, err := strconv.Atoi(flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("invalid user ID: %v", flags.Arg(0))
+	}
+	if err := e.requireProduct(); err != nil {
+		return err
+	}
+
+	if _, err := e.client.People.Remove(e.productId, userId); err != nil {
+		return err
+	}
+	e.out.message("Removed user %v", userId)
+	return nil
+}
+
+func peopleOffboard(e *env, args []string) error {
+	var (
+		opts     offboard.Options
+		products listFlag
+	)
+	flags := e.flags()
+	flags.IntVar(&opts.ReassignTo, "reassign-to", 0, "user ID taking over the items, the items are unassigned when not set")
+	flags.Var(&products, "products", "comma-separated product IDs, the current product by default")
+	flags.IntVar(&opts.Bulk.Concurrency, "concurrency", bulk.DefaultConcurrency, "number of items updated in parallel")
+	flags.BoolVar(&opts.DryRun, "dry-run", false, "only report the items that would be changed")
+	yes := flags.Bool("yes", false, "do not ask for confirmation")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("exactly one user ID expected")
+	}
+	userId, err := strconv.Atoi(flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("invalid user ID: %v", flags.Arg(0))
+	}
+
+	productIds, err := e.productIds(products)
+	if err != nil {
+		return err
+	}
+
+	if !opts.DryRun && !*yes {
+		verb := "Reassign"
+		if opts.ReassignTo == 0 {
+			verb = "Unassign"
+		}
+		q := fmt.Sprintf("%v the open items of user %v and remove them from products %v?",
+			verb, userId, strings.Trim(fmt.Sprint(productIds), "[]"))
+		ok, err := e.confirm(q)
+		if err != nil || !ok {
+			return err
+		}
+	}
+
+	report, err := offboard.Run(e.client, userId, productIds, &opts)
+	if report != nil {
+		if perr := printOffboard(e, report); err == nil {
+			err = perr
+		}
+	}
+	return err
+}
+
+func printOffboard(e *env, report *offboard.Report) error {
+	header := []string{"product", "number", "result", "removed"}
+	var rows [][]string
+	for _, product := range report.Products {
+		productId := strconv.Itoa(product.ProductId)
+		removed := strconv.FormatBool(product.Removed)
+		if product.Err != nil {
+			rows = append(rows, []string{productId, "", "failed: " + product.Err.Error(), removed})
+		}
+		if product.Items == nil {
+			continue
+		}
+		for _, outcome := range product.Items.Changed() {
+			result := "reassigned"
+			switch {
+			case report.DryRun:
+				result = "would change"
+			case outcome.Changes.AssignedTo.New == 0:
+				result = "unassigned"
+			}
+			rows = append(rows, []string{productId, strconv.Itoa(outcome.Number), result, removed})
+		}
+		for _, outcome := range product.Items.Failed() {
+			rows = append(rows, []string{productId, strconv.Itoa(outcome.Number), "failed: " + outcome.Err.Error(), removed})
+		}
+	}
+	return e.out.print(report, header, rows)
+}
+
+// productIds parses the product IDs passed in, defaulting to the current product.
+func (e *env) productIds(products []string) ([]int, error) {
+	var productIds []int
+	for _, product := range products {
+		productId, err := strconv.Atoi(product)
+		if err != nil {
+			return nil, fmt.Errorf("invalid product ID: %v", product)
+		}
+		productIds = append(productIds, productId)
+	}
+	if len(productIds) == 0 {
+		if err := e.requireProduct(); err != nil {
+			return nil, err
+		}
+		productIds = []int{e.productId}
+	}
+	return productIds, nil
+}
+
+func peopleSync(e *env, args []string) error {
+	var (
+		opts     roster.Options
+		products listFlag
+	)
+	flags := e.flags()
+	path := flags.String("f", "", "roster file, YAML or CSV")
+	flags.Var(&products, "products", "comma-separated product IDs, the current product by default")
+	flags.BoolVar(&opts.Remove, "remove", false, "offboard the people not listed in the roster, except for yourself")
+	flags.IntVar(&opts.ReassignTo, "reassign-to", 0, "user ID taking over the open items of the people removed, the items are unassigned when not set")
+	flags.BoolVar(&opts.DryRun, "dry-run", false, "only report the differences")
+	yes := flags.Bool("yes", false, "do not ask for confirmation")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *path == "" {
+		flags.Usage()
+		return fmt.Errorf("roster file required")
+	}
+	productIds, err := e.productIds(products)
+	if err != nil {
+		return err
+	}
+
+	r, err := roster.Load(*path)
+	if err != nil {
+		return err
+	}
+
+	if opts.Remove && !opts.DryRun && !*yes {
+		q := fmt.Sprintf("Remove the people not listed in %v from products %v and reassign or unassign their open items?",
+			*path, strings.Trim(fmt.Sprint(productIds), "[]"))
+		ok, err := e.confirm(q)
+		if err != nil || !ok {
+			return err
+		}
+	}
+
+	report, err := roster.Sync(e.client, r, productIds, &opts)
+	if report != nil {
+		if perr := printSync(e, report); err == nil {
+			err = perr
+		}
+	}
+	return err
+}
+
+func printSync(e *env, report *roster.Report) error {
+	header := []string{"product", "email", "change", "result"}
+	var rows [][]string
+	for _, product := range report.Products {
+		productId := strconv.Itoa(product.ProductId)
+		if product.Err != nil {
+			rows = append(rows, []string{productId, "", "", "failed: " + product.Err.Error()})
+		}
+		for _, change := range product.Changes {
+			var result string
+			switch {
+			case change.Err != nil:
+				result = "failed: " + change.Err.Error()
+			case change.Applied:
+				result = "done"
+			case change.Self:
+				result = "skipped, authenticated user"
+			case change.Kind == roster.ChangeInvite || change.Kind == roster.ChangeRemove:
+				result = "skipped"
+			default:
+				result = "not supported by the API"
+			}
+			rows = append(rows, []string{productId, change.Email, describeChange(change), result})
+		}
+	}
+	return e.out.print(report, header, rows)
+}
+
+// describeChange describes the roster change for the user.
+func describeChange(change *roster.Change) string {
+	switch change.Kind {
+	case roster.ChangeInvite:
+		if change.User != nil {
+			return "invite, access revoked"
+		}
+		return "invite"
+	case roster.ChangeAdmin:
+		return fmt.Sprintf("admin %v, roster says %v", change.User.Admin, change.Person.Admin)
+	case roster.ChangeName:
+		return fmt.Sprintf("name %q, roster says %q", userName(change.User), change.Person.Name)
+	case roster.ChangeRemove:
+		if change.Offboard != nil && change.Offboard.Items != nil {
+			return fmt.Sprintf("remove, open items: %v", len(change.Offboard.Items.Changed()))
+		}
+	}
+	return string(change.Kind)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/salsita/go-sprintly/analytics"
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+var groupFuncs = map[string]analytics.GroupFunc{
+	"type":     analytics.ByType,
+	"tag":      analytics.ByTag,
+	"assignee": analytics.ByAssignee,
+}
+
+type groupStats struct {
+	Group    string               `json:"group"`
+	Flow     *analytics.FlowStats `json:"flow"`
+	Velocity []analytics.Week     `json:"velocity"`
+}
+
+func itemsStats(e *env, args []string) error {
+	flags := e.flags()
+	by := flags.String("by", "", "group by: type, tag or assignee")
+	weeks := flags.Int("weeks", 6, "number of recent weeks to average the velocity over")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	group := func(*sprintly.Item) []string { return []string{"all"} }
+	if *by != "" {
+		var ok bool
+		if group, ok = groupFuncs[*by]; !ok {
+			return fmt.Errorf("unknown grouping: %v", *by)
+		}
+	}
+	if err := e.requireProduct(); err != nil {
+		return err
+	}
+
+	items, _, err := e.client.Items.ListAll(e.productId, &sprintly.ItemListArgs{
+		Status:   sprintly.ItemStatuses,
+		Children: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	flow := analytics.FlowBy(slices.Values(items), group)
+	velocity := analytics.VelocityBy(slices.Values(items), group, time.Local)
+
+	var (
+		stats []*groupStats
+		rows  [][]string
+	)
+	for _, name := range slices.Sorted(maps.Keys(flow)) {
+		recent := velocity[name]
+		if len(recent) > *weeks {
+			recent = recent[len(recent)-*weeks:]
+		}
+		stats = append(stats, &groupStats{name, flow[name], recent})
+
+		var points int
+		for _, w := range recent {
+			points += w.Points
+		}
+		avg := "-"
+		if len(recent) != 0 {
+			avg = strconv.FormatFloat(float64(points)/float64(len(recent)), 'f', 1, 64)
+		}
+
+		f := flow[name]
+		rows = append(rows, []string{
+			name,
+			strconv.Itoa(f.Items),
+			days(f.LeadTime.P50),
+			days(f.LeadTime.P85),
+			days(f.CycleTime.P50),
+			days(f.CycleTime.P85),
+			avg,
+		})
+	}
+
+	header := []string{"group", "items", "lead_p50", "lead_p85", "cycle_p50", "cycle_p85", "points_per_week"}
+	return e.out.print(stats, header, rows)
+}
+
+// days formats the duration in days.
+func days(d time.Duration) string {
+	if d == 0 {
+		return "-"
+	}
+	return strconv.FormatFloat(d.Hours()/24, 'f', 1, 64) + "d"
+}
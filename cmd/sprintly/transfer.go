@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/salsita/go-sprintly/transfer"
+)
+
+func itemsMove(e *env, args []string) error {
+	return itemsTransfer(e, args, true)
+}
+
+func itemsCopy(e *env, args []string) error {
+	return itemsTransfer(e, args, false)
+}
+
+// itemsTransfer implements both items move and items copy.
+func itemsTransfer(e *env, args []string, move bool) error {
+	var opts transfer.Options
+	flags := e.flags()
+	to := flags.Int("to", 0, "target product ID")
+	flags.BoolVar(&opts.Comments, "comments", false, "copy the comments")
+	flags.BoolVar(&opts.Link, "link", move, "comment on the items with a link to their copies")
+	yes := flags.Bool("yes", false, "do not ask for confirmation")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	number, err := itemNumberArg(flags)
+	if err != nil {
+		return err
+	}
+	if *to == 0 {
+		flags.Usage()
+		return fmt.Errorf("target product required")
+	}
+	if err := e.requireProduct(); err != nil {
+		return err
+	}
+
+	var res *transfer.Result
+	if move {
+		if !*yes {
+			q := fmt.Sprintf("Move #%v and its children to product %v, archiving the originals?", number, *to)
+			ok, err := e.confirm(q)
+			if err != nil || !ok {
+				return err
+			}
+		}
+		res, err = transfer.Move(e.client, e.productId, number, *to, &opts)
+	} else {
+		res, err = transfer.Copy(e.client, e.productId, number, *to, &opts)
+	}
+
+	// Print what was done even when the transfer failed midway.
+	if res != nil {
+		for _, email := range res.Unassigned {
+			fmt.Fprintf(e.stderr, "%v is not a member of product %v, the items are left unassigned\n", email, *to)
+		}
+		for _, number := range res.Unlinked {
+			fmt.Fprintf(e.stderr, "#%v was copied to product %v, but not linked to its parent\n", number, *to)
+		}
+		if perr := printTransfer(e, res); err == nil {
+			err = perr
+		}
+	}
+	return err
+}
+
+func printTransfer(e *env, res *transfer.Result) error {
+	originals := make(map[int]int, len(res.Items))
+	for original, copied := range res.Items {
+		originals[copied] = original
+	}
+
+	header := []string{"original", "number", "type", "title"}
+	var rows [][]string
+	for _, item := range res.Created {
+		rows = append(rows, []string{
+			strconv.Itoa(originals[item.Number]),
+			strconv.Itoa(item.Number),
+			item.Type,
+			item.Title,
+		})
+	}
+	return e.out.print(res, header, rows)
+}
@@ -0,0 +1,91 @@
+package commits
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// CI describes the build being run by a continuous integration service.
+type CI struct {
+	// Provider names the CI service, empty when not detected.
+	Provider string
+
+	// Environment is the deploy environment, when the service knows it.
+	Environment string
+
+	// From is the last commit built before, To is the commit being built.
+	From string
+	To   string
+}
+
+// Range returns the revision range of the build, empty when the previous commit is not known.
+// Listing the whole history up to To instead would report every item ever referenced.
+func (ci *CI) Range() string {
+	if ci.From == "" || ci.To == "" {
+		return ""
+	}
+	return ci.From + ".." + ci.To
+}
+
+var compareRegexp = regexp.MustCompile(`/compare/([0-9a-fA-F]+)\.{2,3}([0-9a-fA-F]+)$`)
+
+// DetectCI reads the environment variables set by the common CI services
+// using the given lookup function, usually os.Getenv.
+//
+// SPRINTLY_ENVIRONMENT and SPRINTLY_REVISION_RANGE take precedence
+// over whatever the service sets, so that any pipeline can be configured.
+// An error is returned when SPRINTLY_REVISION_RANGE is not a from..to range.
+func DetectCI(getenv func(string) string) (*CI, error) {
+	ci := &CI{}
+
+	switch {
+	case getenv("GITLAB_CI") != "":
+		ci.Provider = "gitlab"
+		ci.Environment = getenv("CI_ENVIRONMENT_NAME")
+		ci.From = getenv("CI_COMMIT_BEFORE_SHA")
+		ci.To = getenv("CI_COMMIT_SHA")
+
+	case getenv("GITHUB_ACTIONS") != "":
+		// The previous commit is only available in the event payload.
+		ci.Provider = "github"
+		ci.To = getenv("GITHUB_SHA")
+
+	case getenv("CIRCLECI") != "":
+		ci.Provider = "circleci"
+		ci.To = getenv("CIRCLE_SHA1")
+		if m := compareRegexp.FindStringSubmatch(getenv("CIRCLE_COMPARE_URL")); m != nil {
+			ci.From = m[1]
+		}
+
+	case getenv("TRAVIS") != "":
+		ci.Provider = "travis"
+		ci.To = getenv("TRAVIS_COMMIT")
+		if from, _, ok := strings.Cut(getenv("TRAVIS_COMMIT_RANGE"), "..."); ok {
+			ci.From = from
+		}
+
+	case getenv("JENKINS_URL") != "":
+		ci.Provider = "jenkins"
+		ci.From = getenv("GIT_PREVIOUS_SUCCESSFUL_COMMIT")
+		ci.To = getenv("GIT_COMMIT")
+	}
+
+	// New branches are reported as coming from the zero commit.
+	if strings.Trim(ci.From, "0") == "" {
+		ci.From = ""
+	}
+
+	if env := getenv("SPRINTLY_ENVIRONMENT"); env != "" {
+		ci.Environment = env
+	}
+	if revisionRange := getenv("SPRINTLY_REVISION_RANGE"); revisionRange != "" {
+		from, to, ok := strings.Cut(revisionRange, "..")
+		to = strings.TrimPrefix(to, ".")
+		if !ok || from == "" || to == "" {
+			return nil, fmt.Errorf("commits: SPRINTLY_REVISION_RANGE %q is not a from..to range", revisionRange)
+		}
+		ci.From, ci.To = from, to
+	}
+	return ci, nil
+}
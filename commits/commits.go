@@ -0,0 +1,216 @@
+// Package commits can be used to find Sprintly item references in commit messages.
+//
+// A reference consists of a keyword followed by a list of items, e.g.
+//
+//	refs #123
+//	fixes #45, #46
+//	closes sprint.ly/product/1/item/12
+//
+// The keyword decides what should happen to the items referenced.
+package commits
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+// Action represents what a commit is supposed to do with the items it references.
+type Action string
+
+const (
+	ActionReference Action = "reference"
+	ActionStart     Action = "start"
+	ActionComplete  Action = "complete"
+)
+
+// Status returns the status the item should be moved to as a result of the action.
+// An empty status is returned for ActionReference.
+func (action Action) Status() sprintly.ItemStatus {
+	switch action {
+	case ActionStart:
+		return sprintly.ItemStatusInProgress
+	case ActionComplete:
+		return sprintly.ItemStatusCompleted
+	default:
+		return ""
+	}
+}
+
+// weight is used to pick the strongest action when an item is referenced repeatedly.
+func (action Action) weight() int {
+	switch action {
+	case ActionStart:
+		return 1
+	case ActionComplete:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// Reference represents a single item referenced in a commit message.
+type Reference struct {
+	// ProductId is only set when the item is referenced using its URL.
+	ProductId int
+
+	ItemNumber int
+	Action     Action
+
+	// Keyword is the keyword as written in the commit message.
+	Keyword string
+}
+
+// Parser finds item references in commit messages.
+type Parser struct {
+	// Keywords maps the keywords to the actions they stand for.
+	// The keywords are matched case-insensitively.
+	Keywords map[string]Action
+}
+
+// DefaultParser is the parser used by Parse.
+var DefaultParser = &Parser{
+	Keywords: map[string]Action{
+		"re":         ActionReference,
+		"ref":        ActionReference,
+		"refs":       ActionReference,
+		"references": ActionReference,
+		"see":        ActionReference,
+
+		"start":      ActionStart,
+		"starts":     ActionStart,
+		"started":    ActionStart,
+		"starting":   ActionStart,
+		"working on": ActionStart,
+
+		"close":     ActionComplete,
+		"closes":    ActionComplete,
+		"closed":    ActionComplete,
+		"fix":       ActionComplete,
+		"fixes":     ActionComplete,
+		"fixed":     ActionComplete,
+		"resolve":   ActionComplete,
+		"resolves":  ActionComplete,
+		"resolved":  ActionComplete,
+		"complete":  ActionComplete,
+		"completes": ActionComplete,
+		"completed": ActionComplete,
+	},
+}
+
+// Parse parses the given commit message using DefaultParser.
+func Parse(message string) []Reference {
+	return DefaultParser.Parse(message)
+}
+
+const (
+	refPattern = `(?:#\d+|(?:https?://)?(?:www\.)?sprint\.ly/[^\s,;]+)`
+	sepPattern = `(?:\s*(?:,|;|&|\band\b)\s*|\s+)`
+)
+
+var refRegexp = regexp.MustCompile(refPattern)
+
+// Parse returns the items referenced in the given commit message.
+//
+// Every item is returned only once, in the order of appearance, with the
+// strongest action it was referenced with. Invalid item URLs are skipped.
+func (parser *Parser) Parse(message string) []Reference {
+	var (
+		actions  = make(map[string]Action, len(parser.Keywords))
+		keywords = make([]string, 0, len(parser.Keywords))
+	)
+	for keyword, action := range parser.Keywords {
+		keyword = strings.ToLower(keyword)
+		actions[keyword] = action
+		keywords = append(keywords, regexp.QuoteMeta(keyword))
+	}
+	if len(keywords) == 0 {
+		return nil
+	}
+	// Prefer the longest keyword, regexp alternation is leftmost-first.
+	sort.Slice(keywords, func(i, j int) bool {
+		return len(keywords[i]) > len(keywords[j])
+	})
+
+	re := regexp.MustCompile(`(?i)\b(` + strings.Join(keywords, "|") + `)\b:?\s*(` +
+		refPattern + `(?:` + sepPattern + refPattern + `)*)`)
+
+	var (
+		refs  []Reference
+		index = make(map[[2]int]int)
+	)
+	for _, match := range re.FindAllStringSubmatch(message, -1) {
+		keyword := match[1]
+		action := actions[strings.ToLower(keyword)]
+
+		for _, token := range refRegexp.FindAllString(match[2], -1) {
+			ref := Reference{
+				Action:  action,
+				Keyword: keyword,
+			}
+			if strings.HasPrefix(token, "#") {
+				ref.ItemNumber, _ = strconv.Atoi(token[1:])
+			} else {
+				productId, itemNumber, err := ResolveURL(token)
+				if err != nil {
+					continue
+				}
+				ref.ProductId, ref.ItemNumber = productId, itemNumber
+			}
+
+			key := [2]int{ref.ProductId, ref.ItemNumber}
+			if i, ok := index[key]; ok {
+				if ref.Action.weight() > refs[i].Action.weight() {
+					refs[i] = ref
+				}
+				continue
+			}
+			index[key] = len(refs)
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+// ResolveURL returns the product ID and the item number for the given Sprintly item URL.
+//
+// Both the full item URLs (sprint.ly/product/1/item/12) and the short URLs
+// (sprint.ly/i/1/12) are accepted, the scheme being optional.
+func ResolveURL(rawurl string) (productId, itemNumber int, err error) {
+	rawurl = strings.TrimRight(rawurl, ".)")
+	if !strings.Contains(rawurl, "://") {
+		rawurl = "https://" + rawurl
+	}
+
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return 0, 0, err
+	}
+	if host := strings.ToLower(u.Host); host != "sprint.ly" && host != "www.sprint.ly" {
+		return 0, 0, errors.New("commits.ResolveURL: not a Sprintly URL")
+	}
+
+	var numbers []string
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	switch {
+	case len(parts) == 4 && parts[0] == "product" && parts[2] == "item":
+		numbers = []string{parts[1], parts[3]}
+	case len(parts) == 3 && parts[0] == "i":
+		numbers = parts[1:]
+	default:
+		return 0, 0, errors.New("commits.ResolveURL: not an item URL")
+	}
+
+	if productId, err = strconv.Atoi(numbers[0]); err != nil {
+		return 0, 0, errors.New("commits.ResolveURL: invalid product ID")
+	}
+	if itemNumber, err = strconv.Atoi(numbers[1]); err != nil {
+		return 0, 0, errors.New("commits.ResolveURL: invalid item number")
+	}
+	return productId, itemNumber, nil
+}
@@ -0,0 +1,109 @@
+package commits
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		message string
+		want    []Reference
+	}{
+		{
+			"Add invoice export\n\nrefs #123",
+			[]Reference{
+				{ItemNumber: 123, Action: ActionReference, Keyword: "refs"},
+			},
+		},
+		{
+			"Fixes #45, #46 and #47",
+			[]Reference{
+				{ItemNumber: 45, Action: ActionComplete, Keyword: "Fixes"},
+				{ItemNumber: 46, Action: ActionComplete, Keyword: "Fixes"},
+				{ItemNumber: 47, Action: ActionComplete, Keyword: "Fixes"},
+			},
+		},
+		{
+			"closes sprint.ly/product/1/item/12, see https://sprint.ly/i/2/7",
+			[]Reference{
+				{ProductId: 1, ItemNumber: 12, Action: ActionComplete, Keyword: "closes"},
+				{ProductId: 2, ItemNumber: 7, Action: ActionReference, Keyword: "see"},
+			},
+		},
+		{
+			"Working on #5; refs #6. Fixed: #5",
+			[]Reference{
+				{ItemNumber: 5, Action: ActionComplete, Keyword: "Fixed"},
+				{ItemNumber: 6, Action: ActionReference, Keyword: "refs"},
+			},
+		},
+		{
+			"Prefix #5 is not a reference, nor is prefixes #6",
+			nil,
+		},
+	}
+
+	for _, c := range cases {
+		got := Parse(c.message)
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("Parse(%q)\n got = %+v\nwant = %+v", c.message, got, c.want)
+		}
+	}
+}
+
+func TestParser_Parse(t *testing.T) {
+	parser := &Parser{
+		Keywords: map[string]Action{
+			"Behebt": ActionComplete,
+		},
+	}
+
+	got := parser.Parse("behebt #9, fixes #10")
+	want := []Reference{
+		{ItemNumber: 9, Action: ActionComplete, Keyword: "behebt"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parser.Parse\n got = %+v\nwant = %+v", got, want)
+	}
+}
+
+func TestAction_Status(t *testing.T) {
+	if s := ActionStart.Status(); s != "in-progress" {
+		t.Errorf("ActionStart.Status() = %v", s)
+	}
+	if s := ActionComplete.Status(); s != "completed" {
+		t.Errorf("ActionComplete.Status() = %v", s)
+	}
+	if s := ActionReference.Status(); s != "" {
+		t.Errorf("ActionReference.Status() = %v", s)
+	}
+}
+
+func TestResolveURL(t *testing.T) {
+	valid := map[string][2]int{
+		"sprint.ly/product/1/item/12":          {1, 12},
+		"https://sprint.ly/product/1/item/12/": {1, 12},
+		"http://www.sprint.ly/i/3/188":         {3, 188},
+	}
+	for rawurl, want := range valid {
+		productId, itemNumber, err := ResolveURL(rawurl)
+		if err != nil {
+			t.Errorf("ResolveURL(%q) failed: %v", rawurl, err)
+			continue
+		}
+		if got := [2]int{productId, itemNumber}; got != want {
+			t.Errorf("ResolveURL(%q) = %v, want %v", rawurl, got, want)
+		}
+	}
+
+	for _, rawurl := range []string{
+		"https://example.com/product/1/item/12",
+		"sprint.ly/product/1",
+		"sprint.ly/product/x/item/12",
+	} {
+		if _, _, err := ResolveURL(rawurl); err == nil {
+			t.Errorf("ResolveURL(%q) should have failed", rawurl)
+		}
+	}
+}
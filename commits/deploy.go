@@ -0,0 +1,58 @@
+package commits
+
+import (
+	"fmt"
+
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+// DeployRecorder records deploys of the items referenced in the git history.
+type DeployRecorder struct {
+	Client    *sprintly.Client
+	ProductId int
+
+	// Parser is used to find the references, DefaultParser when nil.
+	Parser *Parser
+
+	// Dir is the git repository directory, the working directory when empty.
+	Dir string
+}
+
+// Items returns the numbers of the items referenced by the commits in the revision range.
+func (rec *DeployRecorder) Items(revisionRange string) ([]int, error) {
+	commits, err := Log(rec.Dir, revisionRange)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := rec.Parser
+	if parser == nil {
+		parser = DefaultParser
+	}
+	return parser.ItemNumbers(rec.ProductId, commits), nil
+}
+
+// Record creates a deploy of the items referenced by the commits in the revision range
+// using Deploys.Create. The deploy is nil when no items are referenced.
+func (rec *DeployRecorder) Record(environment, revisionRange string) (*sprintly.Deploy, []int, error) {
+	if environment == "" {
+		return nil, nil, fmt.Errorf("commits: environment required")
+	}
+
+	numbers, err := rec.Items(revisionRange)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(numbers) == 0 {
+		return nil, nil, nil
+	}
+
+	deploy, _, err := rec.Client.Deploys.Create(rec.ProductId, &sprintly.DeployCreateArgs{
+		Environment: environment,
+		ItemNumbers: numbers,
+	})
+	if err != nil {
+		return nil, numbers, err
+	}
+	return deploy, numbers, nil
+}
@@ -0,0 +1,76 @@
+package commits
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Commit represents a single commit read from a git repository.
+type Commit struct {
+	Hash    string
+	Message string
+}
+
+const (
+	fieldSep  = "\x1f"
+	recordSep = "\x1e"
+)
+
+// Log returns the commits in the given revision range, e.g. v1.0..v1.1,
+// running git log in the given directory, the working directory when empty.
+// The commits are returned newest first, as git log lists them.
+// The range is never taken for an option, git 2.24 or newer is required.
+func Log(dir, revisionRange string) ([]Commit, error) {
+	if revisionRange == "" {
+		return nil, fmt.Errorf("commits: revision range required")
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", "log", "--format=%H"+fieldSep+"%B"+recordSep, "--end-of-options", revisionRange, "--")
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("commits: git log %v: %v", revisionRange, msg)
+		}
+		return nil, fmt.Errorf("commits: git log %v: %v", revisionRange, err)
+	}
+
+	var commits []Commit
+	for _, record := range strings.Split(stdout.String(), recordSep) {
+		record = strings.TrimLeft(record, "\n")
+		if record == "" {
+			continue
+		}
+		hash, message, ok := strings.Cut(record, fieldSep)
+		if !ok {
+			return nil, fmt.Errorf("commits: unexpected git log output: %q", record)
+		}
+		commits = append(commits, Commit{hash, strings.TrimSpace(message)})
+	}
+	return commits, nil
+}
+
+// ItemNumbers returns the numbers of the items referenced by the given commits,
+// oldest commit first. References to the items of other products are skipped.
+func (parser *Parser) ItemNumbers(productId int, commits []Commit) []int {
+	var (
+		numbers []int
+		seen    = make(map[int]bool)
+	)
+	for i := len(commits) - 1; i >= 0; i-- {
+		for _, ref := range parser.Parse(commits[i].Message) {
+			if ref.ProductId != 0 && ref.ProductId != productId {
+				continue
+			}
+			if !seen[ref.ItemNumber] {
+				seen[ref.ItemNumber] = true
+				numbers = append(numbers, ref.ItemNumber)
+			}
+		}
+	}
+	return numbers
+}
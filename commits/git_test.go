@@ -0,0 +1,169 @@
+package commits
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+// gitRepo creates a repository with the given commit messages, returning the commit hashes.
+func gitRepo(t *testing.T, messages ...string) (string, []string) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir := t.TempDir()
+	git := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(cmd.Environ(),
+			"GIT_AUTHOR_NAME=Joe", "GIT_AUTHOR_EMAIL=joe@joestump.net",
+			"GIT_COMMITTER_NAME=Joe", "GIT_COMMITTER_EMAIL=joe@joestump.net",
+			"GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_NOSYSTEM=1",
+		)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", strings.Join(args, " "), err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+
+	git("init", "-q")
+	var hashes []string
+	for _, message := range messages {
+		git("commit", "-q", "--allow-empty", "-m", message)
+		hashes = append(hashes, git("rev-parse", "HEAD"))
+	}
+	return dir, hashes
+}
+
+func TestLog(t *testing.T) {
+	dir, hashes := gitRepo(t, "Initial commit", "Add the parser\n\nrefs #12", "Fix the parser, fixes #13 and #12")
+
+	commits, err := Log(dir, hashes[0]+".."+hashes[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Commit{
+		{hashes[2], "Fix the parser, fixes #13 and #12"},
+		{hashes[1], "Add the parser\n\nrefs #12"},
+	}
+	if fmt.Sprint(commits) != fmt.Sprint(want) {
+		t.Errorf("Log = %q, want %q", commits, want)
+	}
+
+	if _, err := Log(dir, "nonexistent..HEAD"); err == nil {
+		t.Error("expected an error for an invalid revision")
+	}
+
+	// A range looking like an option is not passed to git as one.
+	out := filepath.Join(t.TempDir(), "out")
+	if _, err := Log(dir, "--output="+out); err == nil {
+		t.Error("expected an error for an option")
+	}
+	if _, err := os.Stat(out); !os.IsNotExist(err) {
+		t.Errorf("the range was taken for an option: %v", err)
+	}
+}
+
+func TestParser_ItemNumbers(t *testing.T) {
+	commits := []Commit{
+		{"c", "Closes #3, see https://sprint.ly/product/2/item/9"},
+		{"b", "Fixes #1 and https://sprint.ly/product/1/item/2"},
+		{"a", "Refs #3"},
+	}
+	if got := DefaultParser.ItemNumbers(1, commits); fmt.Sprint(got) != "[3 1 2]" {
+		t.Errorf("ItemNumbers = %v, want [3 1 2]", got)
+	}
+}
+
+func TestDeployRecorder_Record(t *testing.T) {
+	dir, hashes := gitRepo(t, "Initial commit, refs #1", "Start #5", "Merge the API work, closes #6")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/products/1/deploys.json", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if env := r.PostForm.Get("environment"); env != "production" {
+			t.Errorf("environment = %q", env)
+		}
+		if numbers := r.PostForm.Get("numbers"); numbers != "5,6" {
+			t.Errorf("numbers = %q", numbers)
+		}
+		fmt.Fprint(w, `{"environment": "production", "items": [{"number": 5}, {"number": 6}]}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := sprintly.NewClient("krtecek", "secret")
+	client.SetBaseURL(server.URL)
+
+	rec := &DeployRecorder{Client: client, ProductId: 1, Dir: dir}
+	deploy, numbers, err := rec.Record("production", hashes[0]+".."+hashes[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deploy == nil || len(deploy.Items) != 2 || fmt.Sprint(numbers) != "[5 6]" {
+		t.Errorf("unexpected deploy %+v of %v", deploy, numbers)
+	}
+
+	// Nothing referenced, nothing deployed.
+	deploy, _, err = rec.Record("production", hashes[2]+".."+hashes[2])
+	if err != nil || deploy != nil {
+		t.Errorf("unexpected deploy %+v, %v", deploy, err)
+	}
+}
+
+func TestDetectCI(t *testing.T) {
+	tests := []struct {
+		env  map[string]string
+		want CI
+	}{
+		{
+			map[string]string{"GITLAB_CI": "true", "CI_ENVIRONMENT_NAME": "staging", "CI_COMMIT_BEFORE_SHA": "aaa", "CI_COMMIT_SHA": "bbb"},
+			CI{"gitlab", "staging", "aaa", "bbb"},
+		},
+		{
+			map[string]string{"GITLAB_CI": "true", "CI_COMMIT_BEFORE_SHA": "0000000000", "CI_COMMIT_SHA": "bbb"},
+			CI{"gitlab", "", "", "bbb"},
+		},
+		{
+			map[string]string{"TRAVIS": "true", "TRAVIS_COMMIT_RANGE": "aaa...bbb", "TRAVIS_COMMIT": "bbb"},
+			CI{"travis", "", "aaa", "bbb"},
+		},
+		{
+			map[string]string{"CIRCLECI": "true", "CIRCLE_SHA1": "bbb", "CIRCLE_COMPARE_URL": "https://github.com/salsita/go-sprintly/compare/aaa...bbb"},
+			CI{"circleci", "", "aaa", "bbb"},
+		},
+		{
+			map[string]string{"GITHUB_ACTIONS": "true", "GITHUB_SHA": "bbb", "SPRINTLY_ENVIRONMENT": "production", "SPRINTLY_REVISION_RANGE": "v1.0..v1.1"},
+			CI{"github", "production", "v1.0", "v1.1"},
+		},
+	}
+	for _, test := range tests {
+		ci, err := DetectCI(func(name string) string { return test.env[name] })
+		if err != nil || *ci != test.want {
+			t.Errorf("DetectCI(%v) = %+v, %v, want %+v", test.env, ci, err, test.want)
+		}
+	}
+	for _, revisionRange := range []string{"v1.1", "..v1.1", "v1.0.."} {
+		env := map[string]string{"SPRINTLY_REVISION_RANGE": revisionRange}
+		if _, err := DetectCI(func(name string) string { return env[name] }); err == nil {
+			t.Errorf("DetectCI accepted the revision range %q", revisionRange)
+		}
+	}
+
+	ci := &CI{From: "aaa", To: "bbb"}
+	if got := ci.Range(); got != "aaa..bbb" {
+		t.Errorf("Range = %q", got)
+	}
+	if got := (&CI{To: "bbb"}).Range(); got != "" {
+		t.Errorf("Range = %q, want empty", got)
+	}
+}
@@ -0,0 +1,284 @@
+package editor
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+const headerDelimiter = "---"
+
+// Document is the editable representation of a Sprintly item.
+//
+// It is serialized as a Markdown document with YAML front matter, the front
+// matter holding the item fields and the body holding the item description:
+//
+//	---
+//	type: task
+//	title: Don't let un-scored items out of the backlog.
+//	status: backlog
+//	score: M
+//	assigned_to: 1
+//	tags: [scoring, backlog]
+//	parent: 12
+//	---
+//	Require people to estimate the score of an item before they can start working on it.
+//
+// The story fields (who, what, why) are only present for stories.
+// Lines starting with # before the front matter are comments.
+type Document struct {
+	Type        string
+	Title       string
+	Who         string
+	What        string
+	Why         string
+	Score       sprintly.ItemScore
+	Status      sprintly.ItemStatus
+	AssignedTo  int
+	Tags        []string
+	Parent      int
+	Description string
+}
+
+// header is the front matter of a serialized document. The fields not relevant
+// for the item type are nil and omitted, the unset numbers are nil and kept.
+type header struct {
+	Type       string   `yaml:"type"`
+	Title      *string  `yaml:"title,omitempty"`
+	Who        *string  `yaml:"who,omitempty"`
+	What       *string  `yaml:"what,omitempty"`
+	Why        *string  `yaml:"why,omitempty"`
+	Status     string   `yaml:"status"`
+	Score      string   `yaml:"score"`
+	AssignedTo *int     `yaml:"assigned_to"`
+	Tags       []string `yaml:"tags,flow"`
+	Parent     *int     `yaml:"parent"`
+}
+
+// NewDocument returns the document for the given item.
+func NewDocument(item *sprintly.Item) (*Document, error) {
+	parent, err := item.ParentNumber()
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &Document{
+		Type:        item.Type,
+		Title:       item.Title,
+		Who:         item.Who,
+		What:        item.What,
+		Why:         item.Why,
+		Score:       item.Score,
+		Status:      item.Status,
+		Tags:        item.Tags,
+		Parent:      parent,
+		Description: item.Description,
+	}
+	if item.AssignedTo != nil {
+		doc.AssignedTo = item.AssignedTo.Id
+	}
+
+	// Only the fields relevant for the item type are editable.
+	if doc.isStory() {
+		doc.Title = ""
+	} else {
+		doc.Who, doc.What, doc.Why = "", "", ""
+	}
+	doc.normalize()
+	return doc, nil
+}
+
+// normalize trims the text fields the same way on both sides of a diff,
+// so that the whitespace lost in the editor is not reported as a change.
+func (doc *Document) normalize() {
+	for _, field := range []*string{&doc.Title, &doc.Who, &doc.What, &doc.Why} {
+		*field = strings.TrimSpace(*field)
+	}
+	doc.Description = normalizeDescription(doc.Description)
+}
+
+// Item returns an item holding the document fields.
+func (doc *Document) Item() *sprintly.Item {
+	item := &sprintly.Item{
+		Type:        doc.Type,
+		Title:       doc.Title,
+		Who:         doc.Who,
+		What:        doc.What,
+		Why:         doc.Why,
+		Score:       doc.Score,
+		Status:      doc.Status,
+		Tags:        doc.Tags,
+		Description: doc.Description,
+	}
+	if doc.AssignedTo != 0 {
+		item.AssignedTo = &sprintly.User{Id: doc.AssignedTo}
+	}
+	if doc.Parent != 0 {
+		// Numbers are decoded from JSON as float64, see Item.ParentNumber.
+		item.Parent = float64(doc.Parent)
+	}
+	return item
+}
+
+func (doc *Document) isStory() bool {
+	return doc.Type == string(sprintly.ItemTypeStory)
+}
+
+// Marshal serializes the document.
+func (doc *Document) Marshal() []byte {
+	h := header{
+		Type:       doc.Type,
+		Status:     string(doc.Status),
+		Score:      string(doc.Score),
+		AssignedTo: optionalNumber(doc.AssignedTo),
+		Tags:       doc.Tags,
+		Parent:     optionalNumber(doc.Parent),
+	}
+	if doc.isStory() {
+		h.Who, h.What, h.Why = &doc.Who, &doc.What, &doc.Why
+	} else {
+		h.Title = &doc.Title
+	}
+	front, err := yaml.Marshal(&h)
+	if err != nil {
+		// The header consists of strings and numbers only.
+		panic(err)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(headerDelimiter + "\n")
+	buf.Write(front)
+	buf.WriteString(headerDelimiter + "\n")
+
+	buf.WriteString(doc.Description)
+	if doc.Description != "" && !strings.HasSuffix(doc.Description, "\n") {
+		buf.WriteString("\n")
+	}
+	return buf.Bytes()
+}
+
+// Unmarshal parses the document as produced by Marshal.
+func Unmarshal(data []byte) (*Document, error) {
+	lines := strings.SplitAfter(string(data), "\n")
+
+	// Skip empty lines and comments before the front matter.
+	i := 0
+	for i < len(lines) {
+		line := strings.TrimSpace(lines[i])
+		if line != "" && !strings.HasPrefix(line, "#") {
+			break
+		}
+		i++
+	}
+	if i == len(lines) || strings.TrimSpace(lines[i]) != headerDelimiter {
+		return nil, errors.New("editor.Unmarshal: document header missing")
+	}
+	start := i + 1
+
+	end := -1
+	for i = start; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == headerDelimiter {
+			end = i
+			break
+		}
+	}
+	if end == -1 {
+		return nil, errors.New("editor.Unmarshal: document header not terminated")
+	}
+
+	var h header
+	if err := yaml.UnmarshalStrict([]byte(strings.Join(lines[start:end], "")), &h); err != nil {
+		return nil, fmt.Errorf("editor.Unmarshal: %v", err)
+	}
+
+	doc := &Document{
+		Type:        h.Type,
+		Title:       valueOf(h.Title),
+		Who:         valueOf(h.Who),
+		What:        valueOf(h.What),
+		Why:         valueOf(h.Why),
+		Status:      sprintly.ItemStatus(h.Status),
+		Score:       sprintly.ItemScore(h.Score),
+		AssignedTo:  valueOf(h.AssignedTo),
+		Parent:      valueOf(h.Parent),
+		Description: strings.Join(lines[end+1:], ""),
+	}
+	for _, tag := range h.Tags {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			doc.Tags = append(doc.Tags, tag)
+		}
+	}
+	doc.normalize()
+	return doc, nil
+}
+
+func optionalNumber(n int) *int {
+	if n == 0 {
+		return nil
+	}
+	return &n
+}
+
+func valueOf[T any](p *T) T {
+	var v T
+	if p != nil {
+		v = *p
+	}
+	return v
+}
+
+// normalizeDescription trims the description and converts the line endings to \n,
+// the same way on both sides of a diff so that CRLFs are not reported as changes.
+func normalizeDescription(description string) string {
+	description = strings.ReplaceAll(description, "\r\n", "\n")
+	description = strings.ReplaceAll(description, "\r", "\n")
+	return strings.TrimSpace(description)
+}
+
+func formatNumber(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+// Change represents a single field changed in a document.
+type Change struct {
+	Field string
+	Old   string
+	New   string
+}
+
+func (change Change) String() string {
+	return fmt.Sprintf("%v: %q -> %q", change.Field, change.Old, change.New)
+}
+
+// Diff returns the fields that differ between the two documents, formatted for display.
+// The update itself is computed by sprintly.DiffItems.
+func Diff(old, new *Document) []Change {
+	var changes []Change
+	compare := func(field, o, n string) {
+		if o != n {
+			changes = append(changes, Change{field, o, n})
+		}
+	}
+
+	compare("type", old.Type, new.Type)
+	compare("title", old.Title, new.Title)
+	compare("who", old.Who, new.Who)
+	compare("what", old.What, new.What)
+	compare("why", old.Why, new.Why)
+	compare("status", string(old.Status), string(new.Status))
+	compare("score", string(old.Score), string(new.Score))
+	compare("assigned_to", formatNumber(old.AssignedTo), formatNumber(new.AssignedTo))
+	compare("tags", strings.Join(old.Tags, ", "), strings.Join(new.Tags, ", "))
+	compare("parent", formatNumber(old.Parent), formatNumber(new.Parent))
+	compare("description", old.Description, new.Description)
+	return changes
+}
@@ -0,0 +1,186 @@
+// Package editor makes it possible to edit Sprintly items in a text editor.
+//
+// The item is turned into a Markdown document (see Document), the document
+// is opened in the editor and the fields changed are applied using Items.Update.
+package editor
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+// ErrConflict is returned when the item was modified while being edited.
+type ErrConflict = sprintly.ErrItemConflict
+
+// ErrInvalidEdit is returned when the document edited cannot be parsed
+// or applied, e.g. because a field was cleared. The file edited is kept
+// so that the edits are not lost.
+type ErrInvalidEdit struct {
+	// Path is the path of the file edited.
+	Path string
+
+	Err error
+}
+
+func (err *ErrInvalidEdit) Error() string {
+	return fmt.Sprintf("%v (the edited item is kept in %v)", err.Err, err.Path)
+}
+
+func (err *ErrInvalidEdit) Unwrap() error {
+	return err.Err
+}
+
+// Editor edits Sprintly items in a text editor.
+type Editor struct {
+	Client *sprintly.Client
+
+	// Command is the editor command to run, the file path being appended
+	// as the last argument. $VISUAL, $EDITOR or vi is used when empty.
+	Command string
+
+	// Launch opens the given file in the editor and returns when the editor exits.
+	// It defaults to running Command, attached to the standard streams.
+	Launch func(path string) error
+
+	// Confirm is called with the changes to be applied and can abort the update.
+	// The changes are applied without confirmation when Confirm is nil.
+	Confirm func(item *sprintly.Item, changes []Change) (bool, error)
+
+	// Merge makes the changes apply on top of the modifications made while
+	// the item was being edited, as long as they touch different fields.
+	Merge bool
+}
+
+// Edit fetches the given item, lets the user edit it and applies the changes.
+//
+// The item returned is the updated item, or the original one in case there was
+// nothing to apply. An *ErrConflict is returned when the item was modified
+// while being edited, the changes being discarded, unless Merge is set
+// and the modifications can be merged. An *ErrInvalidEdit is returned
+// when the edits cannot be applied, before Confirm is called.
+func (ed *Editor) Edit(productId, itemNumber int) (*sprintly.Item, []Change, error) {
+	item, _, err := ed.Client.Items.Get(productId, itemNumber)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	original, err := NewDocument(item)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	path, err := ed.editDocument(item, original)
+	if err != nil {
+		return nil, nil, err
+	}
+	// The file is only kept when the edits are invalid.
+	keep := false
+	defer func() {
+		if !keep {
+			os.Remove(path)
+		}
+	}()
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	edited, err := Unmarshal(content)
+	if err != nil {
+		keep = true
+		return nil, nil, &ErrInvalidEdit{Path: path, Err: err}
+	}
+
+	itemChanges, err := sprintly.DiffItems(original.Item(), edited.Item())
+	if err != nil {
+		return nil, nil, err
+	}
+	if itemChanges.Empty() {
+		return item, nil, nil
+	}
+
+	// Fields cannot be cleared using Items.Update, in which case
+	// a *sprintly.ErrFieldCleared is returned.
+	changes := Diff(original, edited)
+	args, err := itemChanges.UpdateArgs()
+	if err != nil {
+		keep = true
+		return nil, changes, &ErrInvalidEdit{Path: path, Err: err}
+	}
+
+	if ed.Confirm != nil {
+		ok, err := ed.Confirm(item, changes)
+		if err != nil || !ok {
+			return item, nil, err
+		}
+	}
+
+	// Make sure nobody else modified the item in the meantime.
+	var updated *sprintly.Item
+	if ed.Merge {
+		updated, _, err = ed.Client.Items.UpdateMerge(productId, item, args, nil)
+	} else {
+		updated, _, err = ed.Client.Items.UpdateIf(productId, itemNumber, item.LastModified, args)
+	}
+	if err != nil {
+		return nil, changes, err
+	}
+	return updated, changes, nil
+}
+
+// editDocument writes the document into a temporary file and opens it in the editor,
+// returning the path of the file once the editor exits. The caller removes the file.
+func (ed *Editor) editDocument(item *sprintly.Item, doc *Document) (string, error) {
+	file, err := os.CreateTemp("", fmt.Sprintf("sprintly-item-%v-*.md", item.Number))
+	if err != nil {
+		return "", err
+	}
+
+	header := fmt.Sprintf("# Item #%v, %v\n", item.Number, item.ShortURL)
+	content := append([]byte(header), doc.Marshal()...)
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+
+	launch := ed.Launch
+	if launch == nil {
+		launch = ed.run
+	}
+	if err := launch(file.Name()); err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+	return file.Name(), nil
+}
+
+// run runs the editor command for the given file.
+func (ed *Editor) run(path string) error {
+	command := ed.Command
+	for _, v := range []string{"VISUAL", "EDITOR"} {
+		if command == "" {
+			command = os.Getenv(v)
+		}
+	}
+	if command == "" {
+		command = "vi"
+	}
+
+	// Run through the shell so that commands like "code --wait" work.
+	cmd := exec.Command("sh", "-c", command+` "$1"`, "sh", path)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor %q failed: %v", command, err)
+	}
+	return nil
+}
@@ -0,0 +1,249 @@
+package editor
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+var testingItemJson = `
+{
+	"number": 188,
+	"type": "task",
+	"title": "Don't let un-scored items out of the backlog.",
+	"description": "Require people to estimate the score.",
+	"status": "backlog",
+	"score": "M",
+	"tags": ["scoring"],
+	"parent": 12,
+	"assigned_to": {"id": 1},
+	"last_modified": "%v"
+}`
+
+func TestDocument_Roundtrip(t *testing.T) {
+	doc := &Document{
+		Type:        "story",
+		Who:         "user",
+		What:        "to edit items",
+		Why:         "it is faster",
+		Score:       sprintly.ItemScoreLarge,
+		Status:      sprintly.ItemStatusBacklog,
+		AssignedTo:  1,
+		Tags:        []string{"cli", "editor"},
+		Description: "Multi-line\n\n---\n\ndescription.",
+	}
+
+	got, err := Unmarshal(doc.Marshal())
+	if err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, doc) {
+		t.Errorf("Unmarshal(doc.Marshal())\n got = %+v\nwant = %+v", got, doc)
+	}
+}
+
+func TestDocument_CRLF(t *testing.T) {
+	item := &sprintly.Item{Type: "task", Title: "Windows", Description: "First line\r\nsecond line\r\n"}
+	doc, err := NewDocument(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The editor may keep or convert the line endings, neither is a change.
+	for _, data := range [][]byte{doc.Marshal(), []byte(strings.ReplaceAll(string(doc.Marshal()), "\n", "\r\n"))} {
+		edited, err := Unmarshal(data)
+		if err != nil {
+			t.Fatalf("Unmarshal failed: %v", err)
+		}
+		if changes := Diff(doc, edited); len(changes) != 0 {
+			t.Errorf("unexpected changes: %v", changes)
+		}
+	}
+}
+
+func TestDocument_Whitespace(t *testing.T) {
+	item := &sprintly.Item{Type: "task", Title: "Trailing whitespace \t", Tags: []string{"api"}}
+	doc, err := NewDocument(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := strings.Replace(string(doc.Marshal()), "tags: [api]", "tags: [' api ', '']", 1)
+	edited, err := Unmarshal([]byte(data))
+	if err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if changes := Diff(doc, edited); len(changes) != 0 {
+		t.Errorf("unexpected changes: %v", changes)
+	}
+}
+
+func TestUnmarshal_Invalid(t *testing.T) {
+	for _, content := range []string{
+		"title: no header",
+		"---\ntitle: not terminated\n",
+		"---\nunknown: field\n---\n",
+		"---\nassigned_to: joe\n---\n",
+		"---\ntitle: [unterminated\n---\n",
+	} {
+		if _, err := Unmarshal([]byte(content)); err == nil {
+			t.Errorf("Unmarshal(%q) should have failed", content)
+		}
+	}
+}
+
+// setup starts a testing server serving item 188 of product 1
+// and returns the client and the arguments of the update received.
+func setup(t *testing.T, lastModified ...string) (*sprintly.Client, *sprintly.ItemUpdateArgs) {
+	var (
+		update sprintly.ItemUpdateArgs
+		gets   int
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/products/1/items/188.json", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			fmt.Fprintf(w, testingItemJson, lastModified[gets])
+			gets++
+		case "POST":
+			r.ParseForm()
+			update.Title = r.PostForm.Get("title")
+			update.Score = sprintly.ItemScore(r.PostForm.Get("score"))
+			update.Tags = strings.Split(r.PostForm.Get("tags"), ",")
+			update.Description = r.PostForm.Get("description")
+			update.Status = sprintly.ItemStatus(r.PostForm.Get("status"))
+			fmt.Fprintf(w, testingItemJson, lastModified[gets-1])
+		}
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client := sprintly.NewClient("krtecek", "secret")
+	client.SetBaseURL(server.URL)
+	return client, &update
+}
+
+// rewrite returns a Launch function replacing old with new in the file edited.
+func rewrite(replacements ...string) func(string) error {
+	return func(path string) error {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		s := strings.NewReplacer(replacements...).Replace(string(content))
+		return os.WriteFile(path, []byte(s), 0600)
+	}
+}
+
+func TestEditor_Edit(t *testing.T) {
+	modified := "2015-01-01T10:00:00Z"
+	client, update := setup(t, modified, modified)
+
+	var confirmed []Change
+	ed := &Editor{
+		Client: client,
+		Launch: rewrite(
+			"score: M", "score: L",
+			"tags: [scoring]", "tags: [scoring, cli]",
+		),
+		Confirm: func(item *sprintly.Item, changes []Change) (bool, error) {
+			confirmed = changes
+			return true, nil
+		},
+	}
+
+	_, changes, err := ed.Edit(1, 188)
+	if err != nil {
+		t.Fatalf("Editor.Edit failed: %v", err)
+	}
+
+	want := []Change{
+		{"score", "M", "L"},
+		{"tags", "scoring", "scoring, cli"},
+	}
+	if !reflect.DeepEqual(changes, want) || !reflect.DeepEqual(confirmed, want) {
+		t.Errorf("changes = %v, confirmed = %v, want %v", changes, confirmed, want)
+	}
+
+	wantUpdate := &sprintly.ItemUpdateArgs{
+		Score: sprintly.ItemScoreLarge,
+		Tags:  []string{"scoring", "cli"},
+	}
+	if !reflect.DeepEqual(update, wantUpdate) {
+		t.Errorf("update = %+v, want %+v", update, wantUpdate)
+	}
+}
+
+func TestEditor_Edit_Conflict(t *testing.T) {
+	client, update := setup(t, "2015-01-01T10:00:00Z", "2015-01-01T10:05:00Z")
+
+	ed := &Editor{
+		Client: client,
+		Launch: rewrite("status: backlog", "status: in-progress"),
+	}
+
+	_, _, err := ed.Edit(1, 188)
+	if _, ok := err.(*ErrConflict); !ok {
+		t.Errorf("Editor.Edit should have failed with a conflict, err = %v", err)
+	}
+	if update.Status != "" {
+		t.Errorf("the item was updated despite the conflict")
+	}
+}
+
+func TestEditor_Edit_Clear(t *testing.T) {
+	modified := "2015-01-01T10:00:00Z"
+	client, _ := setup(t, modified, modified)
+
+	ed := &Editor{
+		Client: client,
+		Launch: rewrite("tags: [scoring]", "tags: []", "score: M", "score: L"),
+		Confirm: func(item *sprintly.Item, changes []Change) (bool, error) {
+			t.Error("the changes to be refused were confirmed")
+			return false, nil
+		},
+	}
+
+	// The edits are kept for the user to fix them.
+	_, _, err := ed.Edit(1, 188)
+	var invalid *ErrInvalidEdit
+	var cleared *sprintly.ErrFieldCleared
+	if !errors.As(err, &invalid) || !errors.As(err, &cleared) {
+		t.Fatalf("Editor.Edit should have refused to clear the tags, err = %v", err)
+	}
+	defer os.Remove(invalid.Path)
+	content, err := os.ReadFile(invalid.Path)
+	if err != nil || !strings.Contains(string(content), "score: L") {
+		t.Errorf("the edited file was not kept: %q, %v", content, err)
+	}
+
+	ed.Launch = rewrite("type: task", "type: task\nunknown: field")
+	if _, _, err := ed.Edit(1, 188); !errors.As(err, &invalid) {
+		t.Fatalf("Editor.Edit should have failed to parse the document, err = %v", err)
+	}
+	os.Remove(invalid.Path)
+}
+
+func TestEditor_Edit_Merge(t *testing.T) {
+	client, update := setup(t, "2015-01-01T10:00:00Z", "2015-01-01T10:05:00Z", "2015-01-01T10:05:00Z")
+
+	ed := &Editor{
+		Client: client,
+		Launch: rewrite("score: M", "score: XL"),
+		Merge:  true,
+	}
+
+	if _, _, err := ed.Edit(1, 188); err != nil {
+		t.Fatalf("Editor.Edit failed: %v", err)
+	}
+	if update.Score != sprintly.ItemScoreVeryLarge {
+		t.Errorf("update = %+v", update)
+	}
+}
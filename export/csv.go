@@ -0,0 +1,151 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+// Column represents a single CSV column.
+type Column struct {
+	Name  string
+	Value func(rec *Record) string
+}
+
+// AllColumns lists the predefined columns by name.
+var AllColumns = map[string]Column{
+	"number":         {"number", func(rec *Record) string { return strconv.Itoa(rec.Item.Number) }},
+	"type":           {"type", func(rec *Record) string { return rec.Item.Type }},
+	"title":          {"title", func(rec *Record) string { return rec.Item.Title }},
+	"description":    {"description", func(rec *Record) string { return rec.Item.Description }},
+	"status":         {"status", func(rec *Record) string { return string(rec.Item.Status) }},
+	"score":          {"score", func(rec *Record) string { return string(rec.Item.Score) }},
+	"points":         {"points", func(rec *Record) string { return strconv.Itoa(rec.Item.Score.Points()) }},
+	"tags":           {"tags", func(rec *Record) string { return strings.Join(rec.Item.Tags, ",") }},
+	"assignee":       {"assignee", func(rec *Record) string { return UserName(rec.AssignedTo) }},
+	"assignee_email": {"assignee_email", func(rec *Record) string { return userEmail(rec.AssignedTo) }},
+	"creator":        {"creator", func(rec *Record) string { return UserName(rec.CreatedBy) }},
+	"creator_email":  {"creator_email", func(rec *Record) string { return userEmail(rec.CreatedBy) }},
+	"parent":         {"parent", func(rec *Record) string { return itemNumber(rec.Parent) }},
+	"children": {"children", func(rec *Record) string {
+		numbers := make([]string, len(rec.Children))
+		for i, child := range rec.Children {
+			numbers[i] = itemNumber(child)
+		}
+		return strings.Join(numbers, ",")
+	}},
+	"created_at":    {"created_at", func(rec *Record) string { return formatTime(rec.Item.CreatedAt) }},
+	"last_modified": {"last_modified", func(rec *Record) string { return formatTime(rec.Item.LastModified) }},
+	"short_url":     {"short_url", func(rec *Record) string { return rec.Item.ShortURL }},
+}
+
+// DefaultColumns are the columns used when no columns are specified.
+var DefaultColumns = MustColumns("number", "type", "status", "score", "title", "assignee", "tags", "parent")
+
+// Columns returns the predefined columns with the given names.
+func Columns(names ...string) ([]Column, error) {
+	columns := make([]Column, len(names))
+	for i, name := range names {
+		column, ok := AllColumns[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("export: unknown column %q", name)
+		}
+		columns[i] = column
+	}
+	return columns, nil
+}
+
+// MustColumns is like Columns, but panics on unknown column names.
+func MustColumns(names ...string) []Column {
+	columns, err := Columns(names...)
+	if err != nil {
+		panic(err)
+	}
+	return columns
+}
+
+type csvWriter struct {
+	w       *csv.Writer
+	columns []Column
+	header  bool
+}
+
+// NewCSVWriter returns a writer writing the given columns, DefaultColumns when empty.
+// The header row is written before the first record.
+func NewCSVWriter(w io.Writer, columns []Column) Writer {
+	if len(columns) == 0 {
+		columns = DefaultColumns
+	}
+	return &csvWriter{w: csv.NewWriter(w), columns: columns}
+}
+
+func (w *csvWriter) Write(rec *Record) error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+
+	row := make([]string, len(w.columns))
+	for i, column := range w.columns {
+		row[i] = column.Value(rec)
+	}
+	return w.w.Write(row)
+}
+
+func (w *csvWriter) writeHeader() error {
+	if w.header {
+		return nil
+	}
+	w.header = true
+
+	header := make([]string, len(w.columns))
+	for i, column := range w.columns {
+		header[i] = column.Name
+	}
+	return w.w.Write(header)
+}
+
+func (w *csvWriter) Close() error {
+	// Write at least the header for empty exports.
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	w.w.Flush()
+	return w.w.Error()
+}
+
+// UserName returns the full name of the given user, the email when the name is not known.
+func UserName(user *sprintly.User) string {
+	if user == nil {
+		return ""
+	}
+	if name := strings.TrimSpace(user.FirstName + " " + user.LastName); name != "" {
+		return name
+	}
+	return user.Email
+}
+
+func userEmail(user *sprintly.User) string {
+	if user == nil {
+		return ""
+	}
+	return user.Email
+}
+
+func itemNumber(item *sprintly.Item) string {
+	if item == nil {
+		return ""
+	}
+	return strconv.Itoa(item.Number)
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
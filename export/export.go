@@ -0,0 +1,109 @@
+// Package export writes Sprintly product snapshots in formats suitable for stakeholders.
+//
+// The items are turned into records, with the people resolved and the parent
+// and children linked, and the records are passed to a Writer:
+//
+//	w := export.NewCSVWriter(os.Stdout, export.DefaultColumns)
+//	err := export.Export(client, productId, nil, w)
+//
+// Linking the parents and the children needs all the items, so Export loads
+// the matching items before writing the first record. The JSON Lines and CSV
+// writers then write every record to the io.Writer right away, the Markdown
+// writer needs to group the records and writes them on Close.
+package export
+
+import (
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+// Record represents a single exported item.
+type Record struct {
+	Item *sprintly.Item
+
+	// AssignedTo and CreatedBy are resolved using People.List when possible,
+	// the users embedded in the item are used otherwise.
+	AssignedTo *sprintly.User
+	CreatedBy  *sprintly.User
+
+	Parent   *sprintly.Item
+	Children []*sprintly.Item
+}
+
+// Writer writes records in a particular format.
+type Writer interface {
+	// Write writes a single record.
+	Write(rec *Record) error
+
+	// Close writes whatever is buffered, the underlying io.Writer is not closed.
+	Close() error
+}
+
+// Export writes all the items of the given product matching the given arguments.
+//
+// The items are fetched using Items.ListAll, children included, and linked using
+// sprintly.NewItemTree. The items in all the statuses are exported unless
+// args.Status is set. The records are written depth first, parents before children.
+// The writer is closed once all the records are written.
+func Export(client *sprintly.Client, productId int, args *sprintly.ItemListArgs, w Writer) error {
+	var listArgs sprintly.ItemListArgs
+	if args != nil {
+		listArgs = *args
+	}
+	listArgs.Children = true
+	if len(listArgs.Status) == 0 {
+		listArgs.Status = sprintly.ItemStatuses
+	}
+
+	items, _, err := client.Items.ListAll(productId, &listArgs)
+	if err != nil {
+		return err
+	}
+
+	people, _, err := client.People.List(productId)
+	if err != nil {
+		return err
+	}
+
+	if err := WriteItems(w, items, people); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// WriteItems links the given items, resolves the people and writes the records.
+// The writer is not closed.
+func WriteItems(w Writer, items []sprintly.Item, people []sprintly.User) error {
+	tree, err := sprintly.NewItemTree(items)
+	if err != nil {
+		return err
+	}
+
+	users := make(map[int]*sprintly.User, len(people))
+	for i := range people {
+		users[people[i].Id] = &people[i]
+	}
+	resolve := func(user *sprintly.User) *sprintly.User {
+		if user == nil {
+			return nil
+		}
+		if u, ok := users[user.Id]; ok {
+			return u
+		}
+		return user
+	}
+
+	return tree.Walk(func(node *sprintly.ItemNode, depth int) error {
+		rec := &Record{
+			Item:       node.Item,
+			AssignedTo: resolve(node.Item.AssignedTo),
+			CreatedBy:  resolve(node.Item.CreatedBy),
+		}
+		if node.Parent != nil {
+			rec.Parent = node.Parent.Item
+		}
+		for _, child := range node.Children {
+			rec.Children = append(rec.Children, child.Item)
+		}
+		return w.Write(rec)
+	})
+}
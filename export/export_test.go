@@ -0,0 +1,121 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+const testingItems = `[
+	{"number": 1, "type": "story", "status": "backlog", "score": "M", "title": "Story",
+	 "tags": ["api"], "assigned_to": {"id": 1}, "created_by": {"id": 2, "email": "jane@example.com"}},
+	{"number": 2, "type": "task", "status": "in-progress", "score": "S", "title": "Task_one",
+	 "parent": 1, "assigned_to": {"id": 1}},
+	{"number": 3, "type": "defect", "status": "in-progress", "score": "~", "title": "Defect",
+	 "parent": {"number": 1}}
+]`
+
+const testingPeople = `[{"id": 1, "first_name": "Joe", "last_name": "Stump", "email": "joe@joestump.net"}]`
+
+func serve(t *testing.T) *sprintly.Client {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/products/1/items.json", func(w http.ResponseWriter, r *http.Request) {
+		if v := r.URL.Query().Get("children"); v != "true" {
+			t.Errorf("children = %q, want true", v)
+		}
+		if v := r.URL.Query().Get("status"); v != "someday,backlog,in-progress,completed,accepted" {
+			t.Errorf("status = %q, want all the statuses", v)
+		}
+		if r.URL.Query().Get("offset") != "" {
+			fmt.Fprint(w, `[]`)
+			return
+		}
+		fmt.Fprint(w, testingItems)
+	})
+	mux.HandleFunc("/products/1/people.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, testingPeople)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client := sprintly.NewClient("krtecek", "secret")
+	client.SetBaseURL(server.URL)
+	return client
+}
+
+func TestExport_JSONLines(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Export(serve(t), 1, nil, NewJSONLinesWriter(&buf)); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %v lines, want 3", len(lines))
+	}
+
+	var first struct {
+		Number     int            `json:"number"`
+		AssignedTo *sprintly.User `json:"assigned_to"`
+		Children   []int          `json:"children_numbers"`
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatal(err)
+	}
+	if first.Number != 1 || first.AssignedTo.Email != "joe@joestump.net" || fmt.Sprint(first.Children) != "[2 3]" {
+		t.Errorf("first line = %v", lines[0])
+	}
+	if !strings.Contains(lines[1], `"parent_number":1`) {
+		t.Errorf("second line = %v", lines[1])
+	}
+}
+
+func TestExport_CSV(t *testing.T) {
+	columns, err := Columns("number", "title", "assignee", "creator_email", "points", "parent", "children")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := Export(serve(t), 1, nil, NewCSVWriter(&buf, columns)); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `number,title,assignee,creator_email,points,parent,children
+1,Story,Joe Stump,jane@example.com,3,,"2,3"
+2,Task_one,Joe Stump,,1,1,
+3,Defect,,,0,1,
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestColumns_Unknown(t *testing.T) {
+	if _, err := Columns("number", "velocity"); err == nil {
+		t.Error("expected an error for an unknown column")
+	}
+}
+
+func TestExport_Markdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Export(serve(t), 1, nil, NewMarkdownWriter(&buf, "Product")); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "# Product\n\n3 items in total.\n" +
+		"\n## Backlog\n" +
+		"\n### Stories\n\n- **#1** Story (M, Joe Stump, `api`)\n" +
+		"\n## In Progress\n" +
+		"\n### Defects\n\n- **#3** Defect (part of #1)\n" +
+		"\n### Tasks\n\n- **#2** Task\\_one (S, Joe Stump, part of #1)\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got\n%v\nwant\n%v", got, want)
+	}
+}
@@ -0,0 +1,47 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+// jsonRecord is the JSON representation of a record, the item fields
+// being extended with the parent and children numbers.
+type jsonRecord struct {
+	*sprintly.Item
+	ParentNumber    int   `json:"parent_number,omitempty"`
+	ChildrenNumbers []int `json:"children_numbers,omitempty"`
+}
+
+type jsonLinesWriter struct {
+	enc *json.Encoder
+}
+
+// NewJSONLinesWriter returns a writer encoding every record as a single JSON line.
+//
+// The record is encoded as the item with the resolved people in place of
+// assigned_to and created_by, plus the parent_number and children_numbers fields.
+func NewJSONLinesWriter(w io.Writer) Writer {
+	return &jsonLinesWriter{json.NewEncoder(w)}
+}
+
+func (w *jsonLinesWriter) Write(rec *Record) error {
+	item := *rec.Item
+	item.AssignedTo = rec.AssignedTo
+	item.CreatedBy = rec.CreatedBy
+
+	jr := jsonRecord{Item: &item}
+	if rec.Parent != nil {
+		jr.ParentNumber = rec.Parent.Number
+	}
+	for _, child := range rec.Children {
+		jr.ChildrenNumbers = append(jr.ChildrenNumbers, child.Number)
+	}
+	return w.enc.Encode(&jr)
+}
+
+func (w *jsonLinesWriter) Close() error {
+	return nil
+}
@@ -0,0 +1,146 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+var (
+	markdownTypes = []sprintly.ItemType{
+		sprintly.ItemTypeStory,
+		sprintly.ItemTypeDefect,
+		sprintly.ItemTypeTask,
+		sprintly.ItemTypeTest,
+	}
+
+	statusTitles = map[sprintly.ItemStatus]string{
+		sprintly.ItemStatusSomeday:    "Someday",
+		sprintly.ItemStatusBacklog:    "Backlog",
+		sprintly.ItemStatusInProgress: "In Progress",
+		sprintly.ItemStatusCompleted:  "Completed",
+		sprintly.ItemStatusAccepted:   "Accepted",
+	}
+
+	typeTitles = map[sprintly.ItemType]string{
+		sprintly.ItemTypeStory:  "Stories",
+		sprintly.ItemTypeDefect: "Defects",
+		sprintly.ItemTypeTask:   "Tasks",
+		sprintly.ItemTypeTest:   "Tests",
+	}
+)
+
+type markdownWriter struct {
+	w      io.Writer
+	title  string
+	groups map[sprintly.ItemStatus]map[sprintly.ItemType][]*Record
+	count  int
+}
+
+// NewMarkdownWriter returns a writer producing a Markdown report with the given title.
+//
+// The items are grouped by status and then by type. Since the groups are only
+// complete once all the records are written, the report is written on Close.
+func NewMarkdownWriter(w io.Writer, title string) Writer {
+	return &markdownWriter{
+		w:      w,
+		title:  title,
+		groups: make(map[sprintly.ItemStatus]map[sprintly.ItemType][]*Record),
+	}
+}
+
+func (w *markdownWriter) Write(rec *Record) error {
+	status := rec.Item.Status
+	if w.groups[status] == nil {
+		w.groups[status] = make(map[sprintly.ItemType][]*Record)
+	}
+	typ := sprintly.ItemType(rec.Item.Type)
+	w.groups[status][typ] = append(w.groups[status][typ], rec)
+	w.count++
+	return nil
+}
+
+func (w *markdownWriter) Close() error {
+	bw := bufio.NewWriter(w.w)
+
+	if w.title != "" {
+		fmt.Fprintf(bw, "# %v\n\n", w.title)
+	}
+	fmt.Fprintf(bw, "%v items in total.\n", w.count)
+
+	for _, status := range orderedKeys(w.groups, sprintly.ItemStatuses) {
+		byType := w.groups[status]
+		fmt.Fprintf(bw, "\n## %v\n", titleOr(statusTitles[status], string(status)))
+
+		for _, typ := range orderedKeys(byType, markdownTypes) {
+			fmt.Fprintf(bw, "\n### %v\n\n", titleOr(typeTitles[typ], string(typ)))
+			for _, rec := range byType[typ] {
+				writeMarkdownItem(bw, rec)
+			}
+		}
+	}
+
+	w.groups = nil
+	return bw.Flush()
+}
+
+func writeMarkdownItem(w io.Writer, rec *Record) {
+	item := rec.Item
+
+	var details []string
+	if item.Score != "" && item.Score != sprintly.ItemScoreUnset {
+		details = append(details, string(item.Score))
+	}
+	if name := UserName(rec.AssignedTo); name != "" {
+		details = append(details, name)
+	}
+	if rec.Parent != nil {
+		details = append(details, fmt.Sprintf("part of #%v", rec.Parent.Number))
+	}
+	for _, tag := range item.Tags {
+		details = append(details, "`"+tag+"`")
+	}
+
+	fmt.Fprintf(w, "- **#%v** %v", item.Number, escapeMarkdown(item.Title))
+	if len(details) != 0 {
+		fmt.Fprintf(w, " (%v)", strings.Join(details, ", "))
+	}
+	fmt.Fprintln(w)
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`, "<", "&lt;", "\n", " ",
+)
+
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+func titleOr(title, fallback string) string {
+	if title != "" {
+		return title
+	}
+	return fallback
+}
+
+// orderedKeys returns the keys present in m, the known keys first
+// in the given order, the unknown keys after them sorted.
+func orderedKeys[K ~string, V any](m map[K]V, known []K) []K {
+	var keys, unknown []K
+	for _, k := range known {
+		if _, ok := m[k]; ok {
+			keys = append(keys, k)
+		}
+	}
+	for k := range m {
+		if !slices.Contains(known, k) {
+			unknown = append(unknown, k)
+		}
+	}
+	slices.Sort(unknown)
+	return append(keys, unknown...)
+}
@@ -0,0 +1,12 @@
+module github.com/salsita/go-sprintly
+
+go 1.23
+
+require (
+	github.com/google/go-querystring v1.0.0
+	github.com/gorilla/schema v1.1.0
+	github.com/kr/pretty v0.2.0
+	gopkg.in/yaml.v2 v2.4.0
+)
+
+require github.com/kr/text v0.2.0 // indirect
@@ -0,0 +1,275 @@
+package importer
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+// Fields lists the fields a CSV column can be mapped to.
+//
+// The key field identifies the row so that other rows can refer to it
+// in the parent field and records it in the journal. Rows without a key
+// are identified by a hash of their content, so editing such a row
+// between the runs of an import makes it a new row.
+// The assignee field contains the assignee email.
+var Fields = []string{
+	"key", "parent", "type", "title", "who", "what", "why",
+	"description", "score", "status", "tags", "assignee",
+}
+
+// Mapping maps the item fields to the CSV column headers.
+// The headers are matched case-insensitively.
+type Mapping map[string]string
+
+// DefaultMapping maps every field to the column of the same name.
+func DefaultMapping() Mapping {
+	mapping := make(Mapping, len(Fields))
+	for _, field := range Fields {
+		mapping[field] = field
+	}
+	return mapping
+}
+
+// ParseMapping parses field=header pairs and applies them over DefaultMapping.
+func ParseMapping(pairs []string) (Mapping, error) {
+	mapping := DefaultMapping()
+	for _, pair := range pairs {
+		field, header, ok := strings.Cut(pair, "=")
+		field = strings.TrimSpace(field)
+		if !ok || strings.TrimSpace(header) == "" {
+			return nil, fmt.Errorf("importer: invalid mapping %q, field=header expected", pair)
+		}
+		if _, ok := mapping[field]; !ok {
+			return nil, fmt.Errorf("importer: unknown field %q", field)
+		}
+		mapping[field] = strings.TrimSpace(header)
+	}
+	return mapping, nil
+}
+
+// Row represents a single item to be imported.
+type Row struct {
+	// Line is the line number of the row in the CSV file.
+	Line int
+
+	// Key identifies the row in the journal. It is the value of the key column,
+	// or a hash of the row content when the column is missing or empty,
+	// so that editing other rows between runs does not change it.
+	Key      string
+	Parent   string
+	Assignee string
+
+	// Args holds the arguments for Items.Create,
+	// AssignedTo is filled in once the assignee is resolved.
+	Args sprintly.ItemCreateArgs
+}
+
+// RowError represents an invalid row.
+type RowError struct {
+	Line int
+	Err  error
+}
+
+func (err *RowError) Error() string {
+	return fmt.Sprintf("line %v: %v", err.Line, err.Err)
+}
+
+// RowErrors collects the errors of all the invalid rows.
+type RowErrors []*RowError
+
+func (errs RowErrors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	return "importer: invalid rows:\n" + strings.Join(msgs, "\n")
+}
+
+// Read reads the rows from the given CSV input.
+//
+// The first line must contain the column headers. All the rows are validated
+// and RowErrors is returned in case any of them is invalid, so that nothing
+// is imported from a partially broken file.
+func Read(r io.Reader, mapping Mapping) ([]*Row, error) {
+	if mapping == nil {
+		mapping = DefaultMapping()
+	}
+
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("importer: header missing")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]int)
+	for field, name := range mapping {
+		for i, h := range header {
+			if strings.EqualFold(strings.TrimSpace(h), name) {
+				columns[field] = i
+				break
+			}
+		}
+	}
+	if _, ok := columns["type"]; !ok {
+		return nil, fmt.Errorf("importer: column %q for field type missing", mapping["type"])
+	}
+
+	var (
+		rows []*Row
+		errs RowErrors
+		keys = make(map[string]*Row)
+	)
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := cr.FieldPos(0)
+
+		value := func(field string) string {
+			i, ok := columns[field]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		row := &Row{
+			Line:     line,
+			Key:      value("key"),
+			Parent:   value("parent"),
+			Assignee: value("assignee"),
+			Args: sprintly.ItemCreateArgs{
+				Type:        strings.ToLower(value("type")),
+				Title:       value("title"),
+				Who:         value("who"),
+				What:        value("what"),
+				Why:         value("why"),
+				Description: value("description"),
+				Score:       sprintly.ItemScore(strings.ToUpper(value("score"))),
+				Status:      sprintly.ItemStatus(strings.ToLower(value("status"))),
+				Tags:        splitTags(value("tags")),
+			},
+		}
+		if row.Key == "" {
+			row.Key = contentKey(record)
+		}
+
+		if err := validate(row); err != nil {
+			errs = append(errs, &RowError{line, err})
+			continue
+		}
+		if prev, ok := keys[row.Key]; ok {
+			if value("key") == "" {
+				errs = append(errs, &RowError{line, fmt.Errorf("same as line %v, add a key column to import both", prev.Line)})
+			} else {
+				errs = append(errs, &RowError{line, fmt.Errorf("duplicate key %v, see line %v", row.Key, prev.Line)})
+			}
+			continue
+		}
+		keys[row.Key] = row
+		rows = append(rows, row)
+	}
+
+	for _, row := range rows {
+		if row.Parent == "" {
+			continue
+		}
+		if _, ok := keys[row.Parent]; !ok {
+			errs = append(errs, &RowError{row.Line, fmt.Errorf("unknown parent %v", row.Parent)})
+			continue
+		}
+		// Detect cycles by following the parents.
+		seen := map[string]bool{row.Key: true}
+		for p := keys[row.Parent]; p != nil; p = keys[p.Parent] {
+			if seen[p.Key] {
+				errs = append(errs, &RowError{row.Line, fmt.Errorf("parent cycle")})
+				break
+			}
+			seen[p.Key] = true
+		}
+	}
+
+	if len(errs) != 0 {
+		return nil, errs
+	}
+	return rows, nil
+}
+
+// contentKey returns the key of a row without one, derived from all its values.
+func contentKey(record []string) string {
+	h := sha256.New()
+	for _, value := range record {
+		value = strings.TrimSpace(value)
+		fmt.Fprintf(h, "%v:%v,", len(value), value)
+	}
+	return "row-" + hex.EncodeToString(h.Sum(nil))[:12]
+}
+
+// validate checks the row fields, stories without who, what and why
+// get them parsed from the title.
+func validate(row *Row) error {
+	args := &row.Args
+
+	switch sprintly.ItemType(args.Type) {
+	case sprintly.ItemTypeStory:
+		if args.Who == "" && args.What == "" && args.Why == "" && args.Title != "" {
+			story, err := sprintly.ParseStory(args.Title)
+			if err != nil {
+				return err
+			}
+			args.Title = ""
+			args.Who, args.What, args.Why = story.Who, story.What, story.Why
+		}
+		if args.Who == "" || args.What == "" || args.Why == "" {
+			return fmt.Errorf("who, what and why required for stories")
+		}
+	case sprintly.ItemTypeTask, sprintly.ItemTypeDefect, sprintly.ItemTypeTest:
+		if args.Title == "" {
+			return fmt.Errorf("title required")
+		}
+	default:
+		return fmt.Errorf("invalid type %q", args.Type)
+	}
+
+	switch args.Score {
+	case "", sprintly.ItemScoreUnset, sprintly.ItemScoreSmall, sprintly.ItemScoreMedium,
+		sprintly.ItemScoreLarge, sprintly.ItemScoreVeryLarge:
+	default:
+		return fmt.Errorf("invalid score %q", args.Score)
+	}
+
+	switch args.Status {
+	case "", sprintly.ItemStatusSomeday, sprintly.ItemStatusBacklog, sprintly.ItemStatusInProgress,
+		sprintly.ItemStatusCompleted, sprintly.ItemStatusAccepted:
+	default:
+		return fmt.Errorf("invalid status %q", args.Status)
+	}
+
+	return nil
+}
+
+// splitTags splits the tags separated by commas or semicolons.
+func splitTags(value string) []string {
+	var tags []string
+	for _, tag := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ';' }) {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
@@ -0,0 +1,237 @@
+// Package importer creates Sprintly items in bulk from CSV files.
+//
+// The rows are read and validated using Read, then created using Importer.Import:
+//
+//	rows, err := importer.Read(file, importer.DefaultMapping())
+//	journal, err := importer.OpenJournal("backlog.csv.journal")
+//	imp := &importer.Importer{Client: client, ProductId: 1, Journal: journal}
+//	report, err := imp.Import(rows)
+//
+// The items are created first and linked to their parents in a second pass,
+// since the parent cannot be set on create and may be further down the file.
+// Every step is recorded in the journal, so a failed import can be run again
+// without creating the same items twice.
+package importer
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+// Importer imports rows into a product.
+type Importer struct {
+	Client    *sprintly.Client
+	ProductId int
+
+	// Journal records the progress, it is kept in memory only when nil.
+	Journal *Journal
+
+	// DryRun makes Import report what would be done without changing anything.
+	DryRun bool
+
+	// Progress, when set, is called after every row created or linked.
+	Progress func(res *Result)
+}
+
+// Result represents a single step of an import.
+type Result struct {
+	Row *Row
+
+	// Number is the number of the item, zero for items not created yet in a dry run.
+	Number int
+
+	// Parent is the number of the parent item being set, zero when creating.
+	Parent int
+
+	// Resumed is set when the step was already carried out by a previous run.
+	Resumed bool
+}
+
+// Report summarises an import.
+type Report struct {
+	DryRun  bool
+	Created []*Result
+	Linked  []*Result
+}
+
+// Import creates the items for the given rows and sets their parents.
+//
+// The assignees are resolved by email using People.List before anything
+// is created, the import fails in case any of them is not a product member.
+// The rows recorded in the journal are skipped. The report is returned
+// even when the import fails, listing the steps carried out until then.
+func (imp *Importer) Import(rows []*Row) (*Report, error) {
+	report := &Report{DryRun: imp.DryRun}
+
+	journal := imp.Journal
+	if journal == nil {
+		journal = &Journal{Created: make(map[string]int), Linked: make(map[string]int)}
+	}
+	if journal.productId != 0 && journal.productId != imp.ProductId {
+		return report, fmt.Errorf("importer: journal belongs to product %v, not %v", journal.productId, imp.ProductId)
+	}
+
+	if err := imp.resolveAssignees(rows); err != nil {
+		return report, err
+	}
+
+	// First pass, create the items.
+	numbers := make(map[string]int, len(rows))
+	for _, row := range rows {
+		res := &Result{Row: row}
+		if number, ok := journal.Created[row.Key]; ok {
+			res.Number = number
+			res.Resumed = true
+		} else if !imp.DryRun {
+			item, _, err := imp.Client.Items.Create(imp.ProductId, &row.Args)
+			if err != nil {
+				return report, fmt.Errorf("importer: line %v: %v", row.Line, err)
+			}
+			res.Number = item.Number
+
+			err = journal.record(&journalEntry{ProductId: imp.ProductId, Key: row.Key, Number: item.Number})
+			if err != nil {
+				return report, err
+			}
+		}
+		numbers[row.Key] = res.Number
+		imp.progress(&report.Created, res)
+	}
+
+	// Second pass, link the children to their parents.
+	for _, row := range rows {
+		if row.Parent == "" {
+			continue
+		}
+		res := &Result{Row: row, Number: numbers[row.Key], Parent: numbers[row.Parent]}
+		if _, ok := journal.Linked[row.Key]; ok {
+			res.Resumed = true
+		} else if !imp.DryRun {
+			args := &sprintly.ItemUpdateArgs{Parent: res.Parent}
+			if _, _, err := imp.Client.Items.Update(imp.ProductId, res.Number, args); err != nil {
+				return report, fmt.Errorf("importer: line %v: %v", row.Line, err)
+			}
+
+			err := journal.record(&journalEntry{ProductId: imp.ProductId, Key: row.Key, Parent: res.Parent})
+			if err != nil {
+				return report, err
+			}
+		}
+		imp.progress(&report.Linked, res)
+	}
+
+	return report, nil
+}
+
+func (imp *Importer) progress(list *[]*Result, res *Result) {
+	*list = append(*list, res)
+	if imp.Progress != nil {
+		imp.Progress(res)
+	}
+}
+
+// resolveAssignees sets AssignedTo for the rows with an assignee.
+func (imp *Importer) resolveAssignees(rows []*Row) error {
+	var needed bool
+	for _, row := range rows {
+		if row.Assignee != "" {
+			needed = true
+			break
+		}
+	}
+	if !needed {
+		return nil
+	}
+
+	users, _, err := imp.Client.People.List(imp.ProductId)
+	if err != nil {
+		return err
+	}
+	people := make(map[string]int, len(users))
+	for _, user := range users {
+		people[strings.ToLower(user.Email)] = user.Id
+	}
+
+	var errs RowErrors
+	for _, row := range rows {
+		if row.Assignee == "" {
+			continue
+		}
+		id, ok := people[strings.ToLower(row.Assignee)]
+		if !ok {
+			errs = append(errs, &RowError{row.Line, fmt.Errorf("unknown assignee %v", row.Assignee)})
+			continue
+		}
+		row.Args.AssignedTo = id
+	}
+	if len(errs) != 0 {
+		return errs
+	}
+	return nil
+}
+
+// Write prints the report in a human-readable form.
+func (report *Report) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+
+	for _, res := range report.Created {
+		fmt.Fprintf(tw, "%v\tline %v\t%v\t%v\n",
+			report.verb("create", "created", res), res.Row.Line, itemRef(res.Number, res.Row.Key), rowTitle(res.Row))
+	}
+	for _, res := range report.Linked {
+		fmt.Fprintf(tw, "%v\tline %v\t%v\tparent %v\n",
+			report.verb("link", "linked", res), res.Row.Line, itemRef(res.Number, res.Row.Key), itemRef(res.Parent, res.Row.Parent))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	var created, linked int
+	for _, res := range report.Created {
+		if !res.Resumed {
+			created++
+		}
+	}
+	for _, res := range report.Linked {
+		if !res.Resumed {
+			linked++
+		}
+	}
+	if report.DryRun {
+		_, err := fmt.Fprintf(w, "\n%v items to be created, %v to be linked to their parents.\n", created, linked)
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\n%v items created, %v linked to their parents.\n", created, linked)
+	return err
+}
+
+func (report *Report) verb(dryRun, done string, res *Result) string {
+	switch {
+	case res.Resumed:
+		return "skip"
+	case report.DryRun:
+		return dryRun
+	default:
+		return done
+	}
+}
+
+// itemRef refers to an item by number, by the row key when not created yet.
+func itemRef(number int, key string) string {
+	if number != 0 {
+		return fmt.Sprintf("#%v", number)
+	}
+	return "key " + key
+}
+
+func rowTitle(row *Row) string {
+	if row.Args.Title != "" {
+		return row.Args.Title
+	}
+	story := sprintly.Story{Who: row.Args.Who, What: row.Args.What, Why: row.Args.Why}
+	return story.String()
+}
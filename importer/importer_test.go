@@ -0,0 +1,263 @@
+package importer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/salsita/go-sprintly/internal/sprintlytest"
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+const testingCSV = `Key,Type,Summary,Score,Assignee,Parent,Tags
+epic,story,"As a user, I want to import items so that I can migrate.",L,joe@joestump.net,,import
+,task,Parse the CSV,s,,epic,"import; csv"
+,defect,Fix quoting,,JOE@joestump.net,epic,
+`
+
+func testingMapping(t *testing.T) Mapping {
+	mapping, err := ParseMapping([]string{"title=Summary"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return mapping
+}
+
+// fakeProduct serves product 1 with Joe as a member,
+// creating the item number failAt fails unless zero.
+type fakeProduct struct {
+	*sprintlytest.Server
+	failAt int
+}
+
+func newFakeProduct(t *testing.T) *fakeProduct {
+	p := &fakeProduct{Server: sprintlytest.NewServer(t)}
+	p.AddPeople(1, sprintly.User{Id: 7, Email: "joe@joestump.net"})
+	p.Intercept = func(r *http.Request) error {
+		if r.Method == "POST" && r.URL.Path == "/products/1/items.json" && len(p.Items(1))+1 == p.failAt {
+			return errors.New("internal error")
+		}
+		return nil
+	}
+	return p
+}
+
+// linked returns the numbers of the items updated, which the importer only does to set the parents.
+func (p *fakeProduct) linked() []string {
+	var numbers []string
+	for _, req := range p.Requests() {
+		if path, ok := strings.CutPrefix(req, "POST /products/1/items/"); ok {
+			numbers = append(numbers, strings.TrimSuffix(path, ".json"))
+		}
+	}
+	return numbers
+}
+
+func TestRead(t *testing.T) {
+	rows, err := Read(strings.NewReader(testingCSV), testingMapping(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("got %v rows, want 3", len(rows))
+	}
+
+	story := rows[0].Args
+	if story.Who != "user" || story.What != "to import items" || story.Why != "I can migrate" || story.Title != "" {
+		t.Errorf("story not parsed from the title: %+v", story)
+	}
+
+	task := rows[1]
+	if !strings.HasPrefix(task.Key, "row-") || task.Parent != "epic" || task.Args.Score != sprintly.ItemScoreSmall {
+		t.Errorf("task row = %+v", task)
+	}
+	if fmt.Sprint(task.Args.Tags) != "[import csv]" {
+		t.Errorf("task tags = %v", task.Args.Tags)
+	}
+
+	// The rows without a key keep theirs when a row is inserted above.
+	edited := strings.Replace(testingCSV, "\n", "\n,task,Inserted,,,,\n", 1)
+	rows2, err := Read(strings.NewReader(edited), testingMapping(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rows2[2].Key != task.Key || rows2[2].Line != 4 {
+		t.Errorf("key changed from %v to %v", task.Key, rows2[2].Key)
+	}
+
+	// Identical rows without a key cannot be told apart.
+	if _, err := Read(strings.NewReader("type,title\ntask,Task\ntask,Task\n"), nil); err == nil {
+		t.Error("identical rows without a key accepted")
+	}
+}
+
+func TestRead_Invalid(t *testing.T) {
+	input := `key,type,title,score,parent
+a,task,,,
+b,epic,Epic,,
+c,task,Task,XXL,
+d,task,Task,,missing
+e,task,Task,,f
+f,task,Task,,e
+`
+	_, err := Read(strings.NewReader(input), nil)
+
+	var errs RowErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected RowErrors, got %v", err)
+	}
+	want := []string{
+		"line 2: title required",
+		`line 3: invalid type "epic"`,
+		`line 4: invalid score "XXL"`,
+		"line 5: unknown parent missing",
+		"line 6: parent cycle",
+		"line 7: parent cycle",
+	}
+	for i, err := range errs {
+		if i >= len(want) || err.Error() != want[i] {
+			t.Errorf("error %v = %v", i, err)
+		}
+	}
+	if len(errs) != len(want) {
+		t.Errorf("got %v errors, want %v", len(errs), len(want))
+	}
+}
+
+func TestImporter_DryRun(t *testing.T) {
+	rows, err := Read(strings.NewReader(testingCSV), testingMapping(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	product := newFakeProduct(t)
+	imp := &Importer{Client: product.Client, ProductId: 1, DryRun: true}
+	report, err := imp.Import(rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(product.Items(1)) != 0 || len(product.linked()) != 0 {
+		t.Errorf("dry run changed the product: %v, %v", product.Items(1), product.linked())
+	}
+
+	var buf bytes.Buffer
+	if err := report.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := `create  line 2  key epic              As a user, I want to import items so that I can migrate.
+create  line 3  key row-307d80a60971  Parse the CSV
+create  line 4  key row-5e4735a26452  Fix quoting
+link    line 3  key row-307d80a60971  parent key epic
+link    line 4  key row-5e4735a26452  parent key epic
+
+3 items to be created, 2 to be linked to their parents.
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestImporter_Resume(t *testing.T) {
+	rows, err := Read(strings.NewReader(testingCSV), testingMapping(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "import.journal")
+	journal, err := OpenJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The third item fails to be created.
+	product := newFakeProduct(t)
+	product.failAt = 3
+	client := product.Client
+	imp := &Importer{Client: client, ProductId: 1, Journal: journal}
+	if _, err := imp.Import(rows); err == nil {
+		t.Fatal("expected the import to fail")
+	}
+	journal.Close()
+
+	if n := len(product.Items(1)); n != 2 {
+		t.Fatalf("created %v items, want 2", n)
+	}
+	if user := product.Item(1, 1).AssignedTo; user == nil || user.Id != 7 {
+		t.Errorf("assignee not resolved: %+v", user)
+	}
+
+	// Simulate a crash in the middle of writing an entry.
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Fprint(f, `{"product": 1, "key": "row-5e4735a26452", "num`)
+	f.Close()
+
+	// A dry run reports the rows created already and leaves the journal as it is.
+	readOnly, err := ReadJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dryRun := &Importer{Client: client, ProductId: 1, Journal: readOnly, DryRun: true}
+	if report, err := dryRun.Import(rows); err != nil || !report.Created[1].Resumed || report.Created[2].Resumed {
+		t.Errorf("dry run did not use the journal: %v", err)
+	}
+	if content, _ := os.ReadFile(path); !bytes.HasSuffix(content, []byte(`"num`)) {
+		t.Error("dry run modified the journal")
+	}
+
+	journal, err = OpenJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer journal.Close()
+
+	product.failAt = 0
+	imp.Journal = journal
+	report, err := imp.Import(rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := len(product.Items(1)); n != 3 {
+		t.Errorf("created %v items in total, want 3", n)
+	}
+	for _, number := range []int{2, 3} {
+		if parent, _ := product.Item(1, number).ParentNumber(); parent != 1 {
+			t.Errorf("#%v linked to %v, want 1", number, parent)
+		}
+	}
+	if !report.Created[0].Resumed || !report.Created[1].Resumed || report.Created[2].Resumed {
+		t.Errorf("resumed rows not skipped")
+	}
+
+	// Running the import once more changes nothing.
+	if _, err := imp.Import(rows); err != nil {
+		t.Fatal(err)
+	}
+	if created, linked := len(product.Items(1)), len(product.linked()); created != 3 || linked != 2 {
+		t.Errorf("import repeated: %v created, %v linked", created, linked)
+	}
+}
+
+func TestImporter_UnknownAssignee(t *testing.T) {
+	rows, err := Read(strings.NewReader("type,title,assignee\ntask,Task,jane@example.com\n"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	product := newFakeProduct(t)
+	imp := &Importer{Client: product.Client, ProductId: 1}
+	if _, err := imp.Import(rows); err == nil || !strings.Contains(err.Error(), "unknown assignee jane@example.com") {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if len(product.Items(1)) != 0 {
+		t.Errorf("items created despite an unknown assignee")
+	}
+}
@@ -0,0 +1,138 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// journalEntry is a single line of the journal.
+//
+// An entry with Number records a created item,
+// an entry with Parent records the parent being set.
+type journalEntry struct {
+	ProductId int    `json:"product"`
+	Key       string `json:"key"`
+	Number    int    `json:"number,omitempty"`
+	Parent    int    `json:"parent,omitempty"`
+}
+
+// Journal records the progress of an import so that it can be resumed.
+//
+// The journal is an append-only JSON Lines file, every entry is synced
+// to the disk before the import moves on. A line cut short by a crash is ignored.
+type Journal struct {
+	// Created maps the row keys to the numbers of the items created.
+	Created map[string]int
+
+	// Linked maps the row keys to the parent numbers already set.
+	Linked map[string]int
+
+	productId int
+	file      *os.File
+}
+
+// OpenJournal opens the journal at the given path, creating it when necessary.
+// The entries recorded by the previous runs are loaded.
+func OpenJournal(path string) (*Journal, error) {
+	journal, content, valid, err := loadJournal(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(valid) != len(content) {
+		if err := os.Truncate(path, int64(len(valid))); err != nil {
+			return nil, err
+		}
+	}
+
+	journal.file, err = os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return journal, nil
+}
+
+// ReadJournal loads the journal at the given path without modifying it,
+// an empty journal is returned when the file does not exist.
+// Nothing is recorded to the file, which makes it suitable for dry runs.
+func ReadJournal(path string) (*Journal, error) {
+	journal, _, _, err := loadJournal(path)
+	return journal, err
+}
+
+// loadJournal loads the entries of the journal at the given path.
+// It returns the file content and its valid part, without a line cut short.
+func loadJournal(path string) (journal *Journal, content, valid []byte, err error) {
+	journal = &Journal{
+		Created: make(map[string]int),
+		Linked:  make(map[string]int),
+	}
+
+	content, err = os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, nil, nil, err
+	}
+
+	// Drop whatever follows the last newline, it is a line cut short.
+	valid = content[:bytes.LastIndexByte(content, '\n')+1]
+
+	scanner := bufio.NewScanner(bytes.NewReader(valid))
+	for scanner.Scan() {
+		var entry journalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, nil, nil, fmt.Errorf("importer: journal %v: %v", path, err)
+		}
+		if err := journal.apply(&entry); err != nil {
+			return nil, nil, nil, fmt.Errorf("importer: journal %v: %v", path, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, nil, err
+	}
+	return journal, content, valid, nil
+}
+
+func (journal *Journal) apply(entry *journalEntry) error {
+	if journal.productId != 0 && journal.productId != entry.ProductId {
+		return fmt.Errorf("entries of products %v and %v mixed", journal.productId, entry.ProductId)
+	}
+	journal.productId = entry.ProductId
+
+	if entry.Number != 0 {
+		journal.Created[entry.Key] = entry.Number
+	}
+	if entry.Parent != 0 {
+		journal.Linked[entry.Key] = entry.Parent
+	}
+	return nil
+}
+
+// record appends the entry and syncs the journal file.
+func (journal *Journal) record(entry *journalEntry) error {
+	if err := journal.apply(entry); err != nil {
+		return err
+	}
+	if journal.file == nil {
+		return nil
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if _, err := journal.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return journal.file.Sync()
+}
+
+// Close closes the journal file.
+func (journal *Journal) Close() error {
+	if journal.file == nil {
+		return nil
+	}
+	return journal.file.Close()
+}
@@ -0,0 +1,42 @@
+// Package errjson encodes the reports carrying an error in JSON,
+// the error being encoded as its message.
+package errjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Marshal encodes v, which must encode as a JSON object, adding the message
+// of err as the error field unless err is nil.
+//
+// The MarshalJSON methods pass themselves converted to a type without the method:
+//
+//	func (report *Report) MarshalJSON() ([]byte, error) {
+//		type plain Report
+//		return errjson.Marshal((*plain)(report), report.Err)
+//	}
+func Marshal(v any, err error) ([]byte, error) {
+	data, merr := json.Marshal(v)
+	if merr != nil || err == nil {
+		return data, merr
+	}
+	if len(data) < 2 || data[0] != '{' || data[len(data)-1] != '}' {
+		return nil, fmt.Errorf("errjson: %T does not encode as an object", v)
+	}
+	msg, merr := json.Marshal(err.Error())
+	if merr != nil {
+		return nil, merr
+	}
+
+	var buf bytes.Buffer
+	buf.Write(data[:len(data)-1])
+	if len(data) > 2 {
+		buf.WriteByte(',')
+	}
+	buf.WriteString(`"error":`)
+	buf.Write(msg)
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
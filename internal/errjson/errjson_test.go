@@ -0,0 +1,31 @@
+package errjson
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMarshal(t *testing.T) {
+	type report struct {
+		Number int `json:"number,omitempty"`
+	}
+	tests := []struct {
+		v    any
+		err  error
+		want string
+	}{
+		{&report{Number: 1}, nil, `{"number":1}`},
+		{&report{Number: 1}, errors.New(`"quoted" failure`), `{"number":1,"error":"\"quoted\" failure"}`},
+		{&report{}, errors.New("failure"), `{"error":"failure"}`},
+	}
+	for _, test := range tests {
+		data, err := Marshal(test.v, test.err)
+		if err != nil || string(data) != test.want {
+			t.Errorf("Marshal(%+v, %v) = %s, %v, want %s", test.v, test.err, data, err, test.want)
+		}
+	}
+
+	if _, err := Marshal([]int{1}, errors.New("failure")); err == nil {
+		t.Error("Marshal accepted a value not encoded as an object")
+	}
+}
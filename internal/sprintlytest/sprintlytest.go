@@ -0,0 +1,506 @@
+// Package sprintlytest provides an in-memory Sprintly API for the tests
+// of the packages built on top of the client.
+//
+// The server keeps the items, people, comments and deploys of any number
+// of products and implements the endpoints the client uses on them:
+//
+//	s := sprintlytest.NewServer(t)
+//	s.AddPeople(1, sprintly.User{Id: 7, Email: "joe@joestump.net"})
+//	s.AddItems(1, sprintly.Item{Number: 10, Type: "task", Title: "Login"})
+//	item, _, err := s.Client.Items.Get(1, 10)
+//
+// Every change of an item bumps its LastModified by a second of the server clock.
+// Intercept makes requests fail, or changes the data before they are served.
+package sprintlytest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+// Epoch is the time the server clock starts at.
+var Epoch = time.Date(2015, 3, 1, 10, 0, 0, 0, time.UTC)
+
+// Server is an in-memory Sprintly API.
+type Server struct {
+	// Client is a client connected to the server.
+	Client *sprintly.Client
+
+	// Mux serves the API, handlers of other endpoints can be added to it.
+	Mux *http.ServeMux
+
+	// Intercept, when set, is called before a request is served, without
+	// the server being locked. The request fails with 500 Internal Server Error
+	// when it returns an error. The form of the request is parsed already.
+	Intercept func(r *http.Request) error
+
+	mu       sync.Mutex
+	products map[int]*product
+	clock    time.Time
+	requests []string
+}
+
+type product struct {
+	items    map[int]*sprintly.Item
+	people   []sprintly.User
+	comments map[int][]sprintly.Comment
+	deploys  []sprintly.Deploy
+}
+
+// NewServer starts a server closed when the test finishes.
+func NewServer(t testing.TB) *Server {
+	s := &Server{
+		Mux:      http.NewServeMux(),
+		products: make(map[int]*product),
+		clock:    Epoch,
+	}
+	s.handle("GET /products/{product}/items.json", s.listItems)
+	s.handle("POST /products/{product}/items.json", s.createItem)
+	s.handle("GET /products/{product}/items/{number}", s.getItem)
+	s.handle("POST /products/{product}/items/{number}", s.updateItem)
+	s.handle("DELETE /products/{product}/items/{number}", s.archiveItem)
+	s.handle("GET /products/{product}/items/{number}/children.json", s.listChildren)
+	s.handle("GET /products/{product}/items/{number}/comments.json", s.listComments)
+	s.handle("POST /products/{product}/items/{number}/comments.json", s.createComment)
+	s.handle("GET /products/{product}/people.json", s.listPeople)
+	s.handle("POST /products/{product}/people.json", s.invite)
+	s.handle("DELETE /products/{product}/people/{user}", s.removePerson)
+	s.handle("GET /products/{product}/deploys.json", s.listDeploys)
+
+	server := httptest.NewServer(s.Mux)
+	t.Cleanup(server.Close)
+
+	s.Client = sprintly.NewClient("krtecek", "secret")
+	s.Client.SetBaseURL(server.URL)
+	return s
+}
+
+// handler serves a request with the server locked, returning the value
+// to encode as the response.
+type handler func(p *product, r *http.Request) (any, error)
+
+// errNotFound makes the request fail with 404 Not Found.
+var errNotFound = fmt.Errorf("not found")
+
+func (s *Server) handle(pattern string, h handler) {
+	s.Mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if s.Intercept != nil {
+			if err := s.Intercept(r); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.requests = append(s.requests, r.Method+" "+r.URL.RequestURI())
+		productId, _ := strconv.Atoi(r.PathValue("product"))
+		v, err := h(s.product(productId), r)
+		switch {
+		case err == errNotFound:
+			http.NotFound(w, r)
+		case err != nil:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case v != nil:
+			json.NewEncoder(w).Encode(v)
+		}
+	})
+}
+
+// product returns the product, creating it when necessary. The server must be locked.
+func (s *Server) product(productId int) *product {
+	p, ok := s.products[productId]
+	if !ok {
+		p = &product{
+			items:    make(map[int]*sprintly.Item),
+			comments: make(map[int][]sprintly.Comment),
+		}
+		s.products[productId] = p
+	}
+	return p
+}
+
+// tick advances the server clock by a second. The server must be locked.
+func (s *Server) tick() *time.Time {
+	s.clock = s.clock.Add(time.Second)
+	t := s.clock
+	return &t
+}
+
+// AddItems stores the items in the product, replacing those with the same numbers.
+// The items without LastModified get the current time of the server clock.
+func (s *Server) AddItems(productId int, items ...sprintly.Item) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := s.product(productId)
+	for _, item := range items {
+		stored := cloneItem(&item)
+		if stored.LastModified == nil {
+			stored.LastModified = s.tick()
+		}
+		p.items[item.Number] = stored
+	}
+}
+
+// PutItem stores the item in the product, bumping its LastModified.
+func (s *Server) PutItem(productId int, item sprintly.Item) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored := cloneItem(&item)
+	stored.LastModified = s.tick()
+	s.product(productId).items[item.Number] = stored
+}
+
+// Touch bumps the LastModified of the item, as if someone else modified it.
+func (s *Server) Touch(productId, number int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if item, ok := s.product(productId).items[number]; ok {
+		item.LastModified = s.tick()
+	}
+}
+
+// DeleteItem removes the item from the product completely.
+func (s *Server) DeleteItem(productId, number int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.product(productId).items, number)
+}
+
+// Item returns a copy of the item, nil when there is none.
+func (s *Server) Item(productId, number int) *sprintly.Item {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, ok := s.product(productId).items[number]
+	if !ok {
+		return nil
+	}
+	return cloneItem(item)
+}
+
+// Items returns copies of the items of the product, archived ones included, ordered by number.
+func (s *Server) Items(productId int) []sprintly.Item {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var items []sprintly.Item
+	for _, item := range s.product(productId).sortedItems() {
+		items = append(items, *cloneItem(item))
+	}
+	return items
+}
+
+// AddPeople adds the members of the product.
+func (s *Server) AddPeople(productId int, people ...sprintly.User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := s.product(productId)
+	p.people = append(p.people, people...)
+}
+
+// People returns the members of the product.
+func (s *Server) People(productId int) []sprintly.User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.product(productId).people)
+}
+
+// AddComments adds the comments of the item.
+func (s *Server) AddComments(productId, number int, comments ...sprintly.Comment) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := s.product(productId)
+	p.comments[number] = append(p.comments[number], comments...)
+}
+
+// Comments returns the comments of the item.
+func (s *Server) Comments(productId, number int) []sprintly.Comment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.product(productId).comments[number])
+}
+
+// AddDeploys adds the deploys of the product, the most recent ones are expected first.
+func (s *Server) AddDeploys(productId int, deploys ...sprintly.Deploy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := s.product(productId)
+	p.deploys = append(p.deploys, deploys...)
+}
+
+// Requests returns the requests served so far as "METHOD /path?query".
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.requests)
+}
+
+func (p *product) sortedItems() []*sprintly.Item {
+	items := make([]*sprintly.Item, 0, len(p.items))
+	for _, item := range p.items {
+		items = append(items, item)
+	}
+	slices.SortFunc(items, func(a, b *sprintly.Item) int { return a.Number - b.Number })
+	return items
+}
+
+// listItems filters the items by type, status, tags, assignee and creator.
+// Archived items are not listed. The children are listed whether requested or not.
+func (s *Server) listItems(p *product, r *http.Request) (any, error) {
+	q := r.Form
+	items := []*sprintly.Item{}
+	for _, item := range p.sortedItems() {
+		if item.Archived ||
+			!matches(q.Get("type"), item.Type) ||
+			!matches(q.Get("status"), string(item.Status)) ||
+			!matchesUser(q.Get("assigned_to"), item.AssignedTo) ||
+			!matchesUser(q.Get("created_by"), item.CreatedBy) {
+			continue
+		}
+		if tags := q.Get("tags"); tags != "" && !slices.ContainsFunc(strings.Split(tags, ","), func(tag string) bool {
+			return slices.Contains(item.Tags, tag)
+		}) {
+			continue
+		}
+		items = append(items, item)
+	}
+	if q.Get("order_by") == string(sprintly.ItemOrderingRecent) {
+		slices.SortStableFunc(items, func(a, b *sprintly.Item) int {
+			return b.LastModified.Compare(*a.LastModified)
+		})
+	}
+
+	offset, _ := strconv.Atoi(q.Get("offset"))
+	offset = min(offset, len(items))
+	limit, err := strconv.Atoi(q.Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = 100
+	}
+	return items[offset:min(offset+limit, len(items))], nil
+}
+
+// matches reports whether value is one of the comma-separated values of filter,
+// an empty filter matches everything.
+func matches(filter, value string) bool {
+	return filter == "" || slices.Contains(strings.Split(filter, ","), value)
+}
+
+func matchesUser(filter string, user *sprintly.User) bool {
+	return filter == "" || user != nil && filter == strconv.Itoa(user.Id)
+}
+
+func (s *Server) item(p *product, r *http.Request) (*sprintly.Item, error) {
+	number, _ := strconv.Atoi(strings.TrimSuffix(r.PathValue("number"), ".json"))
+	item, ok := p.items[number]
+	if !ok {
+		return nil, errNotFound
+	}
+	return item, nil
+}
+
+func (s *Server) getItem(p *product, r *http.Request) (any, error) {
+	return s.item(p, r)
+}
+
+func (s *Server) createItem(p *product, r *http.Request) (any, error) {
+	number := 1
+	for n := range p.items {
+		number = max(number, n+1)
+	}
+	item := &sprintly.Item{
+		Number:    number,
+		Status:    sprintly.ItemStatusBacklog,
+		CreatedAt: s.tick(),
+		ShortURL:  fmt.Sprintf("https://sprint.ly/i/%v/%v", r.PathValue("product"), number),
+	}
+	item.LastModified = item.CreatedAt
+	if err := applyForm(p, item, r); err != nil {
+		return nil, err
+	}
+	p.items[number] = item
+	return item, nil
+}
+
+func (s *Server) updateItem(p *product, r *http.Request) (any, error) {
+	item, err := s.item(p, r)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyForm(p, item, r); err != nil {
+		return nil, err
+	}
+	item.LastModified = s.tick()
+	return item, nil
+}
+
+// applyForm sets the item fields posted, an empty assigned_to clears the assignee.
+func applyForm(p *product, item *sprintly.Item, r *http.Request) error {
+	form := r.PostForm
+	for field, set := range map[string]func(string){
+		"type":        func(v string) { item.Type = v },
+		"title":       func(v string) { item.Title = v },
+		"who":         func(v string) { item.Who = v },
+		"what":        func(v string) { item.What = v },
+		"why":         func(v string) { item.Why = v },
+		"description": func(v string) { item.Description = v },
+		"score":       func(v string) { item.Score = sprintly.ItemScore(v) },
+		"status":      func(v string) { item.Status = sprintly.ItemStatus(v) },
+		"tags":        func(v string) { item.Tags = strings.Split(v, ",") },
+	} {
+		if _, ok := form[field]; ok {
+			set(form.Get(field))
+		}
+	}
+	if _, ok := form["assigned_to"]; ok {
+		item.AssignedTo = nil
+		if id := form.Get("assigned_to"); id != "" {
+			userId, err := strconv.Atoi(id)
+			if err != nil {
+				return fmt.Errorf("invalid assigned_to %q", id)
+			}
+			user := sprintly.User{Id: userId}
+			if i := slices.IndexFunc(p.people, func(u sprintly.User) bool { return u.Id == userId }); i != -1 {
+				user = p.people[i]
+			}
+			item.AssignedTo = &user
+		}
+	}
+	if parent := form.Get("parent"); parent != "" {
+		number, err := strconv.Atoi(parent)
+		if err != nil {
+			return fmt.Errorf("invalid parent %q", parent)
+		}
+		item.Parent = float64(number)
+	}
+	return nil
+}
+
+func (s *Server) archiveItem(p *product, r *http.Request) (any, error) {
+	item, err := s.item(p, r)
+	if err != nil {
+		return nil, err
+	}
+	item.Archived = true
+	item.LastModified = s.tick()
+	return item, nil
+}
+
+func (s *Server) listChildren(p *product, r *http.Request) (any, error) {
+	parent, err := s.item(p, r)
+	if err != nil {
+		return nil, err
+	}
+	children := []*sprintly.Item{}
+	for _, item := range p.sortedItems() {
+		if number, _ := item.ParentNumber(); number == parent.Number && !item.Archived {
+			children = append(children, item)
+		}
+	}
+	return children, nil
+}
+
+func (s *Server) listComments(p *product, r *http.Request) (any, error) {
+	item, err := s.item(p, r)
+	if err != nil {
+		return nil, err
+	}
+	return append([]sprintly.Comment{}, p.comments[item.Number]...), nil
+}
+
+func (s *Server) createComment(p *product, r *http.Request) (any, error) {
+	item, err := s.item(p, r)
+	if err != nil {
+		return nil, err
+	}
+	comment := sprintly.Comment{Body: r.PostForm.Get("body"), CreatedAt: s.tick()}
+	p.comments[item.Number] = append(p.comments[item.Number], comment)
+	return comment, nil
+}
+
+func (s *Server) listPeople(p *product, r *http.Request) (any, error) {
+	return append([]sprintly.User{}, p.people...), nil
+}
+
+// invite adds the person to the product, restoring the access when revoked.
+func (s *Server) invite(p *product, r *http.Request) (any, error) {
+	form := r.PostForm
+	email := form.Get("email")
+	if email == "" {
+		return nil, fmt.Errorf("email required")
+	}
+	i := slices.IndexFunc(p.people, func(u sprintly.User) bool { return strings.EqualFold(u.Email, email) })
+	if i == -1 {
+		id := 1
+		for _, product := range s.products {
+			for _, user := range product.people {
+				id = max(id, user.Id+1)
+			}
+		}
+		p.people = append(p.people, sprintly.User{Id: id, Email: email})
+		i = len(p.people) - 1
+	}
+	user := &p.people[i]
+	user.FirstName = form.Get("first_name")
+	user.LastName = form.Get("last_name")
+	user.Admin = form.Get("admin") == "true" || form.Get("admin") == "1"
+	user.Revoked = false
+	return user, nil
+}
+
+func (s *Server) removePerson(p *product, r *http.Request) (any, error) {
+	userId, _ := strconv.Atoi(strings.TrimSuffix(r.PathValue("user"), ".json"))
+	i := slices.IndexFunc(p.people, func(u sprintly.User) bool { return u.Id == userId })
+	if i == -1 {
+		return nil, errNotFound
+	}
+	p.people = slices.Delete(p.people, i, i+1)
+	return nil, nil
+}
+
+func (s *Server) listDeploys(p *product, r *http.Request) (any, error) {
+	deploys := []sprintly.Deploy{}
+	for _, deploy := range p.deploys {
+		if matches(r.Form.Get("environment"), deploy.Environment) {
+			deploys = append(deploys, deploy)
+		}
+	}
+	return deploys, nil
+}
+
+// cloneItem returns a copy of the item sharing nothing mutable with the original.
+func cloneItem(item *sprintly.Item) *sprintly.Item {
+	clone := *item
+	clone.Tags = slices.Clone(item.Tags)
+	if item.AssignedTo != nil {
+		user := *item.AssignedTo
+		clone.AssignedTo = &user
+	}
+	if item.LastModified != nil {
+		t := *item.LastModified
+		clone.LastModified = &t
+	}
+	return &clone
+}
@@ -0,0 +1,46 @@
+package sprintlytest
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+func TestServer(t *testing.T) {
+	s := NewServer(t)
+	s.AddPeople(1, sprintly.User{Id: 7, Email: "joe@joestump.net"})
+	s.AddItems(1,
+		sprintly.Item{Number: 1, Type: "task", Status: sprintly.ItemStatusBacklog},
+		sprintly.Item{Number: 2, Type: "task", Status: sprintly.ItemStatusAccepted},
+	)
+
+	items, _, err := s.Client.Items.List(1, &sprintly.ItemListArgs{Status: []sprintly.ItemStatus{sprintly.ItemStatusBacklog}})
+	if err != nil || len(items) != 1 || items[0].Number != 1 {
+		t.Errorf("List = %+v, %v", items, err)
+	}
+
+	item, _, err := s.Client.Items.Create(1, &sprintly.ItemCreateArgs{Type: "defect", Title: "Crash", AssignedTo: 7})
+	if err != nil || item.Number != 3 || item.AssignedTo == nil || item.AssignedTo.Email != "joe@joestump.net" {
+		t.Errorf("Create = %+v, %v", item, err)
+	}
+
+	before := *s.Item(1, 1).LastModified
+	if _, _, err := s.Client.Items.Update(1, 1, &sprintly.ItemUpdateArgs{Parent: 3}); err != nil {
+		t.Fatal(err)
+	}
+	if item := s.Item(1, 1); !item.LastModified.After(before) || item.Parent != float64(3) {
+		t.Errorf("updated item = %+v", item)
+	}
+
+	s.Intercept = func(r *http.Request) error {
+		if r.Method == "DELETE" {
+			return errors.New("read only")
+		}
+		return nil
+	}
+	if _, err := s.Client.People.Remove(1, 7); err == nil || len(s.People(1)) != 1 {
+		t.Errorf("Remove was not intercepted, err = %v", err)
+	}
+}
@@ -0,0 +1,155 @@
+// Package manifest makes it possible to manage Sprintly items declaratively.
+//
+// The items are defined in a YAML manifest, every item having a stable local key:
+//
+//	items:
+//	  - key: release
+//	    type: story
+//	    who: release manager
+//	    what: a release checklist
+//	    why: nothing gets forgotten
+//	    tags: [release]
+//	  - key: release-notes
+//	    parent: release
+//	    type: task
+//	    title: Write the release notes
+//	    assignee: joe@example.com
+//	    score: S
+//
+// Plan compares the manifest with the product items and returns the actions
+// needed to bring the product in sync, Apply then carries them out.
+// The mapping between the local keys and the item numbers is kept in a State.
+//
+// Only the fields set in the manifest are managed, empty fields are left alone.
+package manifest
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+// Manifest represents a manifest file.
+type Manifest struct {
+	Items []*ItemDef `yaml:"items"`
+}
+
+// ItemDef represents a single item definition.
+type ItemDef struct {
+	// Key is the local key identifying the item.
+	Key string `yaml:"key"`
+
+	// Parent is the key of the parent item.
+	Parent string `yaml:"parent,omitempty"`
+
+	Type        string              `yaml:"type"`
+	Title       string              `yaml:"title,omitempty"`
+	Who         string              `yaml:"who,omitempty"`
+	What        string              `yaml:"what,omitempty"`
+	Why         string              `yaml:"why,omitempty"`
+	Description string              `yaml:"description,omitempty"`
+	Score       sprintly.ItemScore  `yaml:"score,omitempty"`
+	Status      sprintly.ItemStatus `yaml:"status,omitempty"`
+	Tags        []string            `yaml:"tags,omitempty"`
+
+	// Assignee is the email of the person the item is assigned to.
+	Assignee string `yaml:"assignee,omitempty"`
+}
+
+// Load reads and validates the manifest file at the given path.
+func Load(path string) (*Manifest, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(content)
+}
+
+// Parse parses and validates the given manifest.
+func Parse(content []byte) (*Manifest, error) {
+	var m Manifest
+	if err := yaml.UnmarshalStrict(content, &m); err != nil {
+		return nil, err
+	}
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// Validate checks that the keys are unique, that the parents exist
+// and that the items are complete enough to be created.
+func (m *Manifest) Validate() error {
+	defs := make(map[string]*ItemDef, len(m.Items))
+	for i, def := range m.Items {
+		if def.Key == "" {
+			return fmt.Errorf("manifest: item %v: key missing", i)
+		}
+		if _, ok := defs[def.Key]; ok {
+			return fmt.Errorf("manifest: %v: duplicate key", def.Key)
+		}
+		defs[def.Key] = def
+
+		switch sprintly.ItemType(def.Type) {
+		case sprintly.ItemTypeStory:
+			if def.Who == "" || def.What == "" || def.Why == "" {
+				return fmt.Errorf("manifest: %v: who, what and why required for stories", def.Key)
+			}
+		case sprintly.ItemTypeTask, sprintly.ItemTypeDefect, sprintly.ItemTypeTest:
+			if def.Title == "" {
+				return fmt.Errorf("manifest: %v: title required", def.Key)
+			}
+		default:
+			return fmt.Errorf("manifest: %v: invalid type %q", def.Key, def.Type)
+		}
+	}
+
+	for _, def := range m.Items {
+		if def.Parent == "" {
+			continue
+		}
+		if _, ok := defs[def.Parent]; !ok {
+			return fmt.Errorf("manifest: %v: unknown parent %v", def.Key, def.Parent)
+		}
+		// Detect cycles by following the parents.
+		seen := map[string]bool{def.Key: true}
+		for p := defs[def.Parent]; p != nil; p = defs[p.Parent] {
+			if seen[p.Key] {
+				return fmt.Errorf("manifest: %v: parent cycle", def.Key)
+			}
+			seen[p.Key] = true
+		}
+	}
+	return nil
+}
+
+// ordered returns the item definitions with parents always preceding their children.
+func (m *Manifest) ordered() []*ItemDef {
+	var (
+		defs    = make(map[string]*ItemDef, len(m.Items))
+		visited = make(map[string]bool, len(m.Items))
+		ordered = make([]*ItemDef, 0, len(m.Items))
+	)
+	for _, def := range m.Items {
+		defs[def.Key] = def
+	}
+
+	var visit func(def *ItemDef)
+	visit = func(def *ItemDef) {
+		if visited[def.Key] {
+			return
+		}
+		visited[def.Key] = true
+		if parent, ok := defs[def.Parent]; ok {
+			visit(parent)
+		}
+		ordered = append(ordered, def)
+	}
+	for _, def := range m.Items {
+		visit(def)
+	}
+	return ordered
+}
@@ -0,0 +1,133 @@
+package manifest
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+var testingManifest = `
+items:
+  - key: release
+    type: story
+    who: release manager
+    what: a release checklist
+    why: nothing gets forgotten
+    score: L
+    tags: [release]
+  - key: release-notes
+    parent: release
+    type: task
+    title: Write the release notes
+    assignee: Joe@joestump.net
+`
+
+func TestParse_Invalid(t *testing.T) {
+	cases := []string{
+		"items:\n  - type: task\n    title: No key",
+		"items:\n  - {key: a, type: task, title: A}\n  - {key: a, type: task, title: B}",
+		"items:\n  - {key: a, type: story, who: me}",
+		"items:\n  - {key: a, type: task}",
+		"items:\n  - {key: a, type: epic, title: A}",
+		"items:\n  - {key: a, type: task, title: A, parent: b}",
+		"items:\n  - {key: a, type: task, title: A, parent: b}\n  - {key: b, type: task, title: B, parent: a}",
+		"items:\n  - {key: a, type: task, title: A, unknown: field}",
+	}
+	for _, content := range cases {
+		if _, err := Parse([]byte(content)); err == nil {
+			t.Errorf("Parse(%q) should have failed", content)
+		}
+	}
+}
+
+func TestPlan(t *testing.T) {
+	m, err := Parse([]byte(testingManifest))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	var requests []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/products/1/people.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"id": 7, "email": "joe@joestump.net"}]`)
+	})
+	mux.HandleFunc("/products/1/items/5.json", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			r.ParseForm()
+			requests = append(requests, "update 5 "+r.PostForm.Encode())
+		}
+		fmt.Fprint(w, `{
+			"number": 5,
+			"type": "story",
+			"title": "As a release manager, I want a release checklist so that nothing gets forgotten.",
+			"who": "release manager",
+			"what": "a release checklist",
+			"why": "nothing gets forgotten",
+			"score": "M",
+			"tags": ["release"]
+		}`)
+	})
+	mux.HandleFunc("/products/1/items.json", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		requests = append(requests, "create "+r.PostForm.Encode())
+		fmt.Fprint(w, `{"number": 6}`)
+	})
+	mux.HandleFunc("/products/1/items/6.json", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		requests = append(requests, "update 6 "+r.PostForm.Encode())
+		fmt.Fprint(w, `{"number": 6}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := sprintly.NewClient("krtecek", "secret")
+	client.SetBaseURL(server.URL)
+
+	state := &State{Items: map[string]int{"release": 5}}
+	plan, err := NewPlan(client, 1, m, state)
+	if err != nil {
+		t.Fatalf("NewPlan failed: %v", err)
+	}
+
+	var out strings.Builder
+	plan.Write(&out)
+	want := `~ release: update #5
+    score: "M" -> "L"
++ release-notes: create task "Write the release notes"
+    parent: release
+
+Plan: 1 to create, 1 to update.
+`
+	if out.String() != want {
+		t.Errorf("Plan.Write printed\n%v\nwant\n%v", out.String(), want)
+	}
+
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	if err := plan.Apply(client, state, func(s *State) error { return s.Save(statePath) }); err != nil {
+		t.Fatalf("Plan.Apply failed: %v", err)
+	}
+
+	wantRequests := []string{
+		"update 5 score=L",
+		"create assigned_to=7&title=Write+the+release+notes&type=task",
+		"update 6 parent=5",
+	}
+	if !reflect.DeepEqual(requests, wantRequests) {
+		t.Errorf("requests = %q, want %q", requests, wantRequests)
+	}
+
+	saved, err := LoadState(statePath)
+	if err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+	wantState := &State{ProductId: 1, Items: map[string]int{"release": 5, "release-notes": 6}}
+	if !reflect.DeepEqual(saved, wantState) {
+		t.Errorf("state = %+v, want %+v", saved, wantState)
+	}
+}
@@ -0,0 +1,291 @@
+package manifest
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+type ActionKind string
+
+const (
+	ActionCreate ActionKind = "create"
+	ActionUpdate ActionKind = "update"
+)
+
+// Change represents a single field to be changed by an update.
+type Change struct {
+	Field string
+	Old   string
+	New   string
+}
+
+// Action represents a single item to be created or updated.
+type Action struct {
+	Kind ActionKind
+	Def  *ItemDef
+
+	// ItemNumber is the number of the item to be updated.
+	ItemNumber int
+
+	// Changes lists the fields to be changed by an update.
+	Changes []Change
+}
+
+// Plan represents the actions needed to bring a product in sync with a manifest.
+type Plan struct {
+	ProductId int
+
+	// Actions lists the actions to be carried out, in order.
+	// Items that are up to date are not listed.
+	Actions []*Action
+
+	// people maps lowercase emails to user IDs.
+	people map[string]int
+}
+
+// NewPlan compares the manifest with the items of the given product.
+//
+// The items already created are found using the state, their current
+// version is fetched using Items.Get. The assignees are resolved using People.List.
+func NewPlan(client *sprintly.Client, productId int, m *Manifest, state *State) (*Plan, error) {
+	if state.ProductId != 0 && state.ProductId != productId {
+		return nil, fmt.Errorf("manifest: state belongs to product %v, not %v", state.ProductId, productId)
+	}
+
+	users, _, err := client.People.List(productId)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{
+		ProductId: productId,
+		people:    make(map[string]int, len(users)),
+	}
+	for _, user := range users {
+		plan.people[strings.ToLower(user.Email)] = user.Id
+	}
+
+	for _, def := range m.ordered() {
+		if def.Assignee != "" {
+			if _, ok := plan.people[strings.ToLower(def.Assignee)]; !ok {
+				return nil, fmt.Errorf("manifest: %v: unknown assignee %v", def.Key, def.Assignee)
+			}
+		}
+
+		number, ok := state.Items[def.Key]
+		if !ok {
+			plan.Actions = append(plan.Actions, &Action{Kind: ActionCreate, Def: def})
+			continue
+		}
+
+		item, _, err := client.Items.Get(productId, number)
+		if _, notFound := err.(*sprintly.ErrItems404); notFound {
+			plan.Actions = append(plan.Actions, &Action{Kind: ActionCreate, Def: def})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		changes, err := plan.diff(def, item, state)
+		if err != nil {
+			return nil, err
+		}
+		if len(changes) != 0 {
+			plan.Actions = append(plan.Actions, &Action{
+				Kind:       ActionUpdate,
+				Def:        def,
+				ItemNumber: number,
+				Changes:    changes,
+			})
+		}
+	}
+
+	return plan, nil
+}
+
+// diff returns the fields of the item that differ from the definition.
+func (plan *Plan) diff(def *ItemDef, item *sprintly.Item, state *State) ([]Change, error) {
+	var changes []Change
+	compare := func(field, current, wanted string) {
+		if wanted != "" && current != wanted {
+			changes = append(changes, Change{field, current, wanted})
+		}
+	}
+
+	compare("type", item.Type, def.Type)
+	compare("title", item.Title, def.Title)
+	compare("who", item.Who, def.Who)
+	compare("what", item.What, def.What)
+	compare("why", item.Why, def.Why)
+	compare("description", item.Description, def.Description)
+	compare("score", string(item.Score), string(def.Score))
+	compare("status", string(item.Status), string(def.Status))
+	if def.Tags != nil {
+		compare("tags", joinTags(item.Tags), joinTags(def.Tags))
+	}
+
+	if def.Assignee != "" {
+		var current string
+		if item.AssignedTo != nil {
+			current = strconv.Itoa(item.AssignedTo.Id)
+		}
+		compare("assigned_to", current, strconv.Itoa(plan.people[strings.ToLower(def.Assignee)]))
+	}
+
+	if def.Parent != "" {
+		parent, err := item.ParentNumber()
+		if err != nil {
+			return nil, err
+		}
+		wanted := def.Parent
+		if number, ok := state.Items[def.Parent]; ok {
+			wanted = strconv.Itoa(number)
+		}
+		compare("parent", strconv.Itoa(parent), wanted)
+	}
+
+	return changes, nil
+}
+
+func joinTags(tags []string) string {
+	sorted := append([]string(nil), tags...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ", ")
+}
+
+// Empty returns true when there is nothing to be done.
+func (plan *Plan) Empty() bool {
+	return len(plan.Actions) == 0
+}
+
+// Write prints the plan in a human readable form.
+func (plan *Plan) Write(w io.Writer) error {
+	if plan.Empty() {
+		_, err := fmt.Fprintln(w, "No changes, the items are up to date.")
+		return err
+	}
+
+	var creates, updates int
+	for _, action := range plan.Actions {
+		def := action.Def
+		switch action.Kind {
+		case ActionCreate:
+			creates++
+			title := def.Title
+			if title == "" {
+				title = (&sprintly.Story{Who: def.Who, What: def.What, Why: def.Why}).String()
+			}
+			fmt.Fprintf(w, "+ %v: create %v %q\n", def.Key, def.Type, title)
+			if def.Parent != "" {
+				fmt.Fprintf(w, "    parent: %v\n", def.Parent)
+			}
+
+		case ActionUpdate:
+			updates++
+			fmt.Fprintf(w, "~ %v: update #%v\n", def.Key, action.ItemNumber)
+			for _, change := range action.Changes {
+				fmt.Fprintf(w, "    %v: %q -> %q\n", change.Field, change.Old, change.New)
+			}
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "\nPlan: %v to create, %v to update.\n", creates, updates)
+	return err
+}
+
+// Apply carries out the plan, recording the items created in the state.
+//
+// The save function, when not nil, is called every time the state changes,
+// so that a failed apply can be resumed without creating duplicate items.
+func (plan *Plan) Apply(client *sprintly.Client, state *State, save func(*State) error) error {
+	state.ProductId = plan.ProductId
+	if state.Items == nil {
+		state.Items = make(map[string]int)
+	}
+
+	for _, action := range plan.Actions {
+		def := action.Def
+		switch action.Kind {
+		case ActionCreate:
+			item, _, err := client.Items.Create(plan.ProductId, &sprintly.ItemCreateArgs{
+				Type:        def.Type,
+				Title:       def.Title,
+				Who:         def.Who,
+				What:        def.What,
+				Why:         def.Why,
+				Description: def.Description,
+				Score:       def.Score,
+				Status:      def.Status,
+				AssignedTo:  plan.people[strings.ToLower(def.Assignee)],
+				Tags:        def.Tags,
+			})
+			if err != nil {
+				return fmt.Errorf("manifest: %v: %v", def.Key, err)
+			}
+
+			state.Items[def.Key] = item.Number
+			if save != nil {
+				if err := save(state); err != nil {
+					return err
+				}
+			}
+
+			// The parent cannot be set on create.
+			if def.Parent != "" {
+				args := &sprintly.ItemUpdateArgs{Parent: state.Items[def.Parent]}
+				if _, _, err := client.Items.Update(plan.ProductId, item.Number, args); err != nil {
+					return fmt.Errorf("manifest: %v: %v", def.Key, err)
+				}
+			}
+
+		case ActionUpdate:
+			args := plan.updateArgs(action, state)
+			if _, _, err := client.Items.Update(plan.ProductId, action.ItemNumber, args); err != nil {
+				return fmt.Errorf("manifest: %v: %v", def.Key, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// updateArgs returns the arguments for Items.Update applying the action changes.
+func (plan *Plan) updateArgs(action *Action, state *State) *sprintly.ItemUpdateArgs {
+	var (
+		def  = action.Def
+		args sprintly.ItemUpdateArgs
+	)
+	for _, change := range action.Changes {
+		switch change.Field {
+		case "type":
+			args.Type = def.Type
+		case "title":
+			args.Title = def.Title
+		case "who":
+			args.Who = def.Who
+		case "what":
+			args.What = def.What
+		case "why":
+			args.Why = def.Why
+		case "description":
+			args.Description = def.Description
+		case "score":
+			args.Score = def.Score
+		case "status":
+			args.Status = def.Status
+		case "tags":
+			args.Tags = def.Tags
+		case "assigned_to":
+			args.AssignedTo = plan.people[strings.ToLower(def.Assignee)]
+		case "parent":
+			args.Parent = state.Items[def.Parent]
+		}
+	}
+	return &args
+}
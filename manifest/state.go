@@ -0,0 +1,50 @@
+package manifest
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/salsita/go-sprintly/internal/atomicfile"
+)
+
+// State maps the local item keys to the item numbers in a product.
+type State struct {
+	ProductId int            `json:"product"`
+	Items     map[string]int `json:"items"`
+}
+
+// LoadState reads the state file at the given path.
+// An empty state is returned in case the file does not exist.
+func LoadState(path string) (*State, error) {
+	state := &State{
+		Items: make(map[string]int),
+	}
+
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(content, state); err != nil {
+		return nil, err
+	}
+	if state.Items == nil {
+		state.Items = make(map[string]int)
+	}
+	return state, nil
+}
+
+// Save writes the state into the file at the given path.
+//
+// The file is replaced atomically and synced, so a crash leaves either
+// the previous or the new state, never an empty or half-written file.
+func (state *State) Save(path string) error {
+	content, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(path, append(content, '\n'))
+}
@@ -0,0 +1,446 @@
+// Package mirror keeps a local copy of a Sprintly product that can be queried offline.
+//
+// The product items, people and deploys are stored as JSON Lines snapshots
+// in a directory. The first sync loads all the items, the following syncs
+// only fetch the items modified since the last sync, using ItemOrderingRecent
+// and Item.LastModified. Since the items archived or deleted do not show up
+// as modified, all the items are listed again once in a while to remove them,
+// see Mirror.ReconcileInterval.
+//
+// Syncing is safe to be run from cron: concurrent syncs are prevented using
+// a lock file, the snapshots are replaced atomically and an interrupted full
+// load is resumed where it stopped.
+package mirror
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/salsita/go-sprintly/internal/atomicfile"
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+const (
+	itemsFile   = "items.jsonl"
+	peopleFile  = "people.jsonl"
+	deploysFile = "deploys.jsonl"
+	stateFile   = "state.json"
+	lockFile    = "lock"
+
+	// partialSuffix marks the items file being filled by a full load.
+	partialSuffix = ".partial"
+
+	// pageSize is the number of items fetched per request.
+	pageSize = 100
+)
+
+// DefaultStaleLockAge is the age after which a lock file is considered
+// to be left behind by a crashed sync and is removed.
+const DefaultStaleLockAge = time.Hour
+
+// DefaultReconcileInterval is the time after which a sync lists all the items
+// again instead of the items modified only.
+const DefaultReconcileInterval = 24 * time.Hour
+
+// ErrLocked is returned by Sync when another sync is running.
+var ErrLocked = errors.New("mirror: another sync is running")
+
+// state is the sync checkpoint stored in the mirror directory.
+type state struct {
+	ProductId int `json:"product"`
+
+	// Loaded is true once the full load is done.
+	Loaded bool `json:"loaded"`
+
+	// Offset is the number of items fetched by an unfinished full load.
+	Offset int `json:"offset,omitempty"`
+
+	// LastModified is the latest Item.LastModified seen.
+	LastModified *time.Time `json:"last_modified,omitempty"`
+
+	// SyncedAt is the time the last sync finished.
+	SyncedAt *time.Time `json:"synced_at,omitempty"`
+
+	// ReconciledAt is the time all the items were listed the last time.
+	ReconciledAt *time.Time `json:"reconciled_at,omitempty"`
+}
+
+// Mirror synchronizes a product into a local directory.
+type Mirror struct {
+	Client    *sprintly.Client
+	ProductId int
+
+	// Dir is the directory the snapshots are stored in.
+	Dir string
+
+	// StaleLockAge overrides DefaultStaleLockAge when set.
+	StaleLockAge time.Duration
+
+	// ReconcileInterval overrides DefaultReconcileInterval when set.
+	ReconcileInterval time.Duration
+}
+
+// Sync brings the mirror up to date.
+func (m *Mirror) Sync() (err error) {
+	if err := os.MkdirAll(m.Dir, 0755); err != nil {
+		return err
+	}
+
+	unlock, err := m.lock()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if uerr := unlock(); err == nil {
+			err = uerr
+		}
+	}()
+
+	st, err := m.loadState()
+	if err != nil {
+		return err
+	}
+
+	if st.Loaded {
+		err = m.syncItems(st)
+	} else {
+		err = m.loadItems(st)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := m.syncPeople(); err != nil {
+		return err
+	}
+	if err := m.syncDeploys(); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	st.SyncedAt = &now
+	return m.saveState(st)
+}
+
+// lock creates the lock file and returns the function removing it.
+func (m *Mirror) lock() (func() error, error) {
+	path := m.path(lockFile)
+
+	staleAge := m.StaleLockAge
+	if staleAge == 0 {
+		staleAge = DefaultStaleLockAge
+	}
+	if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > staleAge {
+		os.Remove(path)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if os.IsExist(err) {
+		return nil, ErrLocked
+	}
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintln(file, os.Getpid())
+	file.Close()
+
+	return func() error {
+		return os.Remove(path)
+	}, nil
+}
+
+// loadItems carries out or resumes the full load of the product items.
+func (m *Mirror) loadItems(st *state) error {
+	partial := m.path(itemsFile + partialSuffix)
+	if st.Offset == 0 {
+		os.Remove(partial)
+	} else if err := truncatePartialLine(partial); err != nil {
+		if !os.IsNotExist(err) {
+			return err
+		}
+		// The partial file is gone, start over.
+		st.Offset = 0
+	}
+
+	file, err := os.OpenFile(partial, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	for {
+		items, _, err := m.Client.Items.List(m.ProductId, &sprintly.ItemListArgs{
+			Status:   sprintly.ItemStatuses,
+			Children: true,
+			OrderBy:  sprintly.ItemOrderingOldest,
+			Offset:   st.Offset,
+			Limit:    pageSize,
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := writeLines(file, items); err != nil {
+			return err
+		}
+		if err := file.Sync(); err != nil {
+			return err
+		}
+
+		// Record the progress so that the load can be resumed.
+		for i := range items {
+			st.observe(&items[i])
+		}
+		st.Offset += len(items)
+		if err := m.saveState(st); err != nil {
+			return err
+		}
+
+		if len(items) < pageSize {
+			break
+		}
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	// Pages may overlap when the load was interrupted, dedupe by number.
+	items, err := readLines[sprintly.Item](partial)
+	if err != nil {
+		return err
+	}
+	if err := m.writeItems(items); err != nil {
+		return err
+	}
+	os.Remove(partial)
+
+	st.Loaded = true
+	st.Offset = 0
+	st.reconciled()
+	return m.saveState(st)
+}
+
+// syncItems fetches the items modified since the last sync,
+// or all the items when the mirror is to be reconciled.
+func (m *Mirror) syncItems(st *state) error {
+	interval := m.ReconcileInterval
+	if interval == 0 {
+		interval = DefaultReconcileInterval
+	}
+	if st.ReconciledAt == nil || time.Since(*st.ReconciledAt) >= interval {
+		return m.reconcileItems(st)
+	}
+
+	items, err := readLines[sprintly.Item](m.path(itemsFile))
+	if err != nil {
+		return err
+	}
+
+	// Items modified in the same second as the latest item seen are listed again,
+	// the items listed on several pages are only taken once, the newest first.
+	var (
+		since   = st.LastModified
+		changed []sprintly.Item
+		seen    = make(map[int]bool)
+	)
+	for offset := 0; ; offset += pageSize {
+		page, _, err := m.Client.Items.List(m.ProductId, &sprintly.ItemListArgs{
+			Status:   sprintly.ItemStatuses,
+			Children: true,
+			OrderBy:  sprintly.ItemOrderingRecent,
+			Offset:   offset,
+			Limit:    pageSize,
+		})
+		if err != nil {
+			return err
+		}
+
+		// Stop at the first item modified before the last sync.
+		done := len(page) < pageSize
+		for i := range page {
+			item := &page[i]
+			if since != nil && item.LastModified != nil && item.LastModified.Before(*since) {
+				done = true
+				break
+			}
+			if !seen[item.Number] {
+				seen[item.Number] = true
+				changed = append(changed, *item)
+			}
+		}
+		if done {
+			break
+		}
+	}
+
+	for i := range changed {
+		st.observe(&changed[i])
+	}
+	return m.writeItems(append(items, changed...))
+}
+
+// reconcileItems replaces the mirrored items with all the product items,
+// removing the items archived or deleted in the meantime.
+func (m *Mirror) reconcileItems(st *state) error {
+	items, _, err := m.Client.Items.ListAll(m.ProductId, &sprintly.ItemListArgs{
+		Status:   sprintly.ItemStatuses,
+		Children: true,
+		Limit:    pageSize,
+	})
+	if err != nil {
+		return err
+	}
+
+	for i := range items {
+		st.observe(&items[i])
+	}
+	st.reconciled()
+	return m.writeItems(items)
+}
+
+func (st *state) reconciled() {
+	now := time.Now().UTC()
+	st.ReconciledAt = &now
+}
+
+func (st *state) observe(item *sprintly.Item) {
+	if item.LastModified == nil {
+		return
+	}
+	if st.LastModified == nil || item.LastModified.After(*st.LastModified) {
+		t := *item.LastModified
+		st.LastModified = &t
+	}
+}
+
+// writeItems stores the items, later items replacing earlier items with the same number.
+func (m *Mirror) writeItems(items []sprintly.Item) error {
+	byNumber := make(map[int]sprintly.Item, len(items))
+	for _, item := range items {
+		byNumber[item.Number] = item
+	}
+
+	deduped := make([]sprintly.Item, 0, len(byNumber))
+	for _, item := range byNumber {
+		deduped = append(deduped, item)
+	}
+	sort.Slice(deduped, func(i, j int) bool {
+		return deduped[i].Number < deduped[j].Number
+	})
+
+	return replaceLines(m, itemsFile, deduped)
+}
+
+func (m *Mirror) syncPeople() error {
+	people, _, err := m.Client.People.List(m.ProductId)
+	if err != nil {
+		return err
+	}
+	return replaceLines(m, peopleFile, people)
+}
+
+func (m *Mirror) syncDeploys() error {
+	deploys, _, err := m.Client.Deploys.List(m.ProductId, nil)
+	if err != nil {
+		return err
+	}
+	return replaceLines(m, deploysFile, deploys)
+}
+
+func (m *Mirror) loadState() (*state, error) {
+	st := &state{ProductId: m.ProductId}
+
+	content, err := os.ReadFile(m.path(stateFile))
+	if os.IsNotExist(err) {
+		return st, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, st); err != nil {
+		return nil, err
+	}
+
+	if st.ProductId != m.ProductId {
+		return nil, fmt.Errorf("mirror: %v mirrors product %v, not %v", m.Dir, st.ProductId, m.ProductId)
+	}
+	return st, nil
+}
+
+func (m *Mirror) saveState(st *state) error {
+	content, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(m.path(stateFile), append(content, '\n'))
+}
+
+// replaceLines atomically replaces the given file with the given values as JSON Lines.
+func replaceLines[T any](m *Mirror, name string, values []T) error {
+	return atomicfile.Write(m.path(name), func(w io.Writer) error {
+		return writeLines(w, values)
+	})
+}
+
+func (m *Mirror) path(name string) string {
+	return filepath.Join(m.Dir, name)
+}
+
+// truncatePartialLine removes an incomplete last line left behind by a crash.
+func truncatePartialLine(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return os.Truncate(path, int64(bytes.LastIndexByte(content, '\n')+1))
+}
+
+// writeLines encodes every value as a single JSON line.
+func writeLines[T any](w io.Writer, values []T) error {
+	enc := json.NewEncoder(w)
+	for i := range values {
+		if err := enc.Encode(&values[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readLines decodes the given JSON Lines file. A missing file is treated as an empty one.
+func readLines[T any](path string) ([]T, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var (
+		values []T
+		dec    = json.NewDecoder(file)
+	)
+	for {
+		var v T
+		err := dec.Decode(&v)
+		switch {
+		case err == nil:
+			values = append(values, v)
+		case err == io.EOF:
+			return values, nil
+		case err == io.ErrUnexpectedEOF:
+			// A truncated last line is left by an interrupted full load.
+			return values, nil
+		default:
+			return nil, fmt.Errorf("mirror: %v: %v", path, err)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package mirror
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/salsita/go-sprintly/internal/sprintlytest"
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+// newServer serves product 1 with the given items, a person and a deploy.
+func newServer(t *testing.T, items ...sprintly.Item) *sprintlytest.Server {
+	s := sprintlytest.NewServer(t)
+	s.AddItems(1, items...)
+	s.AddPeople(1, sprintly.User{Id: 1, Email: "joe@joestump.net"})
+	s.AddDeploys(1, sprintly.Deploy{Environment: "staging", Items: []sprintly.Item{{Number: 1}}})
+	return s
+}
+
+// itemRequests returns the ordering and the offset of the item lists requested
+// after the first skip requests.
+func itemRequests(s *sprintlytest.Server, skip int) []string {
+	var requests []string
+	for _, req := range s.Requests()[skip:] {
+		if u, ok := strings.CutPrefix(req, "GET /products/1/items.json?"); ok {
+			q, _ := url.ParseQuery(u)
+			requests = append(requests, q.Get("order_by")+"@"+q.Get("offset"))
+		}
+	}
+	return requests
+}
+
+func modifiedAt(minutes int) *time.Time {
+	t := time.Date(2015, 1, 1, 10, minutes, 0, 0, time.UTC)
+	return &t
+}
+
+func TestMirror_Sync(t *testing.T) {
+	var items []sprintly.Item
+	for n := 1; n <= 150; n++ {
+		modified := modifiedAt(0).Add(time.Duration(n-150) * time.Second)
+		items = append(items, sprintly.Item{
+			Number:       n,
+			Type:         "task",
+			Status:       sprintly.ItemStatusBacklog,
+			LastModified: &modified,
+		})
+	}
+	s := newServer(t, items...)
+	client := s.Client
+
+	m := &Mirror{Client: client, ProductId: 1, Dir: t.TempDir()}
+	if err := m.Sync(); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+
+	store, err := Open(m.Dir)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if n := len(store.Items(nil)); n != 150 {
+		t.Errorf("len(Items) = %v, want 150", n)
+	}
+	if n := len(store.People()); n != 1 {
+		t.Errorf("len(People) = %v, want 1", n)
+	}
+	if n := len(store.Deploys("staging")); n != 1 {
+		t.Errorf("len(Deploys) = %v, want 1", n)
+	}
+
+	// Modify an item, add a new one and modify the latest item seen
+	// within the same second it was seen.
+	item7, item150 := s.Item(1, 7), s.Item(1, 150)
+	item7.Status = sprintly.ItemStatusInProgress
+	item7.LastModified = modifiedAt(5)
+	item150.Type = "test"
+	s.AddItems(1, *item7, *item150, sprintly.Item{
+		Number:       151,
+		Type:         "defect",
+		Status:       sprintly.ItemStatusBacklog,
+		LastModified: modifiedAt(6),
+	})
+	skip := len(s.Requests())
+
+	if err := m.Sync(); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if requests := itemRequests(s, skip); len(requests) != 1 || requests[0] != "recent@" {
+		t.Errorf("incremental sync requests = %v, want [recent@]", requests)
+	}
+
+	store, err = Open(m.Dir)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if n := len(store.Items(nil)); n != 151 {
+		t.Errorf("len(Items) = %v, want 151", n)
+	}
+
+	inProgress := store.Items(&Query{Status: []sprintly.ItemStatus{sprintly.ItemStatusInProgress}})
+	if len(inProgress) != 1 || inProgress[0].Number != 7 {
+		t.Errorf("in-progress items = %v, want item 7 only", inProgress)
+	}
+	if item := store.Item(151); item == nil || item.Type != "defect" {
+		t.Errorf("Item(151) = %v", item)
+	}
+	if item := store.Item(150); item == nil || item.Type != "test" {
+		t.Errorf("Item(150) = %v, the change in the same second was lost", item)
+	}
+}
+
+func TestMirror_Sync_Reconcile(t *testing.T) {
+	var items []sprintly.Item
+	for n := 1; n <= 3; n++ {
+		items = append(items, sprintly.Item{Number: n, Status: sprintly.ItemStatusBacklog, LastModified: modifiedAt(n)})
+	}
+	s := newServer(t, items...)
+	client := s.Client
+
+	m := &Mirror{Client: client, ProductId: 1, Dir: t.TempDir()}
+	if err := m.Sync(); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+
+	// Item 2 is deleted, which an incremental sync does not notice.
+	s.DeleteItem(1, 2)
+	if err := m.Sync(); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if store, _ := Open(m.Dir); store.Item(2) == nil {
+		t.Fatal("item 2 removed by an incremental sync")
+	}
+
+	m.ReconcileInterval = time.Nanosecond
+	if err := m.Sync(); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	store, err := Open(m.Dir)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if n := len(store.Items(&Query{IncludeArchived: true})); n != 2 || store.Item(2) != nil {
+		t.Errorf("item 2 not removed, %v items mirrored", n)
+	}
+}
+
+func TestMirror_Sync_Resume(t *testing.T) {
+	var items []sprintly.Item
+	for n := 1; n <= 120; n++ {
+		items = append(items, sprintly.Item{Number: n, Status: sprintly.ItemStatusBacklog, LastModified: modifiedAt(0)})
+	}
+	s := newServer(t, items...)
+	client := s.Client
+
+	// Simulate a full load interrupted after the first page,
+	// the partial file ending with a truncated line.
+	dir := t.TempDir()
+	state := fmt.Sprintf(`{"product": 1, "offset": %v}`, pageSize)
+	os.WriteFile(filepath.Join(dir, stateFile), []byte(state), 0644)
+
+	partial, _ := os.Create(filepath.Join(dir, itemsFile+partialSuffix))
+	for n := 1; n <= pageSize; n++ {
+		fmt.Fprintf(partial, "{\"number\": %v}\n", n)
+	}
+	fmt.Fprint(partial, `{"numb`)
+	partial.Close()
+
+	m := &Mirror{Client: client, ProductId: 1, Dir: dir}
+	if err := m.Sync(); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if requests := itemRequests(s, 0); len(requests) != 1 || requests[0] != "oldest@100" {
+		t.Errorf("resumed sync requests = %v, want [oldest@100]", requests)
+	}
+
+	store, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if n := len(store.Items(nil)); n != 120 {
+		t.Errorf("len(Items) = %v, want 120", n)
+	}
+}
+
+func TestMirror_Sync_Locked(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, lockFile), []byte("1\n"), 0644)
+
+	m := &Mirror{Client: sprintly.NewClient("krtecek", "secret"), ProductId: 1, Dir: dir}
+	if err := m.Sync(); err != ErrLocked {
+		t.Errorf("Sync returned %v, want ErrLocked", err)
+	}
+}
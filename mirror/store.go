@@ -0,0 +1,165 @@
+package mirror
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+// Store provides offline access to a mirrored product.
+//
+// The snapshots are loaded into memory by Open, so the store does not see
+// syncs that finish later on. Open the store again to get the latest data.
+type Store struct {
+	ProductId int
+
+	// SyncedAt is the time the last sync finished.
+	SyncedAt *time.Time
+
+	items   []sprintly.Item
+	index   map[int]int
+	people  []sprintly.User
+	deploys []sprintly.Deploy
+}
+
+// Open loads the mirror stored in the given directory.
+func Open(dir string) (*Store, error) {
+	var st state
+	content, err := os.ReadFile(filepath.Join(dir, stateFile))
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, &st); err != nil {
+		return nil, err
+	}
+
+	store := &Store{
+		ProductId: st.ProductId,
+		SyncedAt:  st.SyncedAt,
+	}
+	if store.items, err = readLines[sprintly.Item](filepath.Join(dir, itemsFile)); err != nil {
+		return nil, err
+	}
+	if store.people, err = readLines[sprintly.User](filepath.Join(dir, peopleFile)); err != nil {
+		return nil, err
+	}
+	if store.deploys, err = readLines[sprintly.Deploy](filepath.Join(dir, deploysFile)); err != nil {
+		return nil, err
+	}
+
+	store.index = make(map[int]int, len(store.items))
+	for i, item := range store.items {
+		store.index[item.Number] = i
+	}
+	return store, nil
+}
+
+// Query represents the criteria for Store.Items, the empty fields match any item.
+type Query struct {
+	Type       []sprintly.ItemType
+	Status     []sprintly.ItemStatus
+	Tags       []string
+	AssignedTo int
+	CreatedBy  int
+	Parent     int
+
+	// ModifiedSince matches the items modified after the given time.
+	ModifiedSince *time.Time
+
+	// IncludeArchived makes the query match archived items as well.
+	IncludeArchived bool
+}
+
+// Match returns true when the item matches the query.
+// An item matches the tags when it has all of them.
+func (q *Query) Match(item *sprintly.Item) bool {
+	if item.Archived && !q.IncludeArchived {
+		return false
+	}
+	if len(q.Type) != 0 && !slices.Contains(q.Type, sprintly.ItemType(item.Type)) {
+		return false
+	}
+	if len(q.Status) != 0 && !slices.Contains(q.Status, item.Status) {
+		return false
+	}
+	for _, tag := range q.Tags {
+		if !slices.Contains(item.Tags, tag) {
+			return false
+		}
+	}
+	if q.AssignedTo != 0 && (item.AssignedTo == nil || item.AssignedTo.Id != q.AssignedTo) {
+		return false
+	}
+	if q.CreatedBy != 0 && (item.CreatedBy == nil || item.CreatedBy.Id != q.CreatedBy) {
+		return false
+	}
+	if q.Parent != 0 {
+		if parent, err := item.ParentNumber(); err != nil || parent != q.Parent {
+			return false
+		}
+	}
+	if q.ModifiedSince != nil && (item.LastModified == nil || !item.LastModified.After(*q.ModifiedSince)) {
+		return false
+	}
+	return true
+}
+
+// Items returns the items matching the given query, ordered by number.
+// A nil query matches all the items that are not archived.
+func (store *Store) Items(q *Query) []sprintly.Item {
+	if q == nil {
+		q = &Query{}
+	}
+
+	var items []sprintly.Item
+	for i := range store.items {
+		if q.Match(&store.items[i]) {
+			items = append(items, store.items[i])
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Number < items[j].Number
+	})
+	return items
+}
+
+// Item returns the item with the given number, nil if there is no such item.
+func (store *Store) Item(number int) *sprintly.Item {
+	i, ok := store.index[number]
+	if !ok {
+		return nil
+	}
+	item := store.items[i]
+	return &item
+}
+
+// People returns the product members.
+func (store *Store) People() []sprintly.User {
+	return append([]sprintly.User(nil), store.people...)
+}
+
+// Person returns the product member with the given ID, nil if there is no such member.
+func (store *Store) Person(userId int) *sprintly.User {
+	for _, user := range store.people {
+		if user.Id == userId {
+			return &user
+		}
+	}
+	return nil
+}
+
+// Deploys returns the product deploys, optionally only those for the given environment.
+func (store *Store) Deploys(environment string) []sprintly.Deploy {
+	var deploys []sprintly.Deploy
+	for _, deploy := range store.deploys {
+		if environment == "" || deploy.Environment == environment {
+			deploys = append(deploys, deploy)
+		}
+	}
+	return deploys
+}
@@ -0,0 +1,204 @@
+// Package offboard removes a person from Sprintly products without leaving
+// their items stranded.
+//
+// In every product, the items assigned to the person that are not accepted yet
+// are reassigned to another person, or unassigned, a comment explaining the change
+// is left on each of them, and only then the person is removed from the product:
+//
+//	report, err := offboard.Run(client, joe, []int{1, 2}, &offboard.Options{ReassignTo: ondra})
+//
+// A product is left as it is, the person included, once anything fails there,
+// but the other products are still processed.
+package offboard
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/salsita/go-sprintly/bulk"
+	"github.com/salsita/go-sprintly/internal/errjson"
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+// Options configure offboarding.
+type Options struct {
+	// ReassignTo is the user ID of the person taking over the items,
+	// the items are unassigned when not set.
+	ReassignTo int
+
+	// DryRun only reports the items that would be changed, nothing is changed.
+	DryRun bool
+
+	// Bulk configures the updates of the items, its DryRun is ignored.
+	Bulk bulk.Options
+}
+
+// Report describes what was done in every product.
+type Report struct {
+	UserId   int              `json:"user_id"`
+	DryRun   bool             `json:"dry_run"`
+	Products []*ProductReport `json:"products"`
+}
+
+// ProductReport describes what was done in a single product.
+type ProductReport struct {
+	ProductId int `json:"product_id"`
+
+	// Items are the outcomes of the item updates.
+	Items *bulk.Result `json:"items,omitempty"`
+
+	// Commented lists the numbers of the items commented on.
+	Commented []int `json:"commented,omitempty"`
+
+	// Removed is true when the person was removed from the product.
+	Removed bool `json:"removed"`
+
+	Err error `json:"-"`
+}
+
+// MarshalJSON encodes the report including the error message.
+func (report *ProductReport) MarshalJSON() ([]byte, error) {
+	type plain ProductReport
+	return errjson.Marshal((*plain)(report), report.Err)
+}
+
+// Error is returned by Run when offboarding failed in some of the products,
+// the details are in the report.
+type Error struct {
+	Failed []*ProductReport
+}
+
+func (err *Error) Error() string {
+	var msgs []string
+	for _, product := range err.Failed {
+		msgs = append(msgs, fmt.Sprintf("product %v: %v", product.ProductId, product.Err))
+	}
+	return "offboard: " + strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the errors of the products failed.
+func (err *Error) Unwrap() []error {
+	errs := make([]error, len(err.Failed))
+	for i, product := range err.Failed {
+		errs[i] = product.Err
+	}
+	return errs
+}
+
+// Run offboards the person from the given products.
+//
+// The report is returned even when an error is returned. An *Error is returned
+// when some of the products failed.
+func Run(client *sprintly.Client, userId int, productIds []int, opts *Options) (*Report, error) {
+	var o Options
+	if opts != nil {
+		o = *opts
+	}
+	if userId == o.ReassignTo {
+		return nil, fmt.Errorf("offboard: cannot reassign the items of user %v to themselves", userId)
+	}
+
+	report := &Report{UserId: userId, DryRun: o.DryRun}
+	var failed []*ProductReport
+	for _, productId := range productIds {
+		product := &ProductReport{ProductId: productId}
+		if product.Err = offboardProduct(client, userId, product, &o); product.Err != nil {
+			failed = append(failed, product)
+		}
+		report.Products = append(report.Products, product)
+	}
+	if len(failed) != 0 {
+		return report, &Error{failed}
+	}
+	return report, nil
+}
+
+// offboardProduct offboards the person from a single product, filling the report in.
+func offboardProduct(client *sprintly.Client, userId int, report *ProductReport, opts *Options) error {
+	productId := report.ProductId
+
+	people, _, err := client.People.List(productId)
+	if err != nil {
+		return err
+	}
+	user := findUser(people, userId)
+	var target *sprintly.User
+	if opts.ReassignTo != 0 {
+		if target = findUser(people, opts.ReassignTo); target == nil {
+			return fmt.Errorf("user %v is not a member of the product", opts.ReassignTo)
+		}
+	}
+
+	mutate := bulk.Unassign()
+	if target != nil {
+		mutate = bulk.Reassign(target.Id)
+	}
+	bulkOpts := opts.Bulk
+	bulkOpts.DryRun = opts.DryRun
+	sel := &bulk.Selection{Filter: &sprintly.ItemListArgs{
+		AssignedTo: userId,
+		Status: slices.DeleteFunc(slices.Clone(sprintly.ItemStatuses), func(status sprintly.ItemStatus) bool {
+			return status == sprintly.ItemStatusAccepted
+		}),
+		Children: true,
+	}}
+	report.Items, err = bulk.Run(client, productId, sel, mutate, &bulkOpts)
+	if opts.DryRun {
+		return err
+	}
+
+	// The items changed are commented on even when other items or comments failed,
+	// the person is only removed when everything succeeded.
+	errs := []error{err}
+	comment := &sprintly.CommentCreateArgs{Body: reassignedComment(user, userId, target)}
+	for _, outcome := range report.Items.Changed() {
+		if _, _, err := client.Comments.Create(productId, outcome.Number, comment); err != nil {
+			errs = append(errs, fmt.Errorf("commenting on #%v: %w", outcome.Number, err))
+			continue
+		}
+		report.Commented = append(report.Commented, outcome.Number)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	// The person may have been removed already, leaving the items assigned.
+	if user == nil {
+		return nil
+	}
+	if _, err := client.People.Remove(productId, userId); err != nil {
+		return err
+	}
+	report.Removed = true
+	return nil
+}
+
+func findUser(people []sprintly.User, userId int) *sprintly.User {
+	for i := range people {
+		if people[i].Id == userId {
+			return &people[i]
+		}
+	}
+	return nil
+}
+
+// reassignedComment returns the body of the comment explaining the change of the assignee.
+func reassignedComment(user *sprintly.User, userId int, target *sprintly.User) string {
+	who := fmt.Sprintf("user %v", userId)
+	if user != nil {
+		who = displayName(user)
+	}
+	if target == nil {
+		return fmt.Sprintf("Unassigned as %v is leaving the product.", who)
+	}
+	return fmt.Sprintf("Reassigned from %v to %v as %v is leaving the product.", who, displayName(target), who)
+}
+
+func displayName(user *sprintly.User) string {
+	if name := strings.TrimSpace(user.FirstName + " " + user.LastName); name != "" {
+		return name
+	}
+	return user.Email
+}
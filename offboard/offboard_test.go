@@ -0,0 +1,149 @@
+package offboard
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/salsita/go-sprintly/bulk"
+	"github.com/salsita/go-sprintly/internal/sprintlytest"
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+// newServer serves product 1 with Joe (7) and Ondra (8) as members
+// and product 2 with Joe only, Joe being assigned two items in each.
+func newServer(t *testing.T) *sprintlytest.Server {
+	joe := sprintly.User{Id: 7, FirstName: "Joe", LastName: "Stump"}
+	ondra := sprintly.User{Id: 8, Email: "ondra@salsitasoft.com"}
+
+	s := sprintlytest.NewServer(t)
+	s.AddPeople(1, joe, ondra)
+	s.AddPeople(2, joe)
+	s.AddItems(1,
+		sprintly.Item{Number: 10, Type: "task", Title: "Login", Status: "backlog", AssignedTo: &joe},
+		sprintly.Item{Number: 11, Type: "task", Title: "Logout", Status: "in-progress", AssignedTo: &joe},
+		sprintly.Item{Number: 12, Type: "task", Title: "Signup", Status: "accepted", AssignedTo: &joe},
+	)
+	s.AddItems(2,
+		sprintly.Item{Number: 20, Type: "task", Title: "Billing", Status: "backlog", AssignedTo: &joe},
+		sprintly.Item{Number: 21, Type: "task", Title: "Invoices", Status: "completed", AssignedTo: &joe},
+	)
+	return s
+}
+
+// bodies returns the bodies of the comments of the item.
+func bodies(s *sprintlytest.Server, productId, number int) []string {
+	var bodies []string
+	for _, comment := range s.Comments(productId, number) {
+		bodies = append(bodies, comment.Body)
+	}
+	return bodies
+}
+
+func isMember(s *sprintlytest.Server, productId, userId int) bool {
+	return slices.ContainsFunc(s.People(productId), func(user sprintly.User) bool { return user.Id == userId })
+}
+
+func TestRun_Reassign(t *testing.T) {
+	s := newServer(t)
+
+	// Ondra is not a member of product 2, so Joe stays there.
+	report, err := Run(s.Client, 7, []int{1, 2}, &Options{ReassignTo: 8})
+	var oerr *Error
+	if !errors.As(err, &oerr) || len(oerr.Failed) != 1 || oerr.Failed[0].ProductId != 2 {
+		t.Fatalf("Run should have failed for product 2, err = %v", err)
+	}
+
+	if isMember(s, 1, 7) || !isMember(s, 2, 7) {
+		t.Errorf("Joe removed from the wrong products")
+	}
+	for _, number := range []int{10, 11} {
+		if user := s.Item(1, number).AssignedTo; user == nil || user.Id != 8 {
+			t.Errorf("#%v assigned to %+v", number, user)
+		}
+		want := "Reassigned from Joe Stump to ondra@salsitasoft.com as Joe Stump is leaving the product."
+		if comments := bodies(s, 1, number); len(comments) != 1 || comments[0] != want {
+			t.Errorf("#%v comments = %q", number, comments)
+		}
+	}
+	// Accepted items are left as they are.
+	if user := s.Item(1, 12).AssignedTo; user == nil || user.Id != 7 {
+		t.Errorf("#12 assigned to %+v", user)
+	}
+	if user := s.Item(2, 20).AssignedTo; user == nil || user.Id != 7 {
+		t.Errorf("#20 assigned to %+v", user)
+	}
+
+	product := report.Products[0]
+	if !product.Removed || !slices.Equal(product.Commented, []int{10, 11}) || len(product.Items.Changed()) != 2 {
+		t.Errorf("unexpected report: %+v", product)
+	}
+}
+
+func TestRun_Unassign(t *testing.T) {
+	s := newServer(t)
+
+	report, err := Run(s.Client, 7, []int{2}, nil)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if s.Item(2, 20).AssignedTo != nil || s.Item(2, 21).AssignedTo != nil {
+		t.Error("the items were not unassigned")
+	}
+	if comments := bodies(s, 2, 21); len(comments) != 1 || comments[0] != "Unassigned as Joe Stump is leaving the product." {
+		t.Errorf("comments = %q", comments)
+	}
+	if !report.Products[0].Removed || isMember(s, 2, 7) {
+		t.Error("Joe was not removed")
+	}
+}
+
+func TestRun_Failures(t *testing.T) {
+	s := newServer(t)
+	s.AddItems(1, sprintly.Item{Number: 13, Type: "task", Title: "Profile", Status: "backlog", AssignedTo: &sprintly.User{Id: 7}})
+
+	// #13 cannot be updated and #10 cannot be commented on, #11 is still commented on.
+	s.Intercept = func(r *http.Request) error {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/products/1/items/13.json":
+			return errors.New("update unavailable")
+		case r.Method == "POST" && r.URL.Path == "/products/1/items/10/comments.json":
+			return errors.New("comments unavailable")
+		}
+		return nil
+	}
+	report, err := Run(s.Client, 7, []int{1}, nil)
+	var perr *bulk.PartialError
+	if !errors.As(err, &perr) || !strings.Contains(err.Error(), "commenting on #10") {
+		t.Fatalf("Run should have failed for both #13 and #10, err = %v", err)
+	}
+
+	product := report.Products[0]
+	if product.Removed || !isMember(s, 1, 7) {
+		t.Error("Joe was removed despite the failures")
+	}
+	if len(product.Items.Changed()) != 2 || !slices.Equal(product.Commented, []int{11}) {
+		t.Errorf("unexpected report: %+v", product)
+	}
+}
+
+func TestRun_DryRun(t *testing.T) {
+	s := newServer(t)
+
+	report, err := Run(s.Client, 7, []int{1}, &Options{ReassignTo: 8, DryRun: true})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if !isMember(s, 1, 7) || len(s.Comments(1, 10)) != 0 || s.Item(1, 10).AssignedTo.Id != 7 {
+		t.Error("the dry run changed something")
+	}
+	if product := report.Products[0]; len(product.Items.Changed()) != 2 || product.Removed {
+		t.Errorf("unexpected report: %+v", product)
+	}
+
+	if _, err := Run(s.Client, 7, []int{1}, &Options{ReassignTo: 7}); err == nil {
+		t.Error("reassigning to the same person should have failed")
+	}
+}
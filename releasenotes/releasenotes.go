@@ -0,0 +1,162 @@
+// Package releasenotes generates release notes from the items shipped by Sprintly deploys.
+//
+// The deploys of an environment within a range are collected into Notes,
+// stories being listed as features and defects as fixes:
+//
+//	notes, err := releasenotes.Generate(client, productId, &releasenotes.Options{
+//		Environment: "production",
+//		Since:       lastRelease,
+//	})
+//	tmpl, err := releasenotes.Template("markdown")
+//	err = notes.Render(os.Stdout, tmpl)
+//
+// The default Markdown and HTML templates can be replaced using ParseTemplate
+// or ParseHTMLTemplate, the latter escaping the values.
+package releasenotes
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+// Options select the deploys the notes are generated from.
+type Options struct {
+	// Environment is required by Generate, the deploys are not filtered by New.
+	Environment string
+
+	// Since and Until limit the deploy times, Since being exclusive and Until
+	// inclusive, so the time of the previous release can be passed in as Since.
+	// Zero values leave the range open.
+	Since time.Time
+	Until time.Time
+
+	// Last limits the notes to the given number of the most recent deploys in the range.
+	Last int
+
+	// IncludeTasks lists tasks and tests as well, they are hidden by default.
+	IncludeTasks bool
+
+	// Title is passed to the template, "Release notes" when empty.
+	Title string
+}
+
+// Notes represent the release notes.
+type Notes struct {
+	Title       string
+	Environment string
+
+	// Deploys lists the deploys the notes were generated from, oldest first.
+	Deploys []sprintly.Deploy
+
+	// From and To are the times of the first and the last deploy, nil when not known.
+	From *time.Time
+	To   *time.Time
+
+	// Features lists the stories, Fixes the defects and Tasks the tasks and tests.
+	// Items deployed repeatedly are listed once, the items are ordered by number.
+	Features []sprintly.Item
+	Fixes    []sprintly.Item
+	Tasks    []sprintly.Item
+}
+
+// Empty returns true when no items are listed.
+func (notes *Notes) Empty() bool {
+	return len(notes.Features) == 0 && len(notes.Fixes) == 0 && len(notes.Tasks) == 0
+}
+
+// Generate lists the deploys of the environment and generates the notes from them.
+func Generate(client *sprintly.Client, productId int, opts *Options) (*Notes, error) {
+	if opts == nil || opts.Environment == "" {
+		return nil, fmt.Errorf("releasenotes: environment required")
+	}
+
+	deploys, _, err := client.Deploys.List(productId, &sprintly.DeployListArgs{
+		Environment: opts.Environment,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return New(deploys, opts), nil
+}
+
+// New generates the notes from the given deploys.
+//
+// The deploys are ordered by time, the deploys without a time last,
+// and those cannot be selected using Since and Until.
+func New(deploys []sprintly.Deploy, opts *Options) *Notes {
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	notes := &Notes{
+		Title:       opts.Title,
+		Environment: opts.Environment,
+	}
+	if notes.Title == "" {
+		notes.Title = "Release notes"
+	}
+
+	ordered := slices.Clone(deploys)
+	slices.SortStableFunc(ordered, func(a, b sprintly.Deploy) int {
+		switch {
+		case a.CreatedAt == nil && b.CreatedAt == nil:
+			return 0
+		case a.CreatedAt == nil:
+			return 1
+		case b.CreatedAt == nil:
+			return -1
+		}
+		return a.CreatedAt.Compare(*b.CreatedAt)
+	})
+
+	for _, deploy := range ordered {
+		if !opts.Since.IsZero() || !opts.Until.IsZero() {
+			if deploy.CreatedAt == nil {
+				continue
+			}
+			if !opts.Since.IsZero() && !deploy.CreatedAt.After(opts.Since) {
+				continue
+			}
+			if !opts.Until.IsZero() && deploy.CreatedAt.After(opts.Until) {
+				continue
+			}
+		}
+		notes.Deploys = append(notes.Deploys, deploy)
+	}
+	if opts.Last > 0 && len(notes.Deploys) > opts.Last {
+		notes.Deploys = notes.Deploys[len(notes.Deploys)-opts.Last:]
+	}
+
+	if n := len(notes.Deploys); n != 0 {
+		notes.From = notes.Deploys[0].CreatedAt
+		notes.To = notes.Deploys[n-1].CreatedAt
+	}
+
+	// The later deploys carry the more recent item versions.
+	items := make(map[int]sprintly.Item)
+	for _, deploy := range notes.Deploys {
+		for _, item := range deploy.Items {
+			items[item.Number] = item
+		}
+	}
+	for _, item := range items {
+		switch sprintly.ItemType(item.Type) {
+		case sprintly.ItemTypeStory:
+			notes.Features = append(notes.Features, item)
+		case sprintly.ItemTypeDefect:
+			notes.Fixes = append(notes.Fixes, item)
+		default:
+			if opts.IncludeTasks {
+				notes.Tasks = append(notes.Tasks, item)
+			}
+		}
+	}
+	for _, list := range [][]sprintly.Item{notes.Features, notes.Fixes, notes.Tasks} {
+		slices.SortFunc(list, func(a, b sprintly.Item) int { return a.Number - b.Number })
+	}
+
+	return notes
+}
@@ -0,0 +1,196 @@
+package releasenotes
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+func at(d int) *time.Time {
+	t := time.Date(2015, 1, d, 10, 0, 0, 0, time.UTC)
+	return &t
+}
+
+var testingDeploys = []sprintly.Deploy{
+	// The API returns the most recent deploys first.
+	{
+		Environment: "production",
+		CreatedAt:   at(12),
+		Items: []sprintly.Item{
+			{Number: 5, Type: "defect", Title: "Fix <script> escaping", ShortURL: "http://sprint.ly/i/1/5"},
+			{Number: 2, Type: "story", Who: "user", What: "export items", Why: "I can share them"},
+		},
+	},
+	{
+		Environment: "production",
+		CreatedAt:   at(8),
+		Items: []sprintly.Item{
+			{Number: 2, Type: "story", Who: "user", What: "export", Why: "I can share them"},
+			{Number: 3, Type: "task", Title: "Upgrade the database"},
+		},
+	},
+	{
+		Environment: "production",
+		CreatedAt:   at(1),
+		Items: []sprintly.Item{
+			{Number: 1, Type: "story", Title: "Old story"},
+		},
+	},
+}
+
+func numbers(items []sprintly.Item) string {
+	var ns []string
+	for _, item := range items {
+		ns = append(ns, fmt.Sprint(item.Number))
+	}
+	return strings.Join(ns, ",")
+}
+
+func TestNew(t *testing.T) {
+	notes := New(testingDeploys, &Options{Environment: "production", Since: *at(1)})
+
+	if len(notes.Deploys) != 2 || !notes.From.Equal(*at(8)) || !notes.To.Equal(*at(12)) {
+		t.Errorf("unexpected deploy range: %v deploys from %v to %v", len(notes.Deploys), notes.From, notes.To)
+	}
+	if got := numbers(notes.Features); got != "2" {
+		t.Errorf("Features = %v", got)
+	}
+	if notes.Features[0].What != "export items" {
+		t.Errorf("the most recent item version not used: %+v", notes.Features[0])
+	}
+	if got := numbers(notes.Fixes); got != "5" {
+		t.Errorf("Fixes = %v", got)
+	}
+	if len(notes.Tasks) != 0 {
+		t.Errorf("tasks not hidden")
+	}
+
+	// Deploys without a time are ordered last.
+	undated := append([]sprintly.Deploy{{Items: []sprintly.Item{{Number: 9, Type: "defect"}}}}, testingDeploys...)
+	notes = New(undated, nil)
+	if len(notes.Deploys) != 4 || !notes.From.Equal(*at(1)) || notes.To != nil {
+		t.Errorf("unexpected deploy range: %v deploys from %v to %v", len(notes.Deploys), notes.From, notes.To)
+	}
+
+	notes = New(testingDeploys, &Options{Last: 2, Until: *at(8), IncludeTasks: true})
+	if got := numbers(notes.Features) + "|" + numbers(notes.Tasks); got != "1,2|3" {
+		t.Errorf("Features|Tasks = %v", got)
+	}
+}
+
+func TestRender_Markdown(t *testing.T) {
+	notes := New(testingDeploys, &Options{Environment: "production", Since: *at(1), IncludeTasks: true, Title: "Release 1.2"})
+	tmpl, err := Template("markdown")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := notes.Render(&buf, tmpl); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `# Release 1.2
+
+Deployed to production on 2015-01-12.
+
+## Features
+
+- As a user, I want export items so that I can share them. (#2)
+
+## Fixes
+
+- Fix <script> escaping (#5)
+
+## Other changes
+
+- Upgrade the database (#3)
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestRender_HTML(t *testing.T) {
+	notes := New(testingDeploys, &Options{Environment: "production", Since: *at(8)})
+	tmpl, err := Template("html")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := notes.Render(&buf, tmpl); err != nil {
+		t.Fatal(err)
+	}
+
+	html := buf.String()
+	if !strings.Contains(html, `<li>Fix &lt;script&gt; escaping (<a href="http://sprint.ly/i/1/5">#5</a>)</li>`) {
+		t.Errorf("unexpected HTML:\n%v", html)
+	}
+	if strings.Contains(html, "Other changes") {
+		t.Errorf("tasks not hidden:\n%v", html)
+	}
+
+	// Only http and https links are rendered.
+	notes.Fixes[0].ShortURL = "javascript:alert(1)"
+	buf.Reset()
+	if err := notes.Render(&buf, tmpl); err != nil {
+		t.Fatal(err)
+	}
+	if html := buf.String(); strings.Contains(html, "javascript") || !strings.Contains(html, "(#5)") {
+		t.Errorf("unsafe link rendered:\n%v", html)
+	}
+}
+
+func TestRender_Override(t *testing.T) {
+	tmpl, err := ParseTemplate(`{{range .Fixes}}{{.Number}}: {{summary .}}{{end}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := New(testingDeploys, nil).Render(&buf, tmpl); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != "5: Fix <script> escaping" {
+		t.Errorf("got %q", got)
+	}
+
+	if _, err := Template("pdf"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/products/1/deploys.json", func(w http.ResponseWriter, r *http.Request) {
+		if env := r.URL.Query().Get("environment"); env != "production" {
+			t.Errorf("environment = %q", env)
+		}
+		fmt.Fprint(w, `[{"environment": "production", "created_at": "2015-01-12T10:00:00Z",
+			"items": [{"number": 5, "type": "defect", "title": "Fix"}]}]`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := sprintly.NewClient("krtecek", "secret")
+	client.SetBaseURL(server.URL)
+
+	notes, err := Generate(client, 1, &Options{Environment: "production"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := numbers(notes.Fixes); got != "5" {
+		t.Errorf("Fixes = %v", got)
+	}
+
+	if _, err := Generate(client, 1, &Options{}); err == nil {
+		t.Error("expected an error without an environment")
+	}
+}
@@ -0,0 +1,170 @@
+package releasenotes
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+// Funcs are the functions available to the templates on top of the text/template builtins.
+//
+//	summary  the item title, stories rendered as "As a ..., I want ... so that ..."
+//	assignee the full name of the item assignee
+//	date     the date of a *time.Time, empty when nil
+//	link     the item short URL, empty unless it is an http or https URL
+var Funcs = template.FuncMap{
+	"summary":  summary,
+	"assignee": assignee,
+	"date":     date,
+	"link":     link,
+}
+
+// Executor is a parsed template, either a text/template or an html/template one.
+type Executor interface {
+	Execute(w io.Writer, data any) error
+}
+
+const markdownTemplate = `# {{.Title}}
+{{- if .To}}
+
+Deployed to {{.Environment}} on {{date .To}}.
+{{- end}}
+{{- if .Empty}}
+
+Nothing new this time.
+{{- end}}
+{{- with .Features}}
+
+## Features
+{{range .}}
+- {{summary .}} (#{{.Number}})
+{{- end}}
+{{- end}}
+{{- with .Fixes}}
+
+## Fixes
+{{range .}}
+- {{summary .}} (#{{.Number}})
+{{- end}}
+{{- end}}
+{{- with .Tasks}}
+
+## Other changes
+{{range .}}
+- {{summary .}} (#{{.Number}})
+{{- end}}
+{{- end}}
+`
+
+const htmlTemplate = `<h1>{{.Title}}</h1>
+{{- if .To}}
+<p>Deployed to {{.Environment}} on {{date .To}}.</p>
+{{- end}}
+{{- if .Empty}}
+<p>Nothing new this time.</p>
+{{- end}}
+{{- with .Features}}
+<h2>Features</h2>
+<ul>
+{{- range .}}
+<li>{{summary .}} ({{if link .}}<a href="{{link .}}">#{{.Number}}</a>{{else}}#{{.Number}}{{end}})</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- with .Fixes}}
+<h2>Fixes</h2>
+<ul>
+{{- range .}}
+<li>{{summary .}} ({{if link .}}<a href="{{link .}}">#{{.Number}}</a>{{else}}#{{.Number}}{{end}})</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- with .Tasks}}
+<h2>Other changes</h2>
+<ul>
+{{- range .}}
+<li>{{summary .}} ({{if link .}}<a href="{{link .}}">#{{.Number}}</a>{{else}}#{{.Number}}{{end}})</li>
+{{- end}}
+</ul>
+{{- end}}
+`
+
+// Template returns the default template for the given format, markdown or html.
+func Template(format string) (Executor, error) {
+	switch format {
+	case "markdown":
+		return ParseTemplate(markdownTemplate)
+	case "html":
+		return ParseHTMLTemplate(htmlTemplate)
+	default:
+		return nil, fmt.Errorf("releasenotes: unknown format %q", format)
+	}
+}
+
+// ParseTemplate parses a user-supplied text template, the template is executed with *Notes.
+// Nothing is escaped, use ParseHTMLTemplate for HTML output.
+func ParseTemplate(text string) (*template.Template, error) {
+	return template.New("releasenotes").Funcs(Funcs).Parse(text)
+}
+
+// ParseHTMLTemplate parses a user-supplied html/template, the template is executed with *Notes.
+func ParseHTMLTemplate(text string) (*htmltemplate.Template, error) {
+	return htmltemplate.New("releasenotes").Funcs(htmltemplate.FuncMap(Funcs)).Parse(text)
+}
+
+// ParseTemplateFile parses the template in the given file,
+// files with the .html or .htm extension are parsed as HTML templates.
+func ParseTemplateFile(path string) (Executor, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".html", ".htm":
+		return ParseHTMLTemplate(string(content))
+	default:
+		return ParseTemplate(string(content))
+	}
+}
+
+// Render executes the template with the notes.
+func (notes *Notes) Render(w io.Writer, tmpl Executor) error {
+	return tmpl.Execute(w, notes)
+}
+
+func summary(item sprintly.Item) string {
+	if item.Type == string(sprintly.ItemTypeStory) && item.Who != "" {
+		return sprintly.FormatStory(&item)
+	}
+	return item.Title
+}
+
+func assignee(item sprintly.Item) string {
+	if item.AssignedTo == nil {
+		return ""
+	}
+	return strings.TrimSpace(item.AssignedTo.FirstName + " " + item.AssignedTo.LastName)
+}
+
+func date(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
+
+func link(item sprintly.Item) string {
+	u, err := url.Parse(item.ShortURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ""
+	}
+	return item.ShortURL
+}
@@ -0,0 +1,154 @@
+// Package roster keeps the members of Sprintly products in sync with a roster file.
+//
+// The roster lists the people by email, either in YAML:
+//
+//	people:
+//	  - email: joe@example.com
+//	    name: Joe Stump
+//	    admin: true
+//	  - email: ondra@example.com
+//
+// or in a CSV file with the email, name and admin columns. Sync compares the roster
+// with the members of every product, invites the people missing, including
+// those whose access was revoked, and optionally removes the people not listed.
+// The people are removed using the offboard package, so their open items are
+// unassigned or reassigned first. The authenticated user is never removed.
+// The names and the admin flags cannot be changed using the API, so the differences
+// are only reported.
+package roster
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Roster represents a roster file.
+type Roster struct {
+	People []*Person `yaml:"people"`
+}
+
+// Person represents a single person listed in the roster.
+type Person struct {
+	Email string `yaml:"email"`
+	Name  string `yaml:"name,omitempty"`
+	Admin bool   `yaml:"admin,omitempty"`
+}
+
+// FirstName returns the first word of the name.
+func (p *Person) FirstName() string {
+	first, _, _ := strings.Cut(strings.TrimSpace(p.Name), " ")
+	return first
+}
+
+// LastName returns the name without the first word.
+func (p *Person) LastName() string {
+	_, last, _ := strings.Cut(strings.TrimSpace(p.Name), " ")
+	return strings.TrimSpace(last)
+}
+
+// Load reads and validates the roster file at the given path,
+// a file with the .csv extension is read as CSV, any other file as YAML.
+func Load(path string) (*Roster, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return ReadCSV(file)
+	}
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(content)
+}
+
+// Parse parses and validates the given YAML roster.
+func Parse(content []byte) (*Roster, error) {
+	var r Roster
+	if err := yaml.UnmarshalStrict(content, &r); err != nil {
+		return nil, err
+	}
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// ReadCSV reads and validates a CSV roster. The header must contain the email
+// column, the name and admin columns are optional. The headers are matched
+// case-insensitively.
+func ReadCSV(r io.Reader) (*Roster, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("roster: header missing")
+	}
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]int)
+	for i, h := range header {
+		columns[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	if _, ok := columns["email"]; !ok {
+		return nil, fmt.Errorf("roster: column email missing")
+	}
+
+	var roster Roster
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := cr.FieldPos(0)
+		get := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		person := &Person{Email: get("email"), Name: get("name")}
+		if admin := get("admin"); admin != "" {
+			if person.Admin, err = strconv.ParseBool(admin); err != nil {
+				return nil, fmt.Errorf("roster: line %v: invalid admin flag %q", line, admin)
+			}
+		}
+		roster.People = append(roster.People, person)
+	}
+	if err := roster.Validate(); err != nil {
+		return nil, err
+	}
+	return &roster, nil
+}
+
+// Validate checks that every person has a valid email, listed only once.
+func (r *Roster) Validate() error {
+	emails := make(map[string]bool, len(r.People))
+	for i, person := range r.People {
+		if !strings.Contains(person.Email, "@") {
+			return fmt.Errorf("roster: person %v: invalid email %q", i+1, person.Email)
+		}
+		email := strings.ToLower(person.Email)
+		if emails[email] {
+			return fmt.Errorf("roster: %v listed twice", person.Email)
+		}
+		emails[email] = true
+	}
+	return nil
+}
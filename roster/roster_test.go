@@ -0,0 +1,237 @@
+package roster
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/salsita/go-sprintly/internal/sprintlytest"
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+var testingRoster = `
+people:
+  - email: Joe@joestump.net
+    name: Joe Stump
+    admin: true
+  - email: ondra@salsitasoft.com
+    name: Ondrej Kupka
+  - email: new@salsitasoft.com
+    name: New Hire
+  - email: back@salsitasoft.com
+`
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, "team.csv")
+	content := "Email,Name,Admin\njoe@joestump.net,Joe Stump,true\nondra@salsitasoft.com,Ondrej Kupka,\n"
+	if err := os.WriteFile(csvPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r, err := Load(csvPath)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	want := []Person{
+		{Email: "joe@joestump.net", Name: "Joe Stump", Admin: true},
+		{Email: "ondra@salsitasoft.com", Name: "Ondrej Kupka"},
+	}
+	if len(r.People) != 2 || *r.People[0] != want[0] || *r.People[1] != want[1] {
+		t.Errorf("unexpected roster: %+v", r.People)
+	}
+	if first, last := r.People[1].FirstName(), r.People[1].LastName(); first != "Ondrej" || last != "Kupka" {
+		t.Errorf("name split into %q and %q", first, last)
+	}
+
+	yamlPath := filepath.Join(dir, "team.yaml")
+	if err := os.WriteFile(yamlPath, []byte(testingRoster), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if r, err := Load(yamlPath); err != nil || len(r.People) != 4 || !r.People[0].Admin {
+		t.Errorf("Load = %+v, %v", r, err)
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	for _, content := range []string{
+		"people:\n  - name: No Email",
+		"people:\n  - email: a@example.com\n  - email: A@example.com",
+		"people:\n  - email: a@example.com\n    role: developer",
+	} {
+		if _, err := Parse([]byte(content)); err == nil {
+			t.Errorf("Parse accepted %q", content)
+		}
+	}
+	for _, content := range []string{
+		"",
+		"name\nJoe",
+		"email,admin\njoe@joestump.net,maybe",
+	} {
+		if _, err := ReadCSV(strings.NewReader(content)); err == nil {
+			t.Errorf("ReadCSV accepted %q", content)
+		}
+	}
+}
+
+// newServer serves products 1 and 2 with the same members,
+// back@salsitasoft.com cannot be invited to product 2.
+// Item #1 of every product is assigned to left@salsitasoft.com.
+func newServer(t *testing.T) *sprintlytest.Server {
+	s := sprintlytest.NewServer(t)
+	for _, productId := range []int{1, 2} {
+		s.AddPeople(productId,
+			sprintly.User{Id: 1, Email: "joe@joestump.net", FirstName: "Joe", LastName: "Stump"},
+			sprintly.User{Id: 2, Email: "ondra@salsitasoft.com", FirstName: "Ondra", LastName: "Kupka", Admin: true},
+			sprintly.User{Id: 3, Email: "back@salsitasoft.com", Revoked: true},
+			sprintly.User{Id: 4, Email: "left@salsitasoft.com"},
+		)
+		s.AddItems(productId, sprintly.Item{
+			Number:     1,
+			Status:     sprintly.ItemStatusInProgress,
+			AssignedTo: &sprintly.User{Id: 4, Email: "left@salsitasoft.com"},
+		})
+	}
+	s.Intercept = func(r *http.Request) error {
+		if r.Method == "POST" && r.URL.Path == "/products/2/people.json" && r.PostForm.Get("email") == "back@salsitasoft.com" {
+			return errors.New("invitation failed")
+		}
+		return nil
+	}
+	return s
+}
+
+// members returns the emails and first names of the active members of the product.
+func members(s *sprintlytest.Server, productId int) []string {
+	var ms []string
+	for _, user := range s.People(productId) {
+		if !user.Revoked {
+			ms = append(ms, user.Email+"/"+user.FirstName)
+		}
+	}
+	return ms
+}
+
+func kinds(changes []*Change) []string {
+	var ks []string
+	for _, change := range changes {
+		ks = append(ks, string(change.Kind)+" "+change.Email)
+	}
+	return ks
+}
+
+func TestSync(t *testing.T) {
+	s := newServer(t)
+
+	r, err := Parse([]byte(testingRoster))
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := Sync(s.Client, r, []int{1, 2}, &Options{Remove: true})
+	var rerr *Error
+	if !errors.As(err, &rerr) || rerr.Failed != 1 {
+		t.Fatalf("Sync should have failed once, err = %v", err)
+	}
+
+	want := []string{
+		"admin Joe@joestump.net",
+		"admin ondra@salsitasoft.com",
+		"name ondra@salsitasoft.com",
+		"invite new@salsitasoft.com",
+		"invite back@salsitasoft.com",
+		"remove left@salsitasoft.com",
+	}
+	if got := kinds(report.Products[0].Changes); !slices.Equal(got, want) {
+		t.Errorf("changes = %q, want %q", got, want)
+	}
+
+	wantMembers := map[int][]string{
+		1: {"joe@joestump.net/Joe", "ondra@salsitasoft.com/Ondra", "back@salsitasoft.com/", "new@salsitasoft.com/New"},
+		2: {"joe@joestump.net/Joe", "ondra@salsitasoft.com/Ondra", "new@salsitasoft.com/New"},
+	}
+	for productId, want := range wantMembers {
+		if got := members(s, productId); !slices.Equal(got, want) {
+			t.Errorf("product %v members %q, want %q", productId, got, want)
+		}
+	}
+	if change := report.Products[1].Changes[4]; change.Applied || change.Err == nil {
+		t.Errorf("failed invitation reported as %+v", change)
+	}
+
+	// The items of the people removed are unassigned and commented on first.
+	for i, product := range report.Products {
+		change := product.Changes[5]
+		if !change.Applied || change.Offboard == nil || !slices.Equal(change.Offboard.Commented, []int{1}) {
+			t.Errorf("product %v: removal reported as %+v", product.ProductId, change)
+		}
+		if item := s.Item(i+1, 1); item.AssignedTo != nil {
+			t.Errorf("product %v: item #1 still assigned to %v", product.ProductId, item.AssignedTo.Email)
+		}
+		if comments := s.Comments(i+1, 1); len(comments) != 1 {
+			t.Errorf("product %v: item #1 has %v comments, want 1", product.ProductId, len(comments))
+		}
+	}
+}
+
+func TestSync_DryRun(t *testing.T) {
+	s := newServer(t)
+	before := members(s, 1)
+
+	r, err := Parse([]byte(testingRoster))
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := Sync(s.Client, r, []int{1}, &Options{Remove: true, DryRun: true})
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if got := members(s, 1); !slices.Equal(got, before) {
+		t.Errorf("the dry run changed the members to %q", got)
+	}
+	if len(report.Products[0].Changes) != 6 {
+		t.Fatalf("changes = %q", kinds(report.Products[0].Changes))
+	}
+	if change := report.Products[0].Changes[5]; change.Offboard == nil || len(change.Offboard.Items.Changed()) != 1 {
+		t.Errorf("the open items of the person to be removed not reported: %+v", change)
+	}
+	if item := s.Item(1, 1); item.AssignedTo == nil {
+		t.Error("the dry run unassigned item #1")
+	}
+
+	// Without Remove, the people not listed are only reported.
+	report, err = Sync(s.Client, r, []int{1}, nil)
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if !slices.Contains(members(s, 1), "left@salsitasoft.com/") || report.Products[0].Changes[5].Applied {
+		t.Error("removed a person without Remove")
+	}
+}
+
+func TestSync_Self(t *testing.T) {
+	s := newServer(t)
+	s.AddPeople(1, sprintly.User{Id: 5, Email: "Krtecek"})
+
+	r, err := Parse([]byte(testingRoster))
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := Sync(s.Client, r, []int{1}, &Options{Remove: true, ReassignTo: 1})
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+
+	change := report.Products[0].Changes[6]
+	if change.Email != "Krtecek" || !change.Self || change.Applied || change.Offboard != nil {
+		t.Errorf("the authenticated user reported as %+v", change)
+	}
+	if !slices.Contains(members(s, 1), "Krtecek/") {
+		t.Error("removed the authenticated user")
+	}
+	if item := s.Item(1, 1); item.AssignedTo == nil || item.AssignedTo.Id != 1 {
+		t.Errorf("item #1 not reassigned to user 1: %+v", item.AssignedTo)
+	}
+}
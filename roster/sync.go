@@ -0,0 +1,213 @@
+package roster
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/salsita/go-sprintly/internal/errjson"
+	"github.com/salsita/go-sprintly/offboard"
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+// ChangeKind is the kind of a difference between the roster and the product members.
+type ChangeKind string
+
+const (
+	// ChangeInvite is a person missing in the product, or whose access was revoked.
+	ChangeInvite ChangeKind = "invite"
+
+	// ChangeRemove is a product member not listed in the roster.
+	ChangeRemove ChangeKind = "remove"
+
+	// ChangeAdmin is a member whose admin flag differs, only reported.
+	ChangeAdmin ChangeKind = "admin"
+
+	// ChangeName is a member whose name differs, only reported.
+	ChangeName ChangeKind = "name"
+)
+
+// Change is a single difference between the roster and the product members.
+type Change struct {
+	Kind  ChangeKind `json:"kind"`
+	Email string     `json:"email"`
+
+	// Person is the roster entry, nil for ChangeRemove.
+	Person *Person `json:"person,omitempty"`
+
+	// User is the product member, nil for a person missing in the product.
+	User *sprintly.User `json:"user,omitempty"`
+
+	// Applied is true when the person was invited or removed.
+	Applied bool `json:"applied"`
+
+	// Self is true for a ChangeRemove of the authenticated user, who is never removed.
+	Self bool `json:"self,omitempty"`
+
+	// Offboard describes the open items of the person removed, which are unassigned
+	// or reassigned first. It is set for ChangeRemove when Options.Remove is set.
+	Offboard *offboard.ProductReport `json:"offboard,omitempty"`
+
+	Err error `json:"-"`
+}
+
+// MarshalJSON encodes the change including the error message.
+func (change *Change) MarshalJSON() ([]byte, error) {
+	type plain Change
+	return errjson.Marshal((*plain)(change), change.Err)
+}
+
+// Diff returns the differences between the roster and the product members,
+// in the order of the roster followed by the members not listed.
+// The emails are compared case-insensitively.
+func Diff(r *Roster, people []sprintly.User) []*Change {
+	members := make(map[string]*sprintly.User, len(people))
+	for i := range people {
+		members[strings.ToLower(people[i].Email)] = &people[i]
+	}
+
+	var changes []*Change
+	listed := make(map[string]bool, len(r.People))
+	for _, person := range r.People {
+		email := strings.ToLower(person.Email)
+		listed[email] = true
+
+		user, ok := members[email]
+		if !ok || user.Revoked {
+			changes = append(changes, &Change{Kind: ChangeInvite, Email: person.Email, Person: person, User: user})
+			continue
+		}
+		if person.Admin != user.Admin {
+			changes = append(changes, &Change{Kind: ChangeAdmin, Email: person.Email, Person: person, User: user})
+		}
+		if person.Name != "" && (person.FirstName() != user.FirstName || person.LastName() != user.LastName) {
+			changes = append(changes, &Change{Kind: ChangeName, Email: person.Email, Person: person, User: user})
+		}
+	}
+	for i := range people {
+		user := &people[i]
+		if !listed[strings.ToLower(user.Email)] && !user.Revoked {
+			changes = append(changes, &Change{Kind: ChangeRemove, Email: user.Email, User: user})
+		}
+	}
+	return changes
+}
+
+// Options configure Sync.
+type Options struct {
+	// Remove offboards the product members not listed in the roster,
+	// they are only reported otherwise. The authenticated user is never removed.
+	Remove bool
+
+	// ReassignTo is the user ID of the person taking over the open items
+	// of the people removed, the items are unassigned when not set.
+	ReassignTo int
+
+	// DryRun only reports the differences and the open items of the people
+	// to be removed, nobody is invited or removed.
+	DryRun bool
+}
+
+// Report describes the differences found and applied in every product.
+type Report struct {
+	DryRun   bool             `json:"dry_run"`
+	Products []*ProductReport `json:"products"`
+}
+
+// ProductReport describes the differences found and applied in a single product.
+type ProductReport struct {
+	ProductId int       `json:"product_id"`
+	Changes   []*Change `json:"changes"`
+
+	// Err is set when the members of the product could not be listed.
+	Err error `json:"-"`
+}
+
+// MarshalJSON encodes the report including the error message.
+func (report *ProductReport) MarshalJSON() ([]byte, error) {
+	type plain ProductReport
+	return errjson.Marshal((*plain)(report), report.Err)
+}
+
+// Error is returned by Sync when some of the products could not be listed
+// or some of the people could not be invited or removed.
+type Error struct {
+	Failed int
+}
+
+func (err *Error) Error() string {
+	return fmt.Sprintf("roster: %v operations failed, see the report", err.Failed)
+}
+
+// Sync brings the members of the given products in sync with the roster.
+//
+// The people removed are offboarded using offboard.Run, so their open items
+// are unassigned, or reassigned to Options.ReassignTo, and commented on first.
+// The products are processed one by one and a failure does not stop the sync.
+// The report is returned even when an error is returned.
+func Sync(client *sprintly.Client, r *Roster, productIds []int, opts *Options) (*Report, error) {
+	var o Options
+	if opts != nil {
+		o = *opts
+	}
+
+	report := &Report{DryRun: o.DryRun}
+	failed := 0
+	for _, productId := range productIds {
+		product := &ProductReport{ProductId: productId}
+		report.Products = append(report.Products, product)
+
+		people, _, err := client.People.List(productId)
+		if err != nil {
+			product.Err = err
+			failed++
+			continue
+		}
+		product.Changes = Diff(r, people)
+
+		for _, change := range product.Changes {
+			if change.Kind == ChangeRemove && strings.EqualFold(change.Email, client.Username()) {
+				change.Self = true
+			}
+			switch {
+			case change.Kind == ChangeRemove && o.Remove && !change.Self:
+				change.Offboard, change.Err = offboardUser(client, productId, change.User.Id, &o)
+			case o.DryRun:
+				continue
+			case change.Kind == ChangeInvite:
+				_, change.Err = client.People.Invite(productId, &sprintly.Invitation{
+					Email:     change.Person.Email,
+					FirstName: change.Person.FirstName(),
+					LastName:  change.Person.LastName(),
+					Admin:     change.Person.Admin,
+				})
+			default:
+				continue
+			}
+			if change.Err != nil {
+				failed++
+			} else {
+				change.Applied = !o.DryRun
+			}
+		}
+	}
+	if failed != 0 {
+		return report, &Error{Failed: failed}
+	}
+	return report, nil
+}
+
+// offboardUser offboards the person from the product, returning the product report.
+func offboardUser(client *sprintly.Client, productId, userId int, o *Options) (*offboard.ProductReport, error) {
+	report, err := offboard.Run(client, userId, []int{productId}, &offboard.Options{
+		ReassignTo: o.ReassignTo,
+		DryRun:     o.DryRun,
+	})
+	if report == nil {
+		return nil, err
+	}
+	product := report.Products[0]
+	if product.Err != nil {
+		return product, product.Err
+	}
+	return product, nil
+}
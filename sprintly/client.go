@@ -37,6 +37,9 @@ type Client struct {
 
 	// The Deploys service.
 	Deploys *DeploysService
+
+	// The Comments service.
+	Comments *CommentsService
 }
 
 // NewClient returns a new API client instance that uses
@@ -53,9 +56,16 @@ func NewClient(username, token string) *Client {
 	client.People = newPeopleService(client)
 	client.Items = newItemsService(client)
 	client.Deploys = newDeploysService(client)
+	client.Comments = newCommentsService(client)
 	return client
 }
 
+// Username returns the username the API calls are authenticated with,
+// which is the email of the user.
+func (c *Client) Username() string {
+	return c.username
+}
+
 // SetBaseURL can be used to overwrite the default API base URL,
 // which is the Sprintly API - https://sprint.ly/api/.
 func (c *Client) SetBaseURL(baseURL string) error {
@@ -0,0 +1,90 @@
+package sprintly
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// CommentsService holds all the methods for manipulating Sprintly item comments.
+type CommentsService struct {
+	client *Client
+}
+
+func newCommentsService(client *Client) *CommentsService {
+	return &CommentsService{client}
+}
+
+// Comment represents a comment on a Sprintly item.
+type Comment struct {
+	Id           int        `json:"id,omitempty"`
+	Body         string     `json:"body,omitempty"`
+	Type         string     `json:"type,omitempty"`
+	CreatedBy    *User      `json:"created_by,omitempty"`
+	CreatedAt    *time.Time `json:"created_at,omitempty"`
+	LastModified *time.Time `json:"last_modified,omitempty"`
+}
+
+// CommentCreateArgs represent the arguments that can be passed into Comments.Create.
+type CommentCreateArgs struct {
+	Body string `url:"body,omitempty" schema:"body,omitempty"`
+}
+
+// List can be used to list the comments on the given item.
+//
+// See https://sprintly.uservoice.com/knowledgebase/articles/98413-comments
+func (srv CommentsService) List(productId, itemNumber int) ([]Comment, *http.Response, error) {
+	u := fmt.Sprintf("products/%v/items/%v/comments.json", productId, itemNumber)
+
+	req, err := srv.client.NewGetRequest(u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var comments []Comment
+	resp, err := srv.client.Do(req, &comments)
+	if err != nil {
+		if apiErr, ok := err.(*ErrAPI); ok {
+			switch resp.StatusCode {
+			case 404:
+				return nil, nil, &ErrComments404{apiErr}
+			}
+		}
+		return nil, resp, err
+	}
+
+	return comments, resp, nil
+}
+
+// Create can be used to comment on the given item.
+//
+// See https://sprintly.uservoice.com/knowledgebase/articles/98413-comments
+func (srv CommentsService) Create(
+	productId int,
+	itemNumber int,
+	args *CommentCreateArgs,
+) (*Comment, *http.Response, error) {
+
+	u := fmt.Sprintf("products/%v/items/%v/comments.json", productId, itemNumber)
+
+	req, err := srv.client.NewPostRequest(u, args)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var comment Comment
+	resp, err := srv.client.Do(req, &comment)
+	if err != nil {
+		if apiErr, ok := err.(*ErrAPI); ok {
+			switch resp.StatusCode {
+			case 400:
+				return nil, nil, &ErrComments400{apiErr}
+			case 404:
+				return nil, nil, &ErrComments404{apiErr}
+			}
+		}
+		return nil, resp, err
+	}
+
+	return &comment, resp, nil
+}
@@ -0,0 +1,21 @@
+package sprintly
+
+import (
+	"fmt"
+)
+
+type ErrComments400 struct {
+	Err *ErrAPI
+}
+
+func (err *ErrComments400) Error() string {
+	return fmt.Sprintf("%v (comment body missing)", err.Err)
+}
+
+type ErrComments404 struct {
+	Err *ErrAPI
+}
+
+func (err *ErrComments404) Error() string {
+	return fmt.Sprintf("%v (product or item unknown)", err.Err)
+}
@@ -0,0 +1,105 @@
+package sprintly
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+var testingCommentJson = `
+{
+	"id": 4410,
+	"body": "Started on the handler.",
+	"type": "comment",
+	"created_by": {"id": 1, "email": "joe@joestump.net", "first_name": "Joe", "last_name": "Stump"},
+	"created_at": "2013-06-17T09:15:41+00:00"
+}`
+
+func testingComment() *Comment {
+	createdAt, err := time.Parse(time.RFC3339, "2013-06-17T09:15:41+00:00")
+	if err != nil {
+		panic(err)
+	}
+	return &Comment{
+		Id:        4410,
+		Body:      "Started on the handler.",
+		Type:      "comment",
+		CreatedBy: &testingUser,
+		CreatedAt: &createdAt,
+	}
+}
+
+func TestComments_List(t *testing.T) {
+	client, server, mux := setup()
+	defer server.Close()
+
+	mux.HandleFunc("/products/1/items/188/comments.json", func(w http.ResponseWriter, r *http.Request) {
+		ensureMethod(t, r, "GET")
+		fmt.Fprintf(w, "[%v]", testingCommentJson)
+	})
+
+	comments, _, err := client.Comments.List(1, 188)
+	if err != nil {
+		t.Errorf("Comments.List failed: %v", err)
+		return
+	}
+
+	ensureEqual(t, comments, []Comment{*testingComment()})
+}
+
+func TestComments_List_NotFound(t *testing.T) {
+	client, server, mux := setup()
+	defer server.Close()
+
+	mux.HandleFunc("/products/1/items/188/comments.json", func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+
+	_, _, err := client.Comments.List(1, 188)
+	if _, ok := err.(*ErrComments404); !ok {
+		t.Errorf("Comments.List should have failed with *ErrComments404, err = %v", err)
+	}
+}
+
+func TestComments_Create(t *testing.T) {
+	client, server, mux := setup()
+	defer server.Close()
+
+	args := CommentCreateArgs{Body: "Started on the handler."}
+
+	mux.HandleFunc("/products/1/items/188/comments.json", func(w http.ResponseWriter, r *http.Request) {
+		ensureMethod(t, r, "POST")
+
+		var got CommentCreateArgs
+		if err := decodeArgs(&got, r); err != nil {
+			t.Error(err)
+			return
+		}
+
+		ensureEqual(t, &got, &args)
+		fmt.Fprint(w, testingCommentJson)
+	})
+
+	comment, _, err := client.Comments.Create(1, 188, &args)
+	if err != nil {
+		t.Errorf("Comments.Create failed: %v", err)
+		return
+	}
+
+	ensureEqual(t, comment, testingComment())
+}
+
+func TestComments_Create_Invalid(t *testing.T) {
+	client, server, mux := setup()
+	defer server.Close()
+
+	mux.HandleFunc("/products/1/items/188/comments.json", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "body required", http.StatusBadRequest)
+	})
+
+	_, _, err := client.Comments.Create(1, 188, &CommentCreateArgs{})
+	if _, ok := err.(*ErrComments400); !ok {
+		t.Errorf("Comments.Create should have failed with *ErrComments400, err = %v", err)
+	}
+}
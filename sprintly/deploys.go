@@ -3,6 +3,7 @@ package sprintly
 import (
 	"fmt"
 	"net/http"
+	"time"
 )
 
 // DeploysService holds all the methods for manipulating Sprintly items.
@@ -16,8 +17,9 @@ func newDeploysService(client *Client) *DeploysService {
 
 // Deploy represents the Sprintly Deploy resource.
 type Deploy struct {
-	Environment string `json:"environment,omitempty"`
-	Items       []Item `json:"items,omitempty"`
+	Environment string     `json:"environment,omitempty"`
+	Items       []Item     `json:"items,omitempty"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
 }
 
 type DeployListArgs struct {
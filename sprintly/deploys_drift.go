@@ -0,0 +1,125 @@
+package sprintly
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+)
+
+// EnvironmentDrift represents the difference between the items deployed to two environments.
+type EnvironmentDrift struct {
+	From string
+	To   string
+
+	// Ahead lists the items deployed to From, but not to To.
+	Ahead []Item
+
+	// Behind lists the items deployed to To, but not to From.
+	Behind []Item
+}
+
+// InSync returns true when both the environments carry the same items.
+func (drift *EnvironmentDrift) InSync() bool {
+	return len(drift.Ahead) == 0 && len(drift.Behind) == 0
+}
+
+// Compare compares the items ever deployed to the given environments.
+//
+// The items listed are fetched using Items.Get so that they carry the current
+// title and status, the version recorded by the latest deploy is used
+// for the items that cannot be fetched any more.
+func (srv DeploysService) Compare(productId int, from, to string) (*EnvironmentDrift, *http.Response, error) {
+	fromItems, resp, err := srv.deployedItems(productId, from)
+	if err != nil {
+		return nil, resp, err
+	}
+	toItems, resp, err := srv.deployedItems(productId, to)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	drift := &EnvironmentDrift{From: from, To: to}
+	for number, item := range fromItems {
+		if _, ok := toItems[number]; !ok {
+			drift.Ahead = append(drift.Ahead, item)
+		}
+	}
+	for number, item := range toItems {
+		if _, ok := fromItems[number]; !ok {
+			drift.Behind = append(drift.Behind, item)
+		}
+	}
+
+	for _, list := range [][]Item{drift.Ahead, drift.Behind} {
+		sort.Slice(list, func(i, j int) bool { return list[i].Number < list[j].Number })
+
+		for i := range list {
+			item, r, err := srv.client.Items.Get(productId, list[i].Number)
+			if err != nil {
+				var notFound *ErrItems404
+				if errors.As(err, &notFound) {
+					continue
+				}
+				return nil, r, err
+			}
+			list[i], resp = *item, r
+		}
+	}
+
+	return drift, resp, nil
+}
+
+// Promote deploys to the To environment exactly the items deployed to From, but not to To.
+//
+// The drift the deploy is based on is returned as well. The deploy is nil
+// and nothing is created when there is nothing to promote.
+func (srv DeploysService) Promote(productId int, from, to string) (*Deploy, *EnvironmentDrift, *http.Response, error) {
+	drift, resp, err := srv.Compare(productId, from, to)
+	if err != nil {
+		return nil, nil, resp, err
+	}
+	if len(drift.Ahead) == 0 {
+		return nil, drift, resp, nil
+	}
+
+	args := &DeployCreateArgs{Environment: to}
+	for _, item := range drift.Ahead {
+		args.ItemNumbers = append(args.ItemNumbers, item.Number)
+	}
+	deploy, resp, err := srv.Create(productId, args)
+	if err != nil {
+		return nil, drift, resp, err
+	}
+	return deploy, drift, resp, nil
+}
+
+// deployedItems returns the items ever deployed to the given environment by number.
+func (srv DeploysService) deployedItems(productId int, environment string) (map[int]Item, *http.Response, error) {
+	deploys, resp, err := srv.List(productId, &DeployListArgs{Environment: environment})
+	if err != nil {
+		return nil, resp, err
+	}
+
+	items := make(map[int]Item)
+	for _, deploy := range deploys {
+		// The environment is checked in case the server ignores the filter.
+		if deploy.Environment != environment {
+			continue
+		}
+		for _, item := range deploy.Items {
+			if prev, ok := items[item.Number]; ok && !moreRecent(&item, &prev) {
+				continue
+			}
+			items[item.Number] = item
+		}
+	}
+	return items, resp, nil
+}
+
+// moreRecent returns true unless the item is known to be older than the previous version.
+func moreRecent(item, prev *Item) bool {
+	if prev.LastModified == nil || item.LastModified == nil {
+		return true
+	}
+	return item.LastModified.After(*prev.LastModified)
+}
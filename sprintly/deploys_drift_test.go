@@ -0,0 +1,100 @@
+package sprintly
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func handleDrift(t *testing.T, mux *http.ServeMux) {
+	mux.HandleFunc("/products/1/deploys.json", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			switch env := r.URL.Query().Get("environment"); env {
+			case "staging":
+				fmt.Fprint(w, `[
+					{"environment": "staging", "items": [{"number": 1}, {"number": 2}, {"number": 4, "title": "Old"}]},
+					{"environment": "staging", "items": [{"number": 3}]}
+				]`)
+			case "production":
+				fmt.Fprint(w, `[{"environment": "production", "items": [{"number": 1}, {"number": 5}]}]`)
+			default:
+				t.Errorf("unexpected environment %q", env)
+			}
+		case "POST":
+			r.ParseForm()
+			fmt.Fprintf(w, `{"environment": %q, "items": [{"number": 2}, {"number": 3}, {"number": 4}]}`, r.PostForm.Get("environment"))
+			ensureEqual(t, r.PostForm.Get("numbers"), "2,3,4")
+		}
+	})
+	mux.HandleFunc("/products/1/items/", func(w http.ResponseWriter, r *http.Request) {
+		ensureMethod(t, r, "GET")
+		var number int
+		fmt.Sscanf(r.URL.Path, "/products/1/items/%d.json", &number)
+		if number == 4 {
+			http.Error(w, `{"code": 404, "message": "Not found"}`, http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, `{"number": %v, "title": "Item %v", "status": "completed"}`, number, number)
+	})
+}
+
+func TestDeploys_Compare(t *testing.T) {
+	client, server, mux := setup()
+	defer server.Close()
+	handleDrift(t, mux)
+
+	drift, _, err := client.Deploys.Compare(1, "staging", "production")
+	if err != nil {
+		t.Fatalf("Deploys.Compare failed: %v", err)
+	}
+
+	ensureEqual(t, drift, &EnvironmentDrift{
+		From: "staging",
+		To:   "production",
+		Ahead: []Item{
+			{Number: 2, Title: "Item 2", Status: ItemStatusCompleted},
+			{Number: 3, Title: "Item 3", Status: ItemStatusCompleted},
+			{Number: 4, Title: "Old"},
+		},
+		Behind: []Item{
+			{Number: 5, Title: "Item 5", Status: ItemStatusCompleted},
+		},
+	})
+	if drift.InSync() {
+		t.Error("drift reported in sync")
+	}
+}
+
+func TestDeploys_Promote(t *testing.T) {
+	client, server, mux := setup()
+	defer server.Close()
+	handleDrift(t, mux)
+
+	deploy, drift, _, err := client.Deploys.Promote(1, "staging", "production")
+	if err != nil {
+		t.Fatalf("Deploys.Promote failed: %v", err)
+	}
+	if deploy == nil || deploy.Environment != "production" || len(drift.Ahead) != 3 {
+		t.Errorf("unexpected promotion: %+v, %+v", deploy, drift)
+	}
+}
+
+func TestDeploys_Promote_InSync(t *testing.T) {
+	client, server, mux := setup()
+	defer server.Close()
+
+	mux.HandleFunc("/products/1/deploys.json", func(w http.ResponseWriter, r *http.Request) {
+		ensureMethod(t, r, "GET")
+		env := r.URL.Query().Get("environment")
+		fmt.Fprintf(w, `[{"environment": %q, "items": [{"number": 1}]}]`, env)
+	})
+
+	deploy, drift, _, err := client.Deploys.Promote(1, "staging", "production")
+	if err != nil {
+		t.Fatalf("Deploys.Promote failed: %v", err)
+	}
+	if deploy != nil || !drift.InSync() {
+		t.Errorf("unexpected promotion: %+v, %+v", deploy, drift)
+	}
+}
@@ -4,10 +4,14 @@ import (
 	"fmt"
 	"net/http"
 	"testing"
+	"time"
 )
 
+var testingDeployCreatedAt = time.Date(2015, 1, 5, 10, 0, 0, 0, time.UTC)
+
 var testingDeploy = Deploy{
 	Environment: "staging",
+	CreatedAt:   &testingDeployCreatedAt,
 	Items: []Item{
 		{
 			Number: 188,
@@ -19,6 +23,7 @@ var testingDeploy = Deploy{
 var testingDeployJson = `
 {
 	"environment": "staging",
+	"created_at": "2015-01-05T10:00:00Z",
 	"items": [
 		{
 			"number": 188,
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"sync"
 	"time"
 )
 
@@ -26,6 +27,18 @@ const (
 	ItemStatusAccepted   ItemStatus = "accepted"
 )
 
+// ItemStatuses lists all the item statuses in the order of the workflow.
+//
+// Items.List only returns the items in some of the statuses unless
+// ItemListArgs.Status is set, pass ItemStatuses to list all the items.
+var ItemStatuses = []ItemStatus{
+	ItemStatusSomeday,
+	ItemStatusBacklog,
+	ItemStatusInProgress,
+	ItemStatusCompleted,
+	ItemStatusAccepted,
+}
+
 type ItemScore string
 
 const (
@@ -36,6 +49,23 @@ const (
 	ItemScoreVeryLarge ItemScore = "XL"
 )
 
+// Points returns the number of points Sprintly associates with the score.
+// Unset and unknown scores are worth 0 points.
+func (score ItemScore) Points() int {
+	switch score {
+	case ItemScoreSmall:
+		return 1
+	case ItemScoreMedium:
+		return 3
+	case ItemScoreLarge:
+		return 5
+	case ItemScoreVeryLarge:
+		return 8
+	default:
+		return 0
+	}
+}
+
 type ItemOrdering string
 
 const (
@@ -48,13 +78,22 @@ const (
 	ItemOrderingAbandoned              = "abandoned"
 )
 
+// itemListPageSize is the page size used when listing all items.
+const itemListPageSize = 100
+
 // ItemsService holds all the methods for manipulating Sprintly items.
 type ItemsService struct {
 	client *Client
+
+	// Workflows set for particular products, see SetWorkflow.
+	workflows map[int]*Workflow
 }
 
 func newItemsService(client *Client) *ItemsService {
-	return &ItemsService{client}
+	return &ItemsService{
+		client:    client,
+		workflows: make(map[int]*Workflow),
+	}
 }
 
 // Item represents a Sprintly item.
@@ -231,6 +270,73 @@ func (srv ItemsService) List(productId int, args *ItemListArgs) ([]Item, *http.R
 	return items, resp, nil
 }
 
+// ListAll can be used to list all items matching the given arguments.
+//
+// The items are fetched page by page, args.Offset is used as the starting offset
+// and args.Limit as the page size. The response returned is the last one received.
+func (srv ItemsService) ListAll(productId int, args *ItemListArgs) ([]Item, *http.Response, error) {
+	return srv.listAll(productId, args, 1)
+}
+
+// listAll fetches all the items matching args, concurrency pages at once.
+func (srv ItemsService) listAll(
+	productId int,
+	args *ItemListArgs,
+	concurrency int,
+) ([]Item, *http.Response, error) {
+
+	var pageArgs ItemListArgs
+	if args != nil {
+		pageArgs = *args
+	}
+	if pageArgs.Limit == 0 {
+		pageArgs.Limit = itemListPageSize
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	type page struct {
+		items []Item
+		resp  *http.Response
+		err   error
+	}
+
+	var (
+		all  []Item
+		resp *http.Response
+	)
+	for {
+		// Fetch the next batch of pages.
+		pages := make([]page, concurrency)
+		var wg sync.WaitGroup
+		for i := range pages {
+			listArgs := pageArgs
+			listArgs.Offset += i * pageArgs.Limit
+
+			wg.Add(1)
+			go func(p *page) {
+				defer wg.Done()
+				p.items, p.resp, p.err = srv.List(productId, &listArgs)
+			}(&pages[i])
+		}
+		wg.Wait()
+
+		// Collect the pages, stop at the first page that is not full.
+		for _, p := range pages {
+			if p.err != nil {
+				return nil, p.resp, p.err
+			}
+			all = append(all, p.items...)
+			resp = p.resp
+			if len(p.items) < pageArgs.Limit {
+				return all, resp, nil
+			}
+		}
+		pageArgs.Offset += concurrency * pageArgs.Limit
+	}
+}
+
 // Get can be used to get the item identified by the given item number.
 //
 // See https://sprintly.uservoice.com/knowledgebase/articles/98412-items
@@ -290,6 +396,63 @@ func (srv ItemsService) Update(
 	return &item, resp, nil
 }
 
+// itemUnassignArgs clears the assignee, ItemUpdateArgs omits an empty assigned_to.
+type itemUnassignArgs struct {
+	AssignedTo string `url:"assigned_to" schema:"assigned_to"`
+}
+
+// Unassign can be used to clear the assignee of the item identified by the given item number.
+//
+// See https://sprintly.uservoice.com/knowledgebase/articles/98412-items
+func (srv ItemsService) Unassign(productId, itemNumber int) (*Item, *http.Response, error) {
+	u := fmt.Sprintf("products/%v/items/%v.json", productId, itemNumber)
+
+	req, err := srv.client.NewPostRequest(u, &itemUnassignArgs{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var item Item
+	resp, err := srv.client.Do(req, &item)
+	if err != nil {
+		switch resp.StatusCode {
+		case 400:
+			return nil, nil, &ErrItems400{err.(*ErrAPI)}
+		case 404:
+			return nil, nil, &ErrItems404{err.(*ErrAPI)}
+		default:
+			return nil, resp, err
+		}
+	}
+
+	return &item, resp, nil
+}
+
+// Archive can be used to archive the item identified by the given item number.
+//
+// See https://sprintly.uservoice.com/knowledgebase/articles/98412-items
+func (srv ItemsService) Archive(productId, itemNumber int) (*Item, *http.Response, error) {
+	u := fmt.Sprintf("products/%v/items/%v.json", productId, itemNumber)
+
+	req, err := srv.client.NewDeleteRequest(u)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var item Item
+	resp, err := srv.client.Do(req, &item)
+	if err != nil {
+		switch resp.StatusCode {
+		case 404:
+			return nil, nil, &ErrItems404{err.(*ErrAPI)}
+		default:
+			return nil, resp, err
+		}
+	}
+
+	return &item, resp, nil
+}
+
 // ListChildren can be used to list children of the given item.
 //
 // See https://sprintly.uservoice.com/knowledgebase/articles/98412-items
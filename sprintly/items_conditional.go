@@ -0,0 +1,186 @@
+package sprintly
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DefaultMergeAttempts is the number of updates Items.UpdateMerge attempts
+// before giving up on an item that keeps being modified.
+const DefaultMergeAttempts = 3
+
+// ErrItemConflict is returned by the conditional updates
+// when the item was modified in the meantime.
+type ErrItemConflict struct {
+	ItemNumber int
+	Expected   *time.Time
+
+	// Current is the current version of the item.
+	Current *Item
+
+	// Fields lists the fields modified in the meantime that the update
+	// would overwrite, when known.
+	Fields []string
+}
+
+func (err *ErrItemConflict) Error() string {
+	var actual *time.Time
+	if err.Current != nil {
+		actual = err.Current.LastModified
+	}
+	msg := fmt.Sprintf("item #%v modified in the meantime (last modified %v, expected %v)",
+		err.ItemNumber, formatTime(actual), formatTime(err.Expected))
+	if len(err.Fields) != 0 {
+		msg += ", conflicting fields: " + strings.Join(err.Fields, ", ")
+	}
+	return msg
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "never"
+	}
+	return t.Format(time.RFC3339)
+}
+
+// UpdateIf updates the item only when its LastModified is the expected one,
+// returning an *ErrItemConflict otherwise.
+//
+// The API offers no conditional requests, so the item is fetched and compared
+// right before being updated. That narrows the window for lost updates a lot,
+// but it does not close it completely.
+func (srv ItemsService) UpdateIf(
+	productId int,
+	itemNumber int,
+	expected *time.Time,
+	args *ItemUpdateArgs,
+) (*Item, *http.Response, error) {
+
+	current, resp, err := srv.Get(productId, itemNumber)
+	if err != nil {
+		return nil, resp, err
+	}
+	if !sameTime(current.LastModified, expected) {
+		return nil, resp, &ErrItemConflict{ItemNumber: itemNumber, Expected: expected, Current: current}
+	}
+	return srv.Update(productId, itemNumber, args)
+}
+
+// UnassignIf clears the assignee of the item only when its LastModified
+// is the expected one, returning an *ErrItemConflict otherwise.
+// It is subject to the same race as UpdateIf.
+func (srv ItemsService) UnassignIf(
+	productId int,
+	itemNumber int,
+	expected *time.Time,
+) (*Item, *http.Response, error) {
+
+	current, resp, err := srv.Get(productId, itemNumber)
+	if err != nil {
+		return nil, resp, err
+	}
+	if !sameTime(current.LastModified, expected) {
+		return nil, resp, &ErrItemConflict{ItemNumber: itemNumber, Expected: expected, Current: current}
+	}
+	return srv.Unassign(productId, itemNumber)
+}
+
+// ItemMergeFunc is called by Items.UpdateMerge on a conflict. It receives
+// the current version of the item and the arguments of the update that failed,
+// and returns the arguments to retry with, or an error to give up.
+type ItemMergeFunc func(current *Item, args *ItemUpdateArgs) (*ItemUpdateArgs, error)
+
+// UpdateMerge updates the item the caller based the changes on, merging
+// the changes with the modifications made in the meantime.
+//
+// The update is done using UpdateIf, expecting base.LastModified. On a conflict,
+// merge is called and the update is attempted again, expecting the LastModified
+// of the current item this time, up to DefaultMergeAttempts times. MergeDisjoint(base)
+// is used when merge is nil.
+func (srv ItemsService) UpdateMerge(
+	productId int,
+	base *Item,
+	args *ItemUpdateArgs,
+	merge ItemMergeFunc,
+) (*Item, *http.Response, error) {
+
+	if merge == nil {
+		merge = MergeDisjoint(base)
+	}
+
+	expected := base.LastModified
+	for attempt := 1; ; attempt++ {
+		item, resp, err := srv.UpdateIf(productId, base.Number, expected, args)
+		conflict, ok := err.(*ErrItemConflict)
+		if !ok || attempt == DefaultMergeAttempts {
+			return item, resp, err
+		}
+
+		args, err = merge(conflict.Current, args)
+		if err != nil {
+			return nil, resp, err
+		}
+		expected = conflict.Current.LastModified
+	}
+}
+
+// MergeDisjoint returns an ItemMergeFunc keeping the update unchanged as long as
+// the fields it sets were not modified since base. Otherwise the merge is refused
+// with an *ErrItemConflict listing the fields modified by both.
+func MergeDisjoint(base *Item) ItemMergeFunc {
+	return func(current *Item, args *ItemUpdateArgs) (*ItemUpdateArgs, error) {
+		changes, err := DiffItems(base, current)
+		if err != nil {
+			return nil, err
+		}
+
+		var conflicting []string
+		updated := args.Fields()
+		for _, field := range changes.Fields() {
+			if slices.Contains(updated, field) {
+				conflicting = append(conflicting, field)
+			}
+		}
+		if len(conflicting) != 0 {
+			return nil, &ErrItemConflict{
+				ItemNumber: base.Number,
+				Expected:   base.LastModified,
+				Current:    current,
+				Fields:     conflicting,
+			}
+		}
+		return args, nil
+	}
+}
+
+// Fields returns the names of the fields set, as used by the API.
+func (args *ItemUpdateArgs) Fields() []string {
+	var fields []string
+	add := func(field string, set bool) {
+		if set {
+			fields = append(fields, field)
+		}
+	}
+	add("type", args.Type != "")
+	add("title", args.Title != "")
+	add("who", args.Who != "")
+	add("what", args.What != "")
+	add("why", args.Why != "")
+	add("description", args.Description != "")
+	add("status", args.Status != "")
+	add("score", args.Score != "")
+	add("assigned_to", args.AssignedTo != 0)
+	add("tags", len(args.Tags) != 0)
+	add("parent", args.Parent != 0)
+	return fields
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
@@ -0,0 +1,133 @@
+package sprintly
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// serveConflictingItem serves item 188 of product 1, returning the given versions
+// of the item one by one and recording the updates received.
+func serveConflictingItem(t *testing.T, mux *http.ServeMux, versions ...string) *[]ItemUpdateArgs {
+	var updates []ItemUpdateArgs
+	mux.HandleFunc("/products/1/items/188.json", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			if len(versions) == 0 {
+				t.Error("unexpected GET")
+				http.Error(w, "unexpected GET", http.StatusInternalServerError)
+				return
+			}
+			fmt.Fprint(w, versions[0])
+			versions = versions[1:]
+		case "POST":
+			var args ItemUpdateArgs
+			if err := decodeArgs(&args, r); err != nil {
+				t.Error(err)
+			}
+			updates = append(updates, args)
+			fmt.Fprint(w, `{"number": 188}`)
+		}
+	})
+	return &updates
+}
+
+const (
+	itemVersion1 = `{"number": 188, "status": "backlog", "score": "M", "last_modified": "2015-01-01T10:00:00Z"}`
+	itemVersion2 = `{"number": 188, "status": "in-progress", "score": "M", "last_modified": "2015-01-01T10:05:00Z"}`
+	itemVersion3 = `{"number": 188, "status": "in-progress", "score": "L", "last_modified": "2015-01-01T10:07:00Z"}`
+)
+
+func TestItems_UpdateIf(t *testing.T) {
+	client, server, mux := setup()
+	defer server.Close()
+
+	updates := serveConflictingItem(t, mux, itemVersion1, itemVersion2)
+	expected := time.Date(2015, 1, 1, 10, 0, 0, 0, time.UTC)
+	args := &ItemUpdateArgs{Score: ItemScoreLarge}
+
+	if _, _, err := client.Items.UpdateIf(1, 188, &expected, args); err != nil {
+		t.Fatalf("Items.UpdateIf failed: %v", err)
+	}
+
+	_, _, err := client.Items.UpdateIf(1, 188, &expected, args)
+	var conflict *ErrItemConflict
+	if !errors.As(err, &conflict) {
+		t.Fatalf("Items.UpdateIf should have failed with a conflict, err = %v", err)
+	}
+	if conflict.Current.Status != ItemStatusInProgress {
+		t.Errorf("conflict.Current = %+v", conflict.Current)
+	}
+	if len(*updates) != 1 {
+		t.Errorf("the item was updated %v times, want once", len(*updates))
+	}
+}
+
+func TestItems_UnassignIf(t *testing.T) {
+	client, server, mux := setup()
+	defer server.Close()
+
+	updates := serveConflictingItem(t, mux, itemVersion1, itemVersion2)
+	expected := time.Date(2015, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	if _, _, err := client.Items.UnassignIf(1, 188, &expected); err != nil {
+		t.Fatalf("Items.UnassignIf failed: %v", err)
+	}
+	_, _, err := client.Items.UnassignIf(1, 188, &expected)
+	var conflict *ErrItemConflict
+	if !errors.As(err, &conflict) {
+		t.Fatalf("Items.UnassignIf should have failed with a conflict, err = %v", err)
+	}
+	if len(*updates) != 1 {
+		t.Errorf("the item was updated %v times, want once", len(*updates))
+	}
+}
+
+func TestItems_UpdateMerge(t *testing.T) {
+	client, server, mux := setup()
+	defer server.Close()
+
+	var base Item
+	if err := json.Unmarshal([]byte(itemVersion1), &base); err != nil {
+		t.Fatal(err)
+	}
+
+	// The status changed in the meantime, the score can still be updated.
+	updates := serveConflictingItem(t, mux, itemVersion2, itemVersion2)
+	if _, _, err := client.Items.UpdateMerge(1, &base, &ItemUpdateArgs{Score: ItemScoreLarge}, nil); err != nil {
+		t.Fatalf("Items.UpdateMerge failed: %v", err)
+	}
+	ensureEqual(t, *updates, []ItemUpdateArgs{{Score: ItemScoreLarge}})
+
+	// The score changed in the meantime as well.
+	client, server, mux = setup()
+	defer server.Close()
+
+	updates = serveConflictingItem(t, mux, itemVersion3)
+	_, _, err := client.Items.UpdateMerge(1, &base, &ItemUpdateArgs{Score: ItemScoreSmall}, nil)
+	var conflict *ErrItemConflict
+	if !errors.As(err, &conflict) || len(conflict.Fields) != 1 || conflict.Fields[0] != "score" {
+		t.Errorf("Items.UpdateMerge should have failed with a score conflict, err = %v", err)
+	}
+	if len(*updates) != 0 {
+		t.Errorf("the item was updated despite the conflict")
+	}
+
+	// The item keeps changing.
+	client, server, mux = setup()
+	defer server.Close()
+
+	serveConflictingItem(t, mux, itemVersion2, itemVersion3, itemVersion1)
+	merges := 0
+	merge := func(current *Item, args *ItemUpdateArgs) (*ItemUpdateArgs, error) {
+		merges++
+		return args, nil
+	}
+	_, _, err = client.Items.UpdateMerge(1, &base, &ItemUpdateArgs{Score: ItemScoreSmall}, merge)
+	if !errors.As(err, &conflict) || merges != DefaultMergeAttempts-1 {
+		t.Errorf("Items.UpdateMerge merged %v times, err = %v", merges, err)
+	}
+}
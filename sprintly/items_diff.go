@@ -0,0 +1,260 @@
+package sprintly
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// FieldChange represents a single item field changed from Old to New.
+type FieldChange[T comparable] struct {
+	Old T
+	New T
+}
+
+func (change *FieldChange[T]) String() string {
+	return fmt.Sprintf("%q -> %q", fmt.Sprint(change.Old), fmt.Sprint(change.New))
+}
+
+// TagsChange represents the item tags being changed.
+//
+// The tags are treated as a set, reordering them is not a change.
+type TagsChange struct {
+	Old     []string
+	New     []string
+	Added   []string
+	Removed []string
+}
+
+func (change *TagsChange) String() string {
+	var parts []string
+	for _, tag := range change.Added {
+		parts = append(parts, "+"+tag)
+	}
+	for _, tag := range change.Removed {
+		parts = append(parts, "-"+tag)
+	}
+	return strings.Join(parts, " ")
+}
+
+// ItemChanges represents the differences between two versions of an item,
+// as returned by DiffItems. The fields that did not change are nil.
+type ItemChanges struct {
+	Type        *FieldChange[string]
+	Title       *FieldChange[string]
+	Who         *FieldChange[string]
+	What        *FieldChange[string]
+	Why         *FieldChange[string]
+	Description *FieldChange[string]
+	Status      *FieldChange[ItemStatus]
+	Score       *FieldChange[ItemScore]
+
+	// AssignedTo holds the user IDs, 0 meaning not assigned.
+	AssignedTo *FieldChange[int]
+
+	Tags *TagsChange
+
+	// Parent holds the parent item numbers, 0 meaning no parent.
+	Parent *FieldChange[int]
+
+	// story is true when the new version of the item is a story.
+	story bool
+}
+
+// DiffItems compares two versions of an item field by field.
+//
+// The user-facing fields are compared, the fields maintained by Sprintly
+// such as the progress or the counts are not. An error is returned when
+// the parent of either item cannot be read.
+func DiffItems(old, new *Item) (*ItemChanges, error) {
+	oldParent, err := old.ParentNumber()
+	if err != nil {
+		return nil, err
+	}
+	newParent, err := new.ParentNumber()
+	if err != nil {
+		return nil, err
+	}
+
+	changes := ItemChanges{story: new.Type == string(ItemTypeStory)}
+	changes.Type = diffField(old.Type, new.Type)
+	changes.Title = diffField(old.Title, new.Title)
+	changes.Who = diffField(old.Who, new.Who)
+	changes.What = diffField(old.What, new.What)
+	changes.Why = diffField(old.Why, new.Why)
+	changes.Description = diffField(old.Description, new.Description)
+	changes.Status = diffField(old.Status, new.Status)
+	changes.Score = diffField(old.Score, new.Score)
+	changes.AssignedTo = diffField(userId(old.AssignedTo), userId(new.AssignedTo))
+	changes.Tags = diffTags(old.Tags, new.Tags)
+	changes.Parent = diffField(oldParent, newParent)
+	return &changes, nil
+}
+
+func diffField[T comparable](old, new T) *FieldChange[T] {
+	if old == new {
+		return nil
+	}
+	return &FieldChange[T]{old, new}
+}
+
+func diffTags(old, new []string) *TagsChange {
+	change := &TagsChange{Old: old, New: new}
+	for _, tag := range new {
+		if !slices.Contains(old, tag) && !slices.Contains(change.Added, tag) {
+			change.Added = append(change.Added, tag)
+		}
+	}
+	for _, tag := range old {
+		if !slices.Contains(new, tag) && !slices.Contains(change.Removed, tag) {
+			change.Removed = append(change.Removed, tag)
+		}
+	}
+	if len(change.Added) == 0 && len(change.Removed) == 0 {
+		return nil
+	}
+	return change
+}
+
+func userId(user *User) int {
+	if user == nil {
+		return 0
+	}
+	return user.Id
+}
+
+// Empty returns true when nothing changed.
+func (changes *ItemChanges) Empty() bool {
+	return len(changes.Fields()) == 0
+}
+
+// Fields returns the names of the fields changed, as used by the API.
+func (changes *ItemChanges) Fields() []string {
+	var fields []string
+	changes.each(func(field string, _ fmt.Stringer) {
+		fields = append(fields, field)
+	})
+	return fields
+}
+
+func (changes *ItemChanges) String() string {
+	var lines []string
+	changes.each(func(field string, change fmt.Stringer) {
+		lines = append(lines, fmt.Sprintf("%v: %v", field, change))
+	})
+	return strings.Join(lines, "\n")
+}
+
+// each calls fn for every field changed.
+func (changes *ItemChanges) each(fn func(field string, change fmt.Stringer)) {
+	if changes.Type != nil {
+		fn("type", changes.Type)
+	}
+	if changes.Title != nil {
+		fn("title", changes.Title)
+	}
+	if changes.Who != nil {
+		fn("who", changes.Who)
+	}
+	if changes.What != nil {
+		fn("what", changes.What)
+	}
+	if changes.Why != nil {
+		fn("why", changes.Why)
+	}
+	if changes.Description != nil {
+		fn("description", changes.Description)
+	}
+	if changes.Status != nil {
+		fn("status", changes.Status)
+	}
+	if changes.Score != nil {
+		fn("score", changes.Score)
+	}
+	if changes.AssignedTo != nil {
+		fn("assigned_to", changes.AssignedTo)
+	}
+	if changes.Tags != nil {
+		fn("tags", changes.Tags)
+	}
+	if changes.Parent != nil {
+		fn("parent", changes.Parent)
+	}
+}
+
+// ErrFieldCleared is returned by ItemChanges.UpdateArgs for the changes
+// that clear a field, since Items.Update cannot clear fields.
+type ErrFieldCleared struct {
+	Field string
+}
+
+func (err *ErrFieldCleared) Error() string {
+	return fmt.Sprintf("Items.Update cannot clear %v", err.Field)
+}
+
+// UpdateArgs returns the minimal arguments for Items.Update applying the changes,
+// i.e. only the fields changed are set.
+//
+// The tags are replaced as a whole, so the new set of tags is passed. Since the title
+// of a story is derived from who, what and why, the title is not passed for stories.
+// An *ErrFieldCleared is returned for the first field changed to an empty value.
+func (changes *ItemChanges) UpdateArgs() (*ItemUpdateArgs, error) {
+	var args ItemUpdateArgs
+
+	title := changes.Title
+	if changes.story {
+		title = nil
+	}
+	for _, f := range []struct {
+		field  string
+		dst    *string
+		change *FieldChange[string]
+	}{
+		{"type", &args.Type, changes.Type},
+		{"title", &args.Title, title},
+		{"who", &args.Who, changes.Who},
+		{"what", &args.What, changes.What},
+		{"why", &args.Why, changes.Why},
+		{"description", &args.Description, changes.Description},
+	} {
+		if f.change == nil {
+			continue
+		}
+		if f.change.New == "" {
+			return nil, &ErrFieldCleared{f.field}
+		}
+		*f.dst = f.change.New
+	}
+
+	if c := changes.Status; c != nil {
+		if c.New == "" {
+			return nil, &ErrFieldCleared{"status"}
+		}
+		args.Status = c.New
+	}
+	if c := changes.Score; c != nil {
+		if c.New == "" {
+			return nil, &ErrFieldCleared{"score"}
+		}
+		args.Score = c.New
+	}
+	if c := changes.AssignedTo; c != nil {
+		if c.New == 0 {
+			return nil, &ErrFieldCleared{"assigned_to"}
+		}
+		args.AssignedTo = c.New
+	}
+	if c := changes.Tags; c != nil {
+		if len(c.New) == 0 {
+			return nil, &ErrFieldCleared{"tags"}
+		}
+		args.Tags = c.New
+	}
+	if c := changes.Parent; c != nil {
+		if c.New == 0 {
+			return nil, &ErrFieldCleared{"parent"}
+		}
+		args.Parent = c.New
+	}
+	return &args, nil
+}
@@ -0,0 +1,103 @@
+package sprintly
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestDiffItems(t *testing.T) {
+	old := &Item{
+		Number:      188,
+		Type:        "task",
+		Title:       "Don't let un-scored items out of the backlog.",
+		Description: "Require people to estimate the score.",
+		Status:      ItemStatusBacklog,
+		Score:       ItemScoreMedium,
+		Tags:        []string{"scoring", "backlog"},
+		Parent:      float64(12),
+		AssignedTo:  &User{Id: 1, Email: "joe@joestump.net"},
+		Counts:      &ItemCounts{Comments: 1},
+	}
+	new := &Item{
+		Number:      188,
+		Type:        "task",
+		Title:       "Don't let un-scored items out of the backlog.",
+		Description: "Require people to estimate the score.",
+		Status:      ItemStatusInProgress,
+		Score:       ItemScoreMedium,
+		Tags:        []string{"backlog", "workflow"},
+		Parent:      map[string]interface{}{"number": float64(14)},
+		// Only the user ID counts.
+		AssignedTo: &User{Id: 1},
+		Counts:     &ItemCounts{Comments: 2},
+	}
+
+	changes, err := DiffItems(old, new)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := changes.Fields(); !slices.Equal(got, []string{"status", "tags", "parent"}) {
+		t.Errorf("Fields() = %v", got)
+	}
+	ensureEqual(t, changes.Status, &FieldChange[ItemStatus]{ItemStatusBacklog, ItemStatusInProgress})
+	ensureEqual(t, changes.Parent, &FieldChange[int]{12, 14})
+	if c := changes.Tags; !slices.Equal(c.Added, []string{"workflow"}) || !slices.Equal(c.Removed, []string{"scoring"}) {
+		t.Errorf("tags added %v, removed %v", c.Added, c.Removed)
+	}
+	if got, want := changes.String(), "status: \"backlog\" -> \"in-progress\"\ntags: +workflow -scoring\nparent: \"12\" -> \"14\""; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	args, err := changes.UpdateArgs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ensureEqual(t, args, &ItemUpdateArgs{
+		Status: ItemStatusInProgress,
+		Tags:   []string{"backlog", "workflow"},
+		Parent: 14,
+	})
+
+	// Reordering the tags is not a change.
+	new = &Item{Tags: []string{"b", "a"}}
+	if changes, _ := DiffItems(&Item{Tags: []string{"a", "b"}}, new); !changes.Empty() {
+		t.Errorf("reordered tags reported: %v", changes)
+	}
+
+	if _, err := DiffItems(&Item{Parent: "12"}, new); err == nil {
+		t.Error("DiffItems accepted an invalid parent")
+	}
+}
+
+func TestItemChanges_UpdateArgs(t *testing.T) {
+	// The story title is not passed.
+	changes, _ := DiffItems(
+		&Item{Type: "story", Who: "user", What: "to diff", Title: "As a user, I want to diff"},
+		&Item{Type: "story", Who: "admin", What: "to diff", Title: "As an admin, I want to diff"},
+	)
+	args, err := changes.UpdateArgs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ensureEqual(t, args, &ItemUpdateArgs{Who: "admin"})
+
+	for _, tc := range []struct {
+		old, new *Item
+		field    string
+	}{
+		{&Item{Description: "x"}, &Item{}, "description"},
+		{&Item{AssignedTo: &User{Id: 1}}, &Item{}, "assigned_to"},
+		{&Item{Tags: []string{"a"}}, &Item{}, "tags"},
+		{&Item{Parent: float64(1)}, &Item{}, "parent"},
+	} {
+		changes, _ := DiffItems(tc.old, tc.new)
+		_, err := changes.UpdateArgs()
+
+		var cleared *ErrFieldCleared
+		if !errors.As(err, &cleared) || cleared.Field != tc.field {
+			t.Errorf("clearing %v: err = %v", tc.field, err)
+		}
+	}
+}
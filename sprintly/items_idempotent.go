@@ -0,0 +1,82 @@
+package sprintly
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// IdempotencyLookback is the number of the newest items CreateIdempotent
+// searches for an item created with the same key.
+const IdempotencyLookback = 100
+
+// idempotencyMarker is appended to the description of the items created by CreateIdempotent.
+// It is an HTML comment so that it does not show in the rendered description.
+const idempotencyMarker = "<!-- idempotency-key: %v -->"
+
+// CreateIdempotent creates the item unless an item with the same idempotency key exists.
+//
+// The key is stored as a marker at the end of the item description. Before creating
+// the item, the newest items of the product are searched for the marker, and the item
+// found is returned instead of creating a new one. The boolean returned is true when
+// the item was created.
+//
+// This makes the creation safe to retry, e.g. after a timeout, but it does not
+// protect against concurrent creations using the same key, and only the newest
+// IdempotencyLookback items are searched.
+func (srv ItemsService) CreateIdempotent(
+	productId int,
+	key string,
+	args *ItemCreateArgs,
+) (*Item, bool, *http.Response, error) {
+
+	if key == "" || strings.ContainsAny(key, "\r\n") || strings.Contains(key, "-->") {
+		return nil, false, nil, fmt.Errorf("Items.CreateIdempotent: invalid idempotency key %q", key)
+	}
+	marker := fmt.Sprintf(idempotencyMarker, key)
+
+	items, resp, err := srv.List(productId, &ItemListArgs{
+		Status:   ItemStatuses,
+		OrderBy:  ItemOrderingNewest,
+		Limit:    IdempotencyLookback,
+		Children: true,
+	})
+	if err != nil {
+		return nil, false, resp, err
+	}
+	for i := range items {
+		if strings.Contains(items[i].Description, marker) {
+			return &items[i], false, resp, nil
+		}
+	}
+
+	var createArgs ItemCreateArgs
+	if args != nil {
+		createArgs = *args
+	}
+	if createArgs.Description != "" {
+		createArgs.Description += "\n\n"
+	}
+	createArgs.Description += marker
+
+	item, resp, err := srv.Create(productId, &createArgs)
+	if err != nil {
+		return nil, false, resp, err
+	}
+	return item, true, resp, nil
+}
+
+// IdempotencyKey returns the idempotency key the item was created with
+// by CreateIdempotent, an empty string when there is none.
+func (item *Item) IdempotencyKey() string {
+	prefix, suffix, _ := strings.Cut(idempotencyMarker, "%v")
+	i := strings.LastIndex(item.Description, prefix)
+	if i == -1 {
+		return ""
+	}
+	key, _, ok := strings.Cut(item.Description[i+len(prefix):], suffix)
+	if !ok {
+		return ""
+	}
+	return key
+}
@@ -0,0 +1,74 @@
+package sprintly
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestItems_CreateIdempotent(t *testing.T) {
+	client, server, mux := setup()
+	defer server.Close()
+
+	// The items of the product, newest first.
+	var items []Item
+	mux.HandleFunc("/products/1/items.json", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			if v := r.URL.Query().Get("order_by"); v != "newest" {
+				t.Errorf("order_by = %q, want newest", v)
+			}
+			json.NewEncoder(w).Encode(items)
+
+		case "POST":
+			var args ItemCreateArgs
+			if err := decodeArgs(&args, r); err != nil {
+				t.Error(err)
+			}
+			item := Item{Number: 200 + len(items), Type: args.Type, Title: args.Title, Description: args.Description}
+			items = append([]Item{item}, items...)
+			json.NewEncoder(w).Encode(item)
+		}
+	})
+
+	args := &ItemCreateArgs{Type: "task", Title: "Nightly build failed", Description: "See the CI log."}
+	item, created, _, err := client.Items.CreateIdempotent(1, "build-1234", args)
+	if err != nil {
+		t.Fatalf("Items.CreateIdempotent failed: %v", err)
+	}
+	if !created || item.Number != 200 {
+		t.Errorf("created = %v, item = %+v", created, item)
+	}
+	if want := "See the CI log.\n\n<!-- idempotency-key: build-1234 -->"; item.Description != want {
+		t.Errorf("Description = %q, want %q", item.Description, want)
+	}
+	if key := item.IdempotencyKey(); key != "build-1234" {
+		t.Errorf("IdempotencyKey() = %q", key)
+	}
+	if args.Description != "See the CI log." {
+		t.Errorf("the arguments passed in were modified")
+	}
+
+	// Another key creates another item.
+	if _, created, _, _ := client.Items.CreateIdempotent(1, "build-1235", args); !created {
+		t.Errorf("item with another key not created")
+	}
+
+	// A retry returns the existing item.
+	item, created, _, err = client.Items.CreateIdempotent(1, "build-1234", args)
+	if err != nil {
+		t.Fatalf("Items.CreateIdempotent failed: %v", err)
+	}
+	if created || item.Number != 200 || len(items) != 2 {
+		t.Errorf("created = %v, item = %+v, %v items in the product", created, item, len(items))
+	}
+
+	for _, key := range []string{"", "multi\nline", "x -->"} {
+		if _, _, _, err := client.Items.CreateIdempotent(1, key, args); err == nil {
+			t.Errorf("key %q accepted", key)
+		}
+	}
+	if key := (&Item{Description: "No key."}).IdempotencyKey(); key != "" {
+		t.Errorf("IdempotencyKey() = %q, want none", key)
+	}
+}
@@ -225,6 +225,48 @@ func TestItems_Update(t *testing.T) {
 	ensureEqual(t, item, &testingTask)
 }
 
+func TestItems_Unassign(t *testing.T) {
+	client, server, mux := setup()
+	defer server.Close()
+
+	mux.HandleFunc("/products/1/items/188.json", func(w http.ResponseWriter, r *http.Request) {
+		ensureMethod(t, r, "POST")
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+		}
+		if v, ok := r.PostForm["assigned_to"]; !ok || v[0] != "" {
+			t.Errorf("assigned_to = %q, want it empty", v)
+		}
+		fmt.Fprint(w, testingTaskString)
+	})
+
+	item, _, err := client.Items.Unassign(1, 188)
+	if err != nil {
+		t.Errorf("Items.Unassign failed: %v", err)
+		return
+	}
+
+	ensureEqual(t, item, &testingTask)
+}
+
+func TestItems_Archive(t *testing.T) {
+	client, server, mux := setup()
+	defer server.Close()
+
+	mux.HandleFunc("/products/1/items/188.json", func(w http.ResponseWriter, r *http.Request) {
+		ensureMethod(t, r, "DELETE")
+		fmt.Fprint(w, testingTaskString)
+	})
+
+	item, _, err := client.Items.Archive(1, 188)
+	if err != nil {
+		t.Errorf("Items.Archive failed: %v", err)
+		return
+	}
+
+	ensureEqual(t, item, &testingTask)
+}
+
 func TestItems_ListChildren(t *testing.T) {
 	client, server, mux := setup()
 	defer server.Close()
@@ -0,0 +1,252 @@
+package sprintly
+
+import (
+	"sync"
+)
+
+// defaultTreeConcurrency is the number of API calls running in parallel
+// when ItemTreeArgs.Concurrency is not set.
+const defaultTreeConcurrency = 4
+
+// ItemTree represents Sprintly items linked together by their parent/child relationships.
+type ItemTree struct {
+	// Roots are the nodes with no parent in the tree.
+	Roots []*ItemNode
+
+	nodes map[int]*ItemNode
+}
+
+// ItemNode represents a single item in an ItemTree.
+type ItemNode struct {
+	Item     *Item
+	Parent   *ItemNode
+	Children []*ItemNode
+}
+
+// ItemTreeArgs represents the arguments that can be passed into Items.Tree.
+type ItemTreeArgs struct {
+	// Root is the number of the item the tree is to be rooted at.
+	// The whole product is fetched when Root is not set.
+	Root int
+
+	// Concurrency limits the number of API calls running in parallel.
+	Concurrency int
+
+	// List is used to filter the items when the whole product is fetched.
+	// Children are always included, so the Children field is ignored.
+	// The items of every status are fetched unless Status is set.
+	List *ItemListArgs
+}
+
+// Tree can be used to fetch the items of the given product and link them into a tree.
+//
+// Items which parent is not part of the result are treated as roots.
+func (srv ItemsService) Tree(productId int, args *ItemTreeArgs) (*ItemTree, error) {
+	if args == nil {
+		args = &ItemTreeArgs{}
+	}
+	concurrency := args.Concurrency
+	if concurrency < 1 {
+		concurrency = defaultTreeConcurrency
+	}
+
+	if args.Root != 0 {
+		return srv.subtree(productId, args.Root, concurrency)
+	}
+
+	var listArgs ItemListArgs
+	if args.List != nil {
+		listArgs = *args.List
+	}
+	listArgs.Children = true
+	if len(listArgs.Status) == 0 {
+		listArgs.Status = ItemStatuses
+	}
+
+	items, _, err := srv.listAll(productId, &listArgs, concurrency)
+	if err != nil {
+		return nil, err
+	}
+	return NewItemTree(items)
+}
+
+// subtree fetches the tree rooted at the given item level by level.
+func (srv ItemsService) subtree(productId, root, concurrency int) (*ItemTree, error) {
+	item, _, err := srv.Get(productId, root)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		items = []Item{*item}
+		level = []int{item.Number}
+		seen  = map[int]bool{item.Number: true}
+	)
+	for len(level) != 0 {
+		var (
+			next     []int
+			firstErr error
+			mu       sync.Mutex
+			wg       sync.WaitGroup
+			sem      = make(chan struct{}, concurrency)
+		)
+		for _, number := range level {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(number int) {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+
+				children, _, err := srv.ListChildren(productId, number)
+
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					if firstErr == nil {
+						firstErr = err
+					}
+					return
+				}
+				for _, child := range children {
+					if seen[child.Number] {
+						continue
+					}
+					seen[child.Number] = true
+					items = append(items, child)
+					next = append(next, child.Number)
+				}
+			}(number)
+		}
+		wg.Wait()
+
+		if firstErr != nil {
+			return nil, firstErr
+		}
+		level = next
+	}
+
+	return NewItemTree(items)
+}
+
+// NewItemTree links the given items into a tree using Item.ParentNumber.
+func NewItemTree(items []Item) (*ItemTree, error) {
+	tree := &ItemTree{
+		nodes: make(map[int]*ItemNode, len(items)),
+	}
+
+	nodes := make([]*ItemNode, len(items))
+	for i := range items {
+		node := &ItemNode{Item: &items[i]}
+		nodes[i] = node
+		tree.nodes[node.Item.Number] = node
+	}
+
+	for _, node := range nodes {
+		parentNumber, err := node.Item.ParentNumber()
+		if err != nil {
+			return nil, err
+		}
+
+		parent, ok := tree.nodes[parentNumber]
+		if !ok || parentNumber == 0 || parent == node {
+			tree.Roots = append(tree.Roots, node)
+			continue
+		}
+		node.Parent = parent
+		parent.Children = append(parent.Children, node)
+	}
+
+	return tree, nil
+}
+
+// Node returns the node for the given item number, nil if there is no such node.
+func (tree *ItemTree) Node(itemNumber int) *ItemNode {
+	return tree.nodes[itemNumber]
+}
+
+// Len returns the number of items in the tree.
+func (tree *ItemTree) Len() int {
+	return len(tree.nodes)
+}
+
+// Walk calls fn for every node in the tree, depth first, parents before children.
+// The walk stops at the first error returned by fn.
+func (tree *ItemTree) Walk(fn func(node *ItemNode, depth int) error) error {
+	for _, root := range tree.Roots {
+		if err := root.walk(fn, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (node *ItemNode) walk(fn func(node *ItemNode, depth int) error, depth int) error {
+	if err := fn(node, depth); err != nil {
+		return err
+	}
+	for _, child := range node.Children {
+		if err := child.walk(fn, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RollupScore returns the points of the node item plus the points of all its descendants.
+func (node *ItemNode) RollupScore() int {
+	points := node.Item.Score.Points()
+	for _, child := range node.Children {
+		points += child.RollupScore()
+	}
+	return points
+}
+
+// RollupStatus returns the status of the node as derived from its descendants.
+//
+// A leaf node simply returns the item status. Otherwise the least advanced
+// status of the children is returned, unless there is work in progress,
+// i.e. some children already left the backlog while others did not.
+func (node *ItemNode) RollupStatus() ItemStatus {
+	if len(node.Children) == 0 {
+		return node.Item.Status
+	}
+
+	var (
+		min     ItemStatus
+		started bool
+	)
+	for i, child := range node.Children {
+		status := child.RollupStatus()
+		if i == 0 || status.rank() < min.rank() {
+			min = status
+		}
+		if !status.isBacklog() {
+			started = true
+		}
+	}
+
+	if started && min.isBacklog() {
+		return ItemStatusInProgress
+	}
+	return min
+}
+
+// rank returns the position of the status in the item lifecycle.
+func (status ItemStatus) rank() int {
+	switch status {
+	case ItemStatusSomeday:
+		return 0
+	case ItemStatusBacklog:
+		return 1
+	case ItemStatusInProgress:
+		return 2
+	case ItemStatusCompleted:
+		return 3
+	case ItemStatusAccepted:
+		return 4
+	default:
+		return -1
+	}
+}
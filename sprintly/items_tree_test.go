@@ -0,0 +1,135 @@
+package sprintly
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+var testingTreeItemsJson = `[
+	{"number": 1, "type": "story", "score": "L", "status": "in-progress"},
+	{"number": 2, "type": "task", "score": "S", "status": "accepted", "parent": {"number": 1}},
+	{"number": 3, "type": "task", "score": "M", "status": "backlog", "parent": 1},
+	{"number": 4, "type": "defect", "score": "~", "status": "someday"}
+]`
+
+func TestItems_Tree(t *testing.T) {
+	client, server, mux := setup()
+	defer server.Close()
+
+	mux.HandleFunc("/products/1/items.json", func(w http.ResponseWriter, r *http.Request) {
+		ensureMethod(t, r, "GET")
+		if v := r.URL.Query().Get("children"); v != "true" {
+			t.Errorf("children = %q, want true", v)
+		}
+		if v := r.URL.Query().Get("status"); v != "someday,backlog,in-progress,completed,accepted" {
+			t.Errorf("status = %q, want all the statuses", v)
+		}
+		if v := r.URL.Query().Get("offset"); v != "" {
+			fmt.Fprint(w, "[]")
+			return
+		}
+		fmt.Fprint(w, testingTreeItemsJson)
+	})
+
+	tree, err := client.Items.Tree(1, nil)
+	if err != nil {
+		t.Errorf("Items.Tree failed: %v", err)
+		return
+	}
+
+	if n := tree.Len(); n != 4 {
+		t.Errorf("tree.Len() = %v, want 4", n)
+	}
+	if n := len(tree.Roots); n != 2 {
+		t.Errorf("len(tree.Roots) = %v, want 2", n)
+	}
+
+	story := tree.Node(1)
+	if n := len(story.Children); n != 2 {
+		t.Errorf("len(story.Children) = %v, want 2", n)
+	}
+	if p := tree.Node(3).Parent; p != story {
+		t.Errorf("task parent = %v, want the story node", p)
+	}
+	if score := story.RollupScore(); score != 9 {
+		t.Errorf("story.RollupScore() = %v, want 9", score)
+	}
+	if status := story.RollupStatus(); status != ItemStatusInProgress {
+		t.Errorf("story.RollupStatus() = %v, want %v", status, ItemStatusInProgress)
+	}
+
+	var walked []int
+	tree.Walk(func(node *ItemNode, depth int) error {
+		walked = append(walked, node.Item.Number*10+depth)
+		return nil
+	})
+	ensureEqual(t, walked, []int{10, 21, 31, 40})
+}
+
+func TestItems_Tree_Root(t *testing.T) {
+	client, server, mux := setup()
+	defer server.Close()
+
+	mux.HandleFunc("/products/1/items/1.json", func(w http.ResponseWriter, r *http.Request) {
+		ensureMethod(t, r, "GET")
+		fmt.Fprint(w, `{"number": 1, "type": "story", "status": "accepted"}`)
+	})
+	mux.HandleFunc("/products/1/items/1/children.json", func(w http.ResponseWriter, r *http.Request) {
+		ensureMethod(t, r, "GET")
+		fmt.Fprint(w, `[
+			{"number": 2, "type": "task", "status": "accepted", "parent": 1},
+			{"number": 3, "type": "task", "status": "completed", "parent": 1}
+		]`)
+	})
+	for _, number := range []int{2, 3} {
+		mux.HandleFunc(fmt.Sprintf("/products/1/items/%v/children.json", number),
+			func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, "[]")
+			})
+	}
+
+	tree, err := client.Items.Tree(1, &ItemTreeArgs{Root: 1, Concurrency: 1})
+	if err != nil {
+		t.Errorf("Items.Tree failed: %v", err)
+		return
+	}
+
+	if n := len(tree.Roots); n != 1 {
+		t.Errorf("len(tree.Roots) = %v, want 1", n)
+		return
+	}
+	if status := tree.Roots[0].RollupStatus(); status != ItemStatusCompleted {
+		t.Errorf("RollupStatus() = %v, want %v", status, ItemStatusCompleted)
+	}
+}
+
+func TestItems_ListAll(t *testing.T) {
+	client, server, mux := setup()
+	defer server.Close()
+
+	mux.HandleFunc("/products/1/items.json", func(w http.ResponseWriter, r *http.Request) {
+		ensureMethod(t, r, "GET")
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		if limit := r.URL.Query().Get("limit"); limit != "2" {
+			t.Errorf("limit = %v, want 2", limit)
+		}
+		switch offset {
+		case 0:
+			fmt.Fprint(w, `[{"number": 1}, {"number": 2}]`)
+		case 2:
+			fmt.Fprint(w, `[{"number": 3}]`)
+		default:
+			t.Errorf("Unexpected offset: %v", offset)
+		}
+	})
+
+	items, _, err := client.Items.ListAll(1, &ItemListArgs{Limit: 2})
+	if err != nil {
+		t.Errorf("Items.ListAll failed: %v", err)
+		return
+	}
+
+	ensureEqual(t, items, []Item{{Number: 1}, {Number: 2}, {Number: 3}})
+}
@@ -0,0 +1,224 @@
+package sprintly
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Story represents the who/what/why triple Sprintly stories are made of.
+type Story struct {
+	Who  string
+	What string
+	Why  string
+}
+
+// String renders the story in the canonical form, i.e.
+//
+//	As a customer, I want to export invoices so that I can file taxes.
+func (story *Story) String() string {
+	var b strings.Builder
+	b.WriteString("As ")
+	b.WriteString(article(story.Who))
+	b.WriteString(" ")
+	b.WriteString(story.Who)
+	b.WriteString(", I want ")
+	b.WriteString(story.What)
+	if story.Why != "" {
+		b.WriteString(" so that ")
+		b.WriteString(story.Why)
+	}
+	b.WriteString(".")
+	return b.String()
+}
+
+// CreateArgs returns the arguments that can be passed into Items.Create
+// to create a story out of the given who/what/why triple.
+func (story *Story) CreateArgs() *ItemCreateArgs {
+	return &ItemCreateArgs{
+		Type: string(ItemTypeStory),
+		Who:  story.Who,
+		What: story.What,
+		Why:  story.Why,
+	}
+}
+
+// FormatStory renders the story fields of the given item in the canonical form.
+func FormatStory(item *Item) string {
+	story := &Story{
+		Who:  item.Who,
+		What: item.What,
+		Why:  item.Why,
+	}
+	return story.String()
+}
+
+// StoryParser can be used to turn free text user stories into Story objects.
+//
+// The phrases are matched case-insensitively and on word boundaries,
+// the longest matching phrase always wins.
+type StoryParser struct {
+	// Phrases introducing the who part, e.g. "as a".
+	WhoPhrases []string
+
+	// Phrases introducing the what part, e.g. "I want".
+	WhatPhrases []string
+
+	// Phrases introducing the why part, e.g. "so that".
+	WhyPhrases []string
+}
+
+// DefaultStoryParser is the parser used by ParseStory.
+//
+// A bare "so" does not introduce the why part, as it occurs in plain
+// what parts, e.g. "to export so-called reports".
+var DefaultStoryParser = &StoryParser{
+	WhoPhrases:  []string{"as an", "as a", "as the", "as"},
+	WhatPhrases: []string{"i want", "i would like", "i'd like", "i need", "i wish"},
+	WhyPhrases:  []string{"so that"},
+}
+
+// ParseStory parses the given text using DefaultStoryParser.
+func ParseStory(text string) (*Story, error) {
+	return DefaultStoryParser.Parse(text)
+}
+
+// Parse turns the given text into a Story.
+//
+// The who and what parts are required, the why part is optional.
+func (parser *StoryParser) Parse(text string) (*Story, error) {
+	text = strings.TrimSpace(text)
+	lower := lowerPreservingOffsets(text)
+	whoPhrases := sortPhrases(parser.WhoPhrases)
+	whatPhrases := sortPhrases(parser.WhatPhrases)
+	whyPhrases := sortPhrases(parser.WhyPhrases)
+
+	// The who phrase must start the story.
+	whoPhrase := matchPhrase(lower, 0, whoPhrases)
+	if whoPhrase == "" {
+		return nil, errors.New("StoryParser.Parse: who phrase not found")
+	}
+	whoStart := len(whoPhrase)
+
+	// The what phrase follows the who part.
+	whatIndex, whatPhrase := findPhrase(lower, whoStart, whatPhrases)
+	if whatIndex == -1 {
+		return nil, errors.New("StoryParser.Parse: what phrase not found")
+	}
+	whatStart := whatIndex + len(whatPhrase)
+
+	story := &Story{
+		Who: trimStoryPart(text[whoStart:whatIndex]),
+	}
+
+	// The why phrase is optional.
+	whyIndex, whyPhrase := findPhrase(lower, whatStart, whyPhrases)
+	if whyIndex == -1 {
+		story.What = trimStoryPart(text[whatStart:])
+	} else {
+		story.What = trimStoryPart(text[whatStart:whyIndex])
+		story.Why = trimStoryPart(text[whyIndex+len(whyPhrase):])
+	}
+
+	if story.Who == "" {
+		return nil, errors.New("StoryParser.Parse: who is empty")
+	}
+	if story.What == "" {
+		return nil, errors.New("StoryParser.Parse: what is empty")
+	}
+	return story, nil
+}
+
+// sortPhrases returns the lowercased phrases, the longest first.
+func sortPhrases(phrases []string) []string {
+	sorted := make([]string, 0, len(phrases))
+	for _, phrase := range phrases {
+		if phrase = lowerPreservingOffsets(phrase); phrase != "" {
+			sorted = append(sorted, phrase)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return len(sorted[i]) > len(sorted[j])
+	})
+	return sorted
+}
+
+// findPhrase returns the position of the first phrase occurring in s
+// on a word boundary at or after the given offset.
+func findPhrase(s string, offset int, phrases []string) (int, string) {
+	for i := range s[offset:] {
+		i += offset
+		if i != 0 {
+			if r, _ := utf8.DecodeLastRuneInString(s[:i]); !isStoryBoundary(r) {
+				continue
+			}
+		}
+		if phrase := matchPhrase(s, i, phrases); phrase != "" {
+			return i, phrase
+		}
+	}
+	return -1, ""
+}
+
+// matchPhrase returns the longest phrase that s contains at the given position,
+// the phrases being sorted by sortPhrases.
+func matchPhrase(s string, pos int, phrases []string) string {
+	for _, phrase := range phrases {
+		if !strings.HasPrefix(s[pos:], phrase) {
+			continue
+		}
+		end := pos + len(phrase)
+		if r, _ := utf8.DecodeRuneInString(s[end:]); end == len(s) || isStoryBoundary(r) {
+			return phrase
+		}
+	}
+	return ""
+}
+
+func isStoryBoundary(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+}
+
+// lowerPreservingOffsets lowercases the letters whose lowercase form has the same
+// length in UTF-8, so that the byte offsets into the lowercased string apply to s.
+func lowerPreservingOffsets(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for i, r := range s {
+		if r == utf8.RuneError {
+			// Keep invalid bytes as they are, WriteRune would expand them.
+			_, size := utf8.DecodeRuneInString(s[i:])
+			b.WriteString(s[i : i+size])
+			continue
+		}
+		if lower := unicode.ToLower(r); utf8.RuneLen(lower) == utf8.RuneLen(r) {
+			r = lower
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func trimStoryPart(part string) string {
+	return strings.TrimFunc(part, func(r rune) bool {
+		return unicode.IsSpace(r) || strings.ContainsRune(",.;:!", r)
+	})
+}
+
+// article returns the indefinite article for the given word. Words starting
+// with a "u" pronounced as "you", e.g. user or unit, take "a".
+func article(word string) string {
+	lower := strings.ToLower(word)
+	r, _ := utf8.DecodeRuneInString(lower)
+	if !strings.ContainsRune("aeiou", r) {
+		return "a"
+	}
+	for _, prefix := range []string{"uni", "use", "usu", "uti", "ure", "uro"} {
+		if strings.HasPrefix(lower, prefix) {
+			return "a"
+		}
+	}
+	return "an"
+}
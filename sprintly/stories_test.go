@@ -0,0 +1,126 @@
+package sprintly
+
+import (
+	"testing"
+)
+
+func TestParseStory(t *testing.T) {
+	cases := []struct {
+		text string
+		want *Story
+	}{
+		{
+			"As a customer I want to export invoices so that I can file taxes",
+			&Story{"customer", "to export invoices", "I can file taxes"},
+		},
+		{
+			"as an admin, I would like a CSV export, so that I can archive data.",
+			&Story{"admin", "a CSV export", "I can archive data"},
+		},
+		{
+			"As the product owner I need a burndown chart",
+			&Story{"product owner", "a burndown chart", ""},
+		},
+		{
+			"As an accountant I want to export so-called reports so that I can do so much faster",
+			&Story{"accountant", "to export so-called reports", "I can do so much faster"},
+		},
+		{
+			"As a user I want to do so much",
+			&Story{"user", "to do so much", ""},
+		},
+	}
+
+	for _, c := range cases {
+		got, err := ParseStory(c.text)
+		if err != nil {
+			t.Errorf("ParseStory(%q) failed: %v", c.text, err)
+			continue
+		}
+		ensureEqual(t, got, c.want)
+	}
+}
+
+func TestParseStory_Invalid(t *testing.T) {
+	for _, text := range []string{
+		"",
+		"Export invoices",
+		"As a customer",
+		"As a I want it",
+	} {
+		if _, err := ParseStory(text); err == nil {
+			t.Errorf("ParseStory(%q) should have failed", text)
+		}
+	}
+}
+
+func TestStoryParser_Parse(t *testing.T) {
+	parser := &StoryParser{
+		WhoPhrases:  []string{"als"},
+		WhatPhrases: []string{"möchte ich"},
+		WhyPhrases:  []string{"damit"},
+	}
+
+	got, err := parser.Parse("Als Kunde möchte ich Rechnungen exportieren, damit ich Steuern zahlen kann")
+	if err != nil {
+		t.Errorf("StoryParser.Parse failed: %v", err)
+		return
+	}
+
+	ensureEqual(t, got, &Story{"Kunde", "Rechnungen exportieren", "ich Steuern zahlen kann"})
+
+	// Non-ASCII letters are lowercased and are not word boundaries.
+	parser = &StoryParser{
+		WhoPhrases:  []string{"jako"},
+		WhatPhrases: []string{"chci"},
+		WhyPhrases:  []string{"abych"},
+	}
+	got, err = parser.Parse("JAKO účetní CHCI exportovat faktury, ABYCH nemusel přepisovat čísla")
+	if err != nil {
+		t.Errorf("StoryParser.Parse failed: %v", err)
+		return
+	}
+	ensureEqual(t, got, &Story{"účetní", "exportovat faktury", "nemusel přepisovat čísla"})
+
+	if _, err := parser.Parse("Jako účetníchci exportovat faktury"); err == nil {
+		t.Error("StoryParser.Parse matched a phrase inside a word")
+	}
+}
+
+func TestStory_Article(t *testing.T) {
+	for who, want := range map[string]string{
+		"user":           "As a user",
+		"umpire":         "As an umpire",
+		"Unknown caller": "As an Unknown caller",
+		"editor":         "As an editor",
+		"customer":       "As a customer",
+		"účetní":         "As a účetní",
+	} {
+		story := &Story{Who: who, What: "it"}
+		if got := story.String(); got != want+", I want it." {
+			t.Errorf("Story{Who: %q}.String() = %q", who, got)
+		}
+	}
+}
+
+func TestFormatStory(t *testing.T) {
+	item := &Item{
+		Who:  "user",
+		What: "not to be able to move un-scored items out of the backlog",
+		Why:  "it does not make any sense",
+	}
+
+	got := FormatStory(item)
+	want := "As a user, I want not to be able to move un-scored items out of the backlog" +
+		" so that it does not make any sense."
+	if got != want {
+		t.Errorf("FormatStory returned %q, want %q", got, want)
+	}
+
+	story, err := ParseStory(got)
+	if err != nil {
+		t.Errorf("ParseStory failed: %v", err)
+		return
+	}
+	ensureEqual(t, story, &Story{item.Who, item.What, item.Why})
+}
@@ -0,0 +1,150 @@
+package sprintly
+
+import (
+	"errors"
+	"net/http"
+)
+
+// TransitionRule is a precondition checked before an item is moved to another status.
+//
+// A rule returns a non-nil error describing the reason in case the transition
+// of the given item to the given status is not permitted.
+type TransitionRule func(item *Item, to ItemStatus) error
+
+// Workflow describes how items of a product can move between statuses.
+type Workflow struct {
+	// Transitions maps every status to the statuses an item can be moved to.
+	Transitions map[ItemStatus][]ItemStatus
+
+	// Rules are checked in order for every transition permitted by Transitions.
+	Rules []TransitionRule
+}
+
+// DefaultWorkflow is the workflow used for products with no workflow set.
+var DefaultWorkflow = &Workflow{
+	Transitions: map[ItemStatus][]ItemStatus{
+		ItemStatusSomeday:    {ItemStatusBacklog, ItemStatusInProgress},
+		ItemStatusBacklog:    {ItemStatusSomeday, ItemStatusInProgress},
+		ItemStatusInProgress: {ItemStatusBacklog, ItemStatusCompleted},
+		ItemStatusCompleted:  {ItemStatusInProgress, ItemStatusAccepted},
+		ItemStatusAccepted:   {ItemStatusInProgress},
+	},
+	Rules: []TransitionRule{
+		RequireScore,
+	},
+}
+
+// RequireScore makes sure that the item is scored before it leaves the backlog.
+func RequireScore(item *Item, to ItemStatus) error {
+	if !item.Status.isBacklog() || to.isBacklog() {
+		return nil
+	}
+	if item.Score == "" || item.Score == ItemScoreUnset {
+		return errors.New("score not set")
+	}
+	return nil
+}
+
+// RequireAssignee makes sure that the item is assigned before it leaves the backlog.
+func RequireAssignee(item *Item, to ItemStatus) error {
+	if !item.Status.isBacklog() || to.isBacklog() {
+		return nil
+	}
+	if item.AssignedTo == nil || item.AssignedTo.Id == 0 {
+		return errors.New("item not assigned")
+	}
+	return nil
+}
+
+func (status ItemStatus) isBacklog() bool {
+	return status == ItemStatusSomeday || status == ItemStatusBacklog
+}
+
+// CanTransition returns true when the workflow permits moving items
+// from one status to the other, not taking Rules into account.
+func (wf *Workflow) CanTransition(from, to ItemStatus) bool {
+	for _, status := range wf.Transitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks whether the given item can be moved to the given status.
+//
+// The error returned is always of type *ErrTransition.
+func (wf *Workflow) Validate(item *Item, to ItemStatus) error {
+	if !wf.CanTransition(item.Status, to) {
+		return &ErrTransition{item.Number, item.Status, to, "transition not allowed"}
+	}
+	for _, rule := range wf.Rules {
+		if err := rule(item, to); err != nil {
+			return &ErrTransition{item.Number, item.Status, to, err.Error()}
+		}
+	}
+	return nil
+}
+
+// SetWorkflow sets the workflow to be used for the items of the given product.
+// Passing a nil workflow reverts the product back to DefaultWorkflow.
+//
+// SetWorkflow is not safe to be called concurrently with the other methods.
+func (srv ItemsService) SetWorkflow(productId int, wf *Workflow) {
+	if wf == nil {
+		delete(srv.workflows, productId)
+		return
+	}
+	srv.workflows[productId] = wf
+}
+
+// Workflow returns the workflow used for the items of the given product.
+func (srv ItemsService) Workflow(productId int) *Workflow {
+	if wf, ok := srv.workflows[productId]; ok {
+		return wf
+	}
+	return DefaultWorkflow
+}
+
+// Transition can be used to move the given item to another status.
+//
+// The item is fetched first and validated using the product workflow.
+// No update is sent in case the item is already in the requested status.
+func (srv ItemsService) Transition(
+	productId int,
+	itemNumber int,
+	to ItemStatus,
+) (*Item, *http.Response, error) {
+
+	item, resp, err := srv.Get(productId, itemNumber)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	if item.Status == to {
+		return item, resp, nil
+	}
+
+	if err := srv.Workflow(productId).Validate(item, to); err != nil {
+		return nil, nil, err
+	}
+
+	return srv.Update(productId, itemNumber, &ItemUpdateArgs{
+		Status: to,
+	})
+}
+
+// Start moves the given item to the in-progress status.
+func (srv ItemsService) Start(productId, itemNumber int) (*Item, *http.Response, error) {
+	return srv.Transition(productId, itemNumber, ItemStatusInProgress)
+}
+
+// Complete moves the given item to the completed status.
+func (srv ItemsService) Complete(productId, itemNumber int) (*Item, *http.Response, error) {
+	return srv.Transition(productId, itemNumber, ItemStatusCompleted)
+}
+
+// Accept moves the given item to the accepted status.
+func (srv ItemsService) Accept(productId, itemNumber int) (*Item, *http.Response, error) {
+	return srv.Transition(productId, itemNumber, ItemStatusAccepted)
+}
@@ -0,0 +1,16 @@
+package sprintly
+
+import (
+	"fmt"
+)
+
+type ErrTransition struct {
+	ItemNumber int
+	From       ItemStatus
+	To         ItemStatus
+	Reason     string
+}
+
+func (err *ErrTransition) Error() string {
+	return fmt.Sprintf("item #%v: %v -> %v rejected (%v)", err.ItemNumber, err.From, err.To, err.Reason)
+}
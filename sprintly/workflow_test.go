@@ -0,0 +1,99 @@
+package sprintly
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestWorkflow_Validate(t *testing.T) {
+	scored := testingTask
+	unscored := testingTask
+	unscored.Score = ItemScoreUnset
+	accepted := testingTask
+	accepted.Status = ItemStatusAccepted
+
+	cases := []struct {
+		item *Item
+		to   ItemStatus
+		ok   bool
+	}{
+		{&scored, ItemStatusInProgress, true},
+		{&scored, ItemStatusSomeday, true},
+		{&scored, ItemStatusAccepted, false},
+		{&unscored, ItemStatusInProgress, false},
+		{&unscored, ItemStatusSomeday, true},
+		{&accepted, ItemStatusInProgress, true},
+		{&accepted, ItemStatusBacklog, false},
+	}
+
+	for _, c := range cases {
+		err := DefaultWorkflow.Validate(c.item, c.to)
+		if c.ok && err != nil {
+			t.Errorf("%v -> %v (score %v) rejected: %v", c.item.Status, c.to, c.item.Score, err)
+		}
+		if !c.ok {
+			if _, ok := err.(*ErrTransition); !ok {
+				t.Errorf("%v -> %v (score %v) not rejected, err = %v", c.item.Status, c.to, c.item.Score, err)
+			}
+		}
+	}
+}
+
+func TestItems_Start(t *testing.T) {
+	client, server, mux := setup()
+	defer server.Close()
+
+	mux.HandleFunc("/products/1/items/188.json", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			fmt.Fprint(w, testingTaskString)
+		case "POST":
+			var got ItemUpdateArgs
+			if err := decodeArgs(&got, r); err != nil {
+				t.Error(err)
+				return
+			}
+			ensureEqual(t, &got, &ItemUpdateArgs{Status: ItemStatusInProgress})
+			fmt.Fprint(w, testingTaskString)
+		default:
+			t.Errorf("Unexpected request method: %v", r.Method)
+		}
+	})
+
+	if _, _, err := client.Items.Start(1, 188); err != nil {
+		t.Errorf("Items.Start failed: %v", err)
+	}
+}
+
+func TestItems_Start_Rejected(t *testing.T) {
+	client, server, mux := setup()
+	defer server.Close()
+
+	mux.HandleFunc("/products/1/items/188.json", func(w http.ResponseWriter, r *http.Request) {
+		ensureMethod(t, r, "GET")
+		fmt.Fprint(w, testingTaskString)
+	})
+
+	client.Items.SetWorkflow(1, &Workflow{
+		Transitions: DefaultWorkflow.Transitions,
+		Rules: []TransitionRule{
+			RequireScore,
+			RequireAssignee,
+			func(item *Item, to ItemStatus) error {
+				return errors.New("frozen")
+			},
+		},
+	})
+
+	_, _, err := client.Items.Start(1, 188)
+	if _, ok := err.(*ErrTransition); !ok {
+		t.Errorf("Items.Start should have been rejected, err = %v", err)
+	}
+
+	client.Items.SetWorkflow(1, nil)
+	if wf := client.Items.Workflow(1); wf != DefaultWorkflow {
+		t.Errorf("Items.Workflow did not revert to DefaultWorkflow")
+	}
+}
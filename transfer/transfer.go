@@ -0,0 +1,236 @@
+// Package transfer copies and moves Sprintly items, along with their children,
+// from one product to another.
+//
+// Sprintly items cannot change products, so the items are recreated in the target
+// product: the fields and the tags are copied as they are, the assignees are matched
+// by email among the members of the target product and the parent/child links are
+// restored. Moving an item copies it and archives the originals:
+//
+//	res, err := transfer.Move(client, 1, 188, 2, &transfer.Options{Comments: true, Link: true})
+//	fmt.Printf("#188 is #%v now\n", res.Items[188])
+//
+// The API client does not support attachments, so they are not transferred.
+package transfer
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+// Options configure a transfer.
+type Options struct {
+	// Comments copies the comments of the items, prefixed with the author and the time.
+	Comments bool
+
+	// Link leaves a comment on every original item and its copy, linking one to the other.
+	Link bool
+}
+
+// Result describes a transfer, including a partial one that failed.
+type Result struct {
+	// Items maps the numbers of the original items to the numbers of their copies.
+	Items map[int]int `json:"items"`
+
+	// Created lists the copies, parents before their children.
+	Created []sprintly.Item `json:"created"`
+
+	// Unlinked lists the numbers of the copies that could not be linked
+	// to the copies of their parents.
+	Unlinked []int `json:"unlinked,omitempty"`
+
+	// Unassigned lists the emails of the assignees that are not members
+	// of the target product, their items are left unassigned.
+	Unassigned []string `json:"unassigned,omitempty"`
+
+	// Archived lists the numbers of the original items archived.
+	Archived []int `json:"archived,omitempty"`
+}
+
+// Copy copies the item and all its descendants from one product to another.
+//
+// In case the copy fails midway, the result returned along with the error
+// describes the items copied so far.
+func Copy(client *sprintly.Client, from, itemNumber, to int, opts *Options) (*Result, error) {
+	return newTransfer(client, from, to, opts, "Copied").run(itemNumber)
+}
+
+// Move copies the item and all its descendants from one product to another
+// and archives the originals, children first. The originals are only archived
+// once all the items are copied.
+func Move(client *sprintly.Client, from, itemNumber, to int, opts *Options) (*Result, error) {
+	t := newTransfer(client, from, to, opts, "Moved")
+	res, err := t.run(itemNumber)
+	if err != nil {
+		return res, err
+	}
+
+	originals := make(map[int]int, len(res.Items))
+	for original, copied := range res.Items {
+		originals[copied] = original
+	}
+	for _, item := range slices.Backward(res.Created) {
+		number := originals[item.Number]
+		if _, _, err := client.Items.Archive(from, number); err != nil {
+			return res, fmt.Errorf("archiving #%v: %w", number, err)
+		}
+		res.Archived = append(res.Archived, number)
+	}
+	return res, nil
+}
+
+type transfer struct {
+	client   *sprintly.Client
+	from, to int
+	opts     Options
+	verb     string
+
+	// people maps the lowercase emails to the user IDs in the target product.
+	people map[string]int
+	res    *Result
+}
+
+func newTransfer(client *sprintly.Client, from, to int, opts *Options, verb string) *transfer {
+	t := &transfer{
+		client: client,
+		from:   from,
+		to:     to,
+		verb:   verb,
+		res:    &Result{Items: make(map[int]int)},
+	}
+	if opts != nil {
+		t.opts = *opts
+	}
+	return t
+}
+
+func (t *transfer) run(itemNumber int) (*Result, error) {
+	if t.from == t.to {
+		return nil, fmt.Errorf("transfer: #%v is in product %v already", itemNumber, t.to)
+	}
+
+	tree, err := t.client.Items.Tree(t.from, &sprintly.ItemTreeArgs{Root: itemNumber})
+	if err != nil {
+		return nil, err
+	}
+
+	people, _, err := t.client.People.List(t.to)
+	if err != nil {
+		return nil, err
+	}
+	t.people = make(map[string]int, len(people))
+	for _, user := range people {
+		t.people[strings.ToLower(user.Email)] = user.Id
+	}
+
+	err = tree.Walk(func(node *sprintly.ItemNode, depth int) error {
+		if err := t.copyItem(node); err != nil {
+			return fmt.Errorf("copying #%v: %w", node.Item.Number, err)
+		}
+		return nil
+	})
+	return t.res, err
+}
+
+// copyItem creates the copy of the item, its parent being copied already.
+func (t *transfer) copyItem(node *sprintly.ItemNode) error {
+	original := node.Item
+
+	created, _, err := t.client.Items.Create(t.to, t.createArgs(original))
+	if err != nil {
+		return err
+	}
+	// The copy exists from now on, so it is part of the result even when the rest fails.
+	t.res.Items[original.Number] = created.Number
+	t.res.Created = append(t.res.Created, *created)
+
+	if node.Parent != nil {
+		args := &sprintly.ItemUpdateArgs{Parent: t.res.Items[node.Parent.Item.Number]}
+		linked, _, err := t.client.Items.Update(t.to, created.Number, args)
+		if err != nil {
+			t.res.Unlinked = append(t.res.Unlinked, created.Number)
+			return fmt.Errorf("linking copy #%v to its parent: %w", created.Number, err)
+		}
+		created = linked
+		t.res.Created[len(t.res.Created)-1] = *linked
+	}
+
+	if t.opts.Comments {
+		comments, _, err := t.client.Comments.List(t.from, original.Number)
+		if err != nil {
+			return err
+		}
+		for _, comment := range comments {
+			args := &sprintly.CommentCreateArgs{Body: copiedComment(&comment)}
+			if _, _, err := t.client.Comments.Create(t.to, created.Number, args); err != nil {
+				return err
+			}
+		}
+	}
+
+	if t.opts.Link {
+		link := &sprintly.CommentCreateArgs{Body: linkComment(t.verb+" from", t.from, original)}
+		if _, _, err := t.client.Comments.Create(t.to, created.Number, link); err != nil {
+			return err
+		}
+		link = &sprintly.CommentCreateArgs{Body: linkComment(t.verb+" to", t.to, created)}
+		if _, _, err := t.client.Comments.Create(t.from, original.Number, link); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// linkComment returns the body of the comment linking to the given item.
+func linkComment(action string, productId int, item *sprintly.Item) string {
+	body := fmt.Sprintf("%v product %v, item #%v", action, productId, item.Number)
+	if item.ShortURL != "" {
+		body += ": " + item.ShortURL
+	}
+	return body
+}
+
+// createArgs maps the original item to the arguments creating its copy.
+func (t *transfer) createArgs(item *sprintly.Item) *sprintly.ItemCreateArgs {
+	args := &sprintly.ItemCreateArgs{
+		Type:        item.Type,
+		Description: item.Description,
+		Score:       item.Score,
+		Status:      item.Status,
+		Tags:        item.Tags,
+	}
+	if item.Type == string(sprintly.ItemTypeStory) {
+		args.Who, args.What, args.Why = item.Who, item.What, item.Why
+	} else {
+		args.Title = item.Title
+	}
+
+	if assignee := item.AssignedTo; assignee != nil && assignee.Email != "" {
+		if id, ok := t.people[strings.ToLower(assignee.Email)]; ok {
+			args.AssignedTo = id
+		} else if !slices.Contains(t.res.Unassigned, assignee.Email) {
+			t.res.Unassigned = append(t.res.Unassigned, assignee.Email)
+		}
+	}
+	return args
+}
+
+// copiedComment returns the body of the copy of the comment,
+// crediting the original author.
+func copiedComment(comment *sprintly.Comment) string {
+	author := "Someone"
+	if user := comment.CreatedBy; user != nil {
+		author = strings.TrimSpace(user.FirstName + " " + user.LastName)
+		if author == "" {
+			author = user.Email
+		}
+	}
+	if comment.CreatedAt != nil {
+		return fmt.Sprintf("**%v** wrote on %v:\n\n%v",
+			author, comment.CreatedAt.UTC().Format(time.DateTime), comment.Body)
+	}
+	return fmt.Sprintf("**%v** wrote:\n\n%v", author, comment.Body)
+}
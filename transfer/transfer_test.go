@@ -0,0 +1,130 @@
+package transfer
+
+import (
+	"errors"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/salsita/go-sprintly/internal/sprintlytest"
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+// newServer serves product 1 holding story 10 with tasks 11 and 12,
+// 12 being a child of 11, and an empty product 2.
+func newServer(t *testing.T) *sprintlytest.Server {
+	s := sprintlytest.NewServer(t)
+	s.AddItems(1,
+		sprintly.Item{Number: 10, Type: "story", Who: "user", What: "to move items", Why: "products change",
+			Title: "As a user, I want to move items", Status: "backlog", Score: "L",
+			AssignedTo: &sprintly.User{Id: 7, Email: "Joe@Joestump.net"}, ShortURL: "http://sprint.ly/i/1/10"},
+		sprintly.Item{Number: 11, Type: "task", Title: "Copy the fields", Tags: []string{"api"}, Parent: float64(10),
+			AssignedTo: &sprintly.User{Id: 8, Email: "ondra@salsitasoft.com"}},
+		sprintly.Item{Number: 12, Type: "task", Title: "Restore the links", Parent: map[string]interface{}{"number": float64(11)}},
+	)
+	s.AddComments(1, 11, sprintly.Comment{Body: "Started.", CreatedBy: &sprintly.User{FirstName: "Ondrej"}})
+	s.AddPeople(2, sprintly.User{Id: 1, Email: "joe@joestump.net"})
+	return s
+}
+
+// bodies returns the bodies of the comments of the item.
+func bodies(s *sprintlytest.Server, productId, number int) []string {
+	var bodies []string
+	for _, comment := range s.Comments(productId, number) {
+		bodies = append(bodies, comment.Body)
+	}
+	return bodies
+}
+
+func TestMove(t *testing.T) {
+	s := newServer(t)
+
+	res, err := Move(s.Client, 1, 10, 2, &Options{Comments: true, Link: true})
+	if err != nil {
+		t.Fatalf("Move failed: %v", err)
+	}
+
+	if want := map[int]int{10: 1, 11: 2, 12: 3}; !maps.Equal(res.Items, want) {
+		t.Errorf("Items = %v, want %v", res.Items, want)
+	}
+	if !slices.Equal(res.Unassigned, []string{"ondra@salsitasoft.com"}) {
+		t.Errorf("Unassigned = %v", res.Unassigned)
+	}
+	if !slices.Equal(res.Archived, []int{12, 11, 10}) {
+		t.Errorf("Archived = %v, want children first", res.Archived)
+	}
+	if !s.Item(1, 10).Archived || !s.Item(1, 12).Archived {
+		t.Error("the originals were not archived")
+	}
+
+	story := s.Item(2, 1)
+	if story.Who != "user" || story.Title != "" || story.Score != "L" || story.AssignedTo == nil {
+		t.Errorf("unexpected story copy: %+v", story)
+	}
+	if task := s.Item(2, 2); task.Parent != float64(1) || !slices.Equal(task.Tags, []string{"api"}) || task.AssignedTo != nil {
+		t.Errorf("unexpected task copy: %+v", task)
+	}
+	if task := s.Item(2, 3); task.Parent != float64(2) {
+		t.Errorf("unexpected subtask copy: %+v", task)
+	}
+
+	comments := bodies(s, 2, 2)
+	if len(comments) != 2 || comments[0] != "**Ondrej** wrote:\n\nStarted." ||
+		comments[1] != "Moved from product 1, item #11" {
+		t.Errorf("comments on the copy: %q", comments)
+	}
+	if comments := bodies(s, 1, 10); len(comments) != 1 || comments[0] != "Moved to product 2, item #1: https://sprint.ly/i/2/1" {
+		t.Errorf("comments on the original: %q", comments)
+	}
+}
+
+func TestCopy_Failure(t *testing.T) {
+	s := newServer(t)
+
+	// Comments cannot be copied, the copy stops after the first item.
+	s.Intercept = func(r *http.Request) error {
+		if strings.HasSuffix(r.URL.Path, "/comments.json") {
+			return errors.New("comments unavailable")
+		}
+		return nil
+	}
+	res, err := Copy(s.Client, 1, 10, 2, &Options{Comments: true})
+	if err == nil {
+		t.Fatal("Copy should have failed")
+	}
+	if len(res.Created) != 1 || len(res.Archived) != 0 {
+		t.Errorf("partial result = %+v", res)
+	}
+
+	if _, err := Copy(s.Client, 1, 10, 1, nil); err == nil {
+		t.Error("Copy within the same product should have failed")
+	}
+}
+
+func TestCopy_LinkFailure(t *testing.T) {
+	s := newServer(t)
+
+	// The parent of the first child cannot be set, the copy stays unlinked.
+	s.Intercept = func(r *http.Request) error {
+		if r.Method == "POST" && r.PostForm.Get("parent") != "" {
+			return errors.New("parent unavailable")
+		}
+		return nil
+	}
+	res, err := Copy(s.Client, 1, 10, 2, nil)
+	if err == nil {
+		t.Fatal("Copy should have failed")
+	}
+
+	if want := map[int]int{10: 1, 11: 2}; !maps.Equal(res.Items, want) {
+		t.Errorf("Items = %v, want %v", res.Items, want)
+	}
+	if len(res.Created) != 2 || res.Created[1].Number != 2 || !slices.Equal(res.Unlinked, []int{2}) {
+		t.Errorf("partial result = %+v", res)
+	}
+	if copied := s.Item(2, 2); copied == nil || copied.Parent != nil {
+		t.Errorf("unexpected copy of #11: %+v", copied)
+	}
+}
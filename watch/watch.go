@@ -0,0 +1,358 @@
+// Package watch polls a Sprintly product for item changes, for the environments
+// that cannot receive webhooks.
+//
+// The watcher lists the recently modified items using ItemOrderingRecent and
+// compares them with the snapshot taken by the previous poll:
+//
+//	w := &watch.Watcher{Client: client, ProductId: 1, Checkpoint: "watch.json"}
+//	events := make(chan watch.Event)
+//	go func() {
+//		for ev := range events {
+//			fmt.Println(ev)
+//		}
+//	}()
+//	err := w.Run(ctx, events)
+//
+// The first poll only takes the snapshot of all the product items. The snapshot
+// is stored in the checkpoint file once the events of a poll are delivered, so
+// a restarted watcher does not replay the changes it has already emitted. Changes
+// not delivered because the watcher was stopped are emitted again after a restart.
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/salsita/go-sprintly/internal/atomicfile"
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+// DefaultInterval is the time between two polls used when Watcher.Interval is not set.
+const DefaultInterval = time.Minute
+
+// pageSize is the number of items fetched per request.
+const pageSize = 100
+
+// EventKind identifies the kind of change an Event represents.
+type EventKind string
+
+const (
+	ItemCreated   EventKind = "created"
+	StatusChanged EventKind = "status"
+	Reassigned    EventKind = "reassigned"
+	Rescored      EventKind = "rescored"
+	Retagged      EventKind = "retagged"
+)
+
+// Event represents a single change of an item.
+//
+// An update changing several fields at once produces an event for every field.
+type Event struct {
+	Kind EventKind
+
+	// Item is the current version of the item.
+	Item *sprintly.Item
+
+	// Previous is the version of the item seen by the previous poll, nil for new items.
+	Previous *sprintly.Item
+}
+
+func (ev Event) String() string {
+	switch ev.Kind {
+	case ItemCreated:
+		return fmt.Sprintf("#%v created: %v", ev.Item.Number, ev.Item.Title)
+	case StatusChanged:
+		return fmt.Sprintf("#%v status changed: %v -> %v", ev.Item.Number, ev.Previous.Status, ev.Item.Status)
+	case Reassigned:
+		return fmt.Sprintf("#%v reassigned: %v -> %v",
+			ev.Item.Number, userEmail(ev.Previous.AssignedTo), userEmail(ev.Item.AssignedTo))
+	case Rescored:
+		return fmt.Sprintf("#%v rescored: %v -> %v", ev.Item.Number, ev.Previous.Score, ev.Item.Score)
+	case Retagged:
+		return fmt.Sprintf("#%v retagged: %v -> %v", ev.Item.Number, ev.Previous.Tags, ev.Item.Tags)
+	default:
+		return fmt.Sprintf("#%v %v", ev.Item.Number, ev.Kind)
+	}
+}
+
+// checkpoint is the snapshot persisted between polls.
+type checkpoint struct {
+	ProductId int `json:"product"`
+
+	// LastModified is the latest Item.LastModified seen.
+	LastModified *time.Time `json:"last_modified,omitempty"`
+
+	// Items maps the item numbers to the items as seen by the last poll.
+	Items map[int]*sprintly.Item `json:"items"`
+}
+
+// Watcher polls a product and emits the item changes.
+type Watcher struct {
+	Client    *sprintly.Client
+	ProductId int
+
+	// Interval is the time between two polls, DefaultInterval when zero.
+	Interval time.Duration
+
+	// Checkpoint is the path of the file the snapshot is kept in.
+	// The snapshot is only kept in memory when empty.
+	Checkpoint string
+
+	// ErrorLog logs the failed polls and the items skipped,
+	// the standard logger is used when nil.
+	ErrorLog *log.Logger
+
+	cp *checkpoint
+}
+
+// Run polls the product and sends the changes to events until the context is cancelled.
+//
+// Failed polls are logged and retried after the interval. Run only returns
+// when the context is cancelled or the checkpoint cannot be read or written.
+func (w *Watcher) Run(ctx context.Context, events chan<- Event) error {
+	interval := w.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		changes, err := w.Poll()
+		switch err.(type) {
+		case nil:
+			if err := w.deliver(ctx, events, changes); err != nil {
+				return err
+			}
+		case *checkpointError:
+			return err
+		default:
+			w.logf("watch: product %v: %v", w.ProductId, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// deliver sends the events and commits the snapshot once they are all received.
+func (w *Watcher) deliver(ctx context.Context, events chan<- Event, changes []Event) error {
+	for _, ev := range changes {
+		select {
+		case events <- ev:
+		case <-ctx.Done():
+			// Forget the snapshot so that the changes are emitted again.
+			w.cp = nil
+			return ctx.Err()
+		}
+	}
+	return w.Commit()
+}
+
+// Poll fetches the items modified since the previous poll and returns the changes.
+//
+// The snapshot is updated in memory only, Commit stores it in the checkpoint file
+// once the changes are processed. Run takes care of both. The changes of an item
+// that cannot be compared with its previous version are logged and skipped.
+func (w *Watcher) Poll() ([]Event, error) {
+	if w.cp == nil {
+		cp, err := w.load()
+		if err != nil {
+			return nil, &checkpointError{err}
+		}
+		if cp == nil {
+			// No snapshot yet, take it without emitting anything.
+			return nil, w.baseline()
+		}
+		w.cp = cp
+	}
+
+	// Items modified in the same second as the latest item seen are listed again,
+	// they produce no events unless they really changed since.
+	var modified []sprintly.Item
+	for offset := 0; ; offset += pageSize {
+		page, _, err := w.Client.Items.List(w.ProductId, &sprintly.ItemListArgs{
+			Status:   sprintly.ItemStatuses,
+			Children: true,
+			OrderBy:  sprintly.ItemOrderingRecent,
+			Offset:   offset,
+			Limit:    pageSize,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		done := len(page) < pageSize
+		for _, item := range page {
+			since := w.cp.LastModified
+			if since != nil && item.LastModified != nil && item.LastModified.Before(*since) {
+				done = true
+				break
+			}
+			modified = append(modified, item)
+		}
+		if done {
+			break
+		}
+	}
+
+	// Emit the changes in the order they happened.
+	slices.Reverse(modified)
+
+	var events []Event
+	for i := range modified {
+		item := &modified[i]
+		changes, err := compare(w.cp.Items[item.Number], item)
+		if err != nil {
+			// Failing the poll would fail all the following ones as well.
+			w.logf("watch: product %v: skipping the changes of #%v: %v", w.ProductId, item.Number, err)
+			continue
+		}
+		events = append(events, changes...)
+	}
+
+	// Only update the snapshot once the poll cannot fail any more.
+	for i := range modified {
+		w.cp.observe(&modified[i])
+	}
+	return events, nil
+}
+
+// Commit stores the snapshot in the checkpoint file.
+func (w *Watcher) Commit() error {
+	if w.Checkpoint == "" || w.cp == nil {
+		return nil
+	}
+
+	content, err := json.Marshal(w.cp)
+	if err != nil {
+		return &checkpointError{err}
+	}
+	if err := atomicfile.WriteFile(w.Checkpoint, content); err != nil {
+		return &checkpointError{err}
+	}
+	return nil
+}
+
+// baseline takes the snapshot of all the product items.
+func (w *Watcher) baseline() error {
+	items, _, err := w.Client.Items.ListAll(w.ProductId, &sprintly.ItemListArgs{
+		Status:   sprintly.ItemStatuses,
+		Children: true,
+		Limit:    pageSize,
+	})
+	if err != nil {
+		return err
+	}
+
+	cp := &checkpoint{
+		ProductId: w.ProductId,
+		Items:     make(map[int]*sprintly.Item, len(items)),
+	}
+	for i := range items {
+		cp.observe(&items[i])
+	}
+	w.cp = cp
+	return w.Commit()
+}
+
+// compare returns the events describing the changes from previous to current.
+func compare(previous, current *sprintly.Item) ([]Event, error) {
+	if previous == nil {
+		return []Event{{ItemCreated, current, nil}}, nil
+	}
+
+	changes, err := sprintly.DiffItems(previous, current)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []Event
+	if changes.Status != nil {
+		events = append(events, Event{StatusChanged, current, previous})
+	}
+	if changes.AssignedTo != nil {
+		events = append(events, Event{Reassigned, current, previous})
+	}
+	if changes.Score != nil {
+		events = append(events, Event{Rescored, current, previous})
+	}
+	if changes.Tags != nil {
+		events = append(events, Event{Retagged, current, previous})
+	}
+	return events, nil
+}
+
+func (cp *checkpoint) observe(item *sprintly.Item) {
+	cp.Items[item.Number] = item
+	if item.LastModified == nil {
+		return
+	}
+	if cp.LastModified == nil || item.LastModified.After(*cp.LastModified) {
+		t := *item.LastModified
+		cp.LastModified = &t
+	}
+}
+
+// load reads the checkpoint file, it returns nil when there is none.
+func (w *Watcher) load() (*checkpoint, error) {
+	if w.Checkpoint == "" {
+		return nil, nil
+	}
+
+	content, err := os.ReadFile(w.Checkpoint)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var cp checkpoint
+	if err := json.Unmarshal(content, &cp); err != nil {
+		return nil, err
+	}
+	if cp.ProductId != w.ProductId {
+		return nil, fmt.Errorf("%v watches product %v, not %v", w.Checkpoint, cp.ProductId, w.ProductId)
+	}
+	if cp.Items == nil {
+		cp.Items = make(map[int]*sprintly.Item)
+	}
+	return &cp, nil
+}
+
+func (w *Watcher) logf(format string, args ...interface{}) {
+	if w.ErrorLog != nil {
+		w.ErrorLog.Printf(format, args...)
+	} else {
+		log.Printf(format, args...)
+	}
+}
+
+// checkpointError is returned when the checkpoint cannot be read or written.
+type checkpointError struct {
+	Err error
+}
+
+func (err *checkpointError) Error() string {
+	return "watch: checkpoint: " + err.Err.Error()
+}
+
+func (err *checkpointError) Unwrap() error {
+	return err.Err
+}
+
+func userEmail(user *sprintly.User) string {
+	if user == nil {
+		return "nobody"
+	}
+	return user.Email
+}
@@ -0,0 +1,179 @@
+package watch
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/salsita/go-sprintly/internal/sprintlytest"
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+// newServer serves product 1 with the given items, modified a second apart.
+func newServer(t *testing.T, items ...sprintly.Item) *sprintlytest.Server {
+	s := sprintlytest.NewServer(t)
+	for _, item := range items {
+		s.PutItem(1, item)
+	}
+	return s
+}
+
+var (
+	joe   = &sprintly.User{Id: 1, Email: "joe@joestump.net"}
+	ondra = &sprintly.User{Id: 2, Email: "ondra@salsitasoft.com"}
+)
+
+func eventKinds(events []Event) []string {
+	var kinds []string
+	for _, ev := range events {
+		kinds = append(kinds, strconv.Itoa(ev.Item.Number)+":"+string(ev.Kind))
+	}
+	return kinds
+}
+
+func TestWatcher_Poll(t *testing.T) {
+	product := newServer(t,
+		sprintly.Item{Number: 1, Status: "backlog", Score: "S", Tags: []string{"api", "cli"}},
+		sprintly.Item{Number: 2, Status: "backlog", AssignedTo: joe},
+		sprintly.Item{Number: 3, Status: "in-progress"},
+	)
+	client := product.Client
+	checkpoint := filepath.Join(t.TempDir(), "watch.json")
+
+	w := &Watcher{Client: client, ProductId: 1, Checkpoint: checkpoint}
+	events, err := w.Poll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 0 {
+		t.Fatalf("the first poll emitted %v", eventKinds(events))
+	}
+
+	product.PutItem(1, sprintly.Item{Number: 2, Status: "in-progress", AssignedTo: ondra})
+	// Reordering the tags is not a change.
+	product.PutItem(1, sprintly.Item{Number: 1, Status: "backlog", Score: "M", Tags: []string{"cli", "api"}})
+	product.PutItem(1, sprintly.Item{Number: 4, Status: "backlog", Title: "Watch items"})
+	product.PutItem(1, sprintly.Item{Number: 1, Status: "backlog", Score: "M", Tags: []string{"api"}})
+
+	events, err = w.Poll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Item 1 is listed once, as last modified.
+	want := []string{"2:status", "2:reassigned", "4:created", "1:rescored", "1:retagged"}
+	if got := eventKinds(events); !slices.Equal(got, want) {
+		t.Errorf("got events %v, want %v", got, want)
+	}
+	if ev := events[0]; ev.Previous.Status != "backlog" || ev.Item.Status != "in-progress" {
+		t.Errorf("unexpected status change: %v", ev)
+	}
+	if got := events[1].String(); got != "#2 reassigned: joe@joestump.net -> ondra@salsitasoft.com" {
+		t.Errorf("String() = %q", got)
+	}
+
+	if events, _ := w.Poll(); len(events) != 0 {
+		t.Errorf("nothing changed, yet the poll emitted %v", eventKinds(events))
+	}
+	if err := w.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A restarted watcher picks up from the checkpoint.
+	product.PutItem(1, sprintly.Item{Number: 3, Status: "completed"})
+
+	w = &Watcher{Client: client, ProductId: 1, Checkpoint: checkpoint}
+	events, err = w.Poll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := eventKinds(events); !slices.Equal(got, []string{"3:status"}) {
+		t.Errorf("got events %v after a restart", got)
+	}
+
+	w = &Watcher{Client: client, ProductId: 2, Checkpoint: checkpoint}
+	if _, err := w.Poll(); err == nil {
+		t.Error("checkpoint of another product accepted")
+	}
+}
+
+func TestWatcher_Poll_Malformed(t *testing.T) {
+	product := newServer(t,
+		sprintly.Item{Number: 1, Status: "backlog"},
+		sprintly.Item{Number: 2, Status: "backlog"},
+	)
+	var logged bytes.Buffer
+	w := &Watcher{Client: product.Client, ProductId: 1, ErrorLog: log.New(&logged, "", 0)}
+	if _, err := w.Poll(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The parent of item 1 cannot be parsed, the other changes are still emitted.
+	product.PutItem(1, sprintly.Item{Number: 1, Status: "in-progress", Parent: map[string]interface{}{}})
+	product.PutItem(1, sprintly.Item{Number: 2, Status: "completed"})
+	events, err := w.Poll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := eventKinds(events); !slices.Equal(got, []string{"2:status"}) {
+		t.Errorf("got events %v", got)
+	}
+	if !strings.Contains(logged.String(), "skipping the changes of #1") {
+		t.Errorf("logged %q", logged.String())
+	}
+	if events, err := w.Poll(); err != nil || len(events) != 0 {
+		t.Errorf("the next poll returned %v, %v", eventKinds(events), err)
+	}
+}
+
+func TestWatcher_Run(t *testing.T) {
+	product := newServer(t, sprintly.Item{Number: 1, Status: "backlog"})
+	checkpoint := filepath.Join(t.TempDir(), "watch.json")
+
+	w := &Watcher{
+		Client:     product.Client,
+		ProductId:  1,
+		Interval:   10 * time.Millisecond,
+		Checkpoint: checkpoint,
+		ErrorLog:   log.New(io.Discard, "", 0),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := make(chan Event)
+	done := make(chan error, 1)
+	go func() {
+		done <- w.Run(ctx, events)
+	}()
+
+	// Wait for the baseline to be taken.
+	time.Sleep(50 * time.Millisecond)
+	product.PutItem(1, sprintly.Item{Number: 1, Status: "in-progress"})
+
+	select {
+	case ev := <-events:
+		if ev.Kind != StatusChanged || ev.Item.Number != 1 {
+			t.Errorf("unexpected event: %v", ev)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event received")
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Run returned %v", err)
+	}
+
+	// The delivered change is not replayed.
+	w = &Watcher{Client: w.Client, ProductId: 1, Checkpoint: checkpoint}
+	if events, err := w.Poll(); err != nil || len(events) != 0 {
+		t.Errorf("restarted watcher emitted %v, %v", eventKinds(events), err)
+	}
+}
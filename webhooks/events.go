@@ -0,0 +1,225 @@
+// Package webhooks receives the events Sprintly pushes to the product webhook URL.
+//
+// Sprintly posts a JSON payload describing what happened to which model:
+//
+//	{
+//		"model": "Item",
+//		"action": "updated",
+//		"product": {"id": 1, "name": "Sprintly"},
+//		"user": {"id": 2, "email": "joe@joestump.net"},
+//		"attributes": {"number": 188, "status": "in-progress", ...},
+//		"changes": {"status": ["backlog", "in-progress"]}
+//	}
+//
+// The package is experimental. The webhook payloads are not covered by the Sprintly
+// API documentation and no real deliveries were captured, so this shape is an assumption
+// modelled on the API resources, the attributes being decoded as the item, comment
+// and deploy resources are. The payloads in testdata are hand-written to match it.
+// Check the shape against real deliveries, e.g. by logging the payloads, before relying
+// on the events; the types may change once it is confirmed.
+//
+// Handler parses the payloads into typed events and dispatches them to the callbacks:
+//
+//	h := webhooks.NewHandler()
+//	h.OnItemStatusChanged(func(ev *webhooks.ItemStatusChanged) error {
+//		return chat.Post(fmt.Sprintf("#%v is %v now", ev.Item.Number, ev.To))
+//	})
+//	http.Handle("/sprintly", h)
+package webhooks
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+// Event is implemented by all the event types.
+type Event interface {
+	// Header returns the fields common to all the events.
+	Header() *EventHeader
+}
+
+// EventHeader holds the fields common to all the events.
+type EventHeader struct {
+	Model   string
+	Action  string
+	Product *sprintly.Product
+
+	// User is the user who triggered the event, nil when not known.
+	User *sprintly.User
+}
+
+func (h *EventHeader) Header() *EventHeader {
+	return h
+}
+
+// Change represents a single field changed by an update.
+type Change struct {
+	Old interface{}
+	New interface{}
+}
+
+// ItemCreated is emitted when a new item is created.
+type ItemCreated struct {
+	EventHeader
+	Item *sprintly.Item
+}
+
+// ItemUpdated is emitted when an item is updated, including status changes.
+type ItemUpdated struct {
+	EventHeader
+	Item *sprintly.Item
+
+	// Changes maps the changed fields to their old and new values.
+	Changes map[string]Change
+}
+
+// ItemStatusChanged is emitted on top of ItemUpdated when the item status changes.
+type ItemStatusChanged struct {
+	EventHeader
+	Item *sprintly.Item
+	From sprintly.ItemStatus
+	To   sprintly.ItemStatus
+}
+
+// CommentAdded is emitted when an item is commented on.
+type CommentAdded struct {
+	EventHeader
+	ItemNumber int
+	Comment    *sprintly.Comment
+}
+
+// DeployCreated is emitted when a deploy is recorded.
+type DeployCreated struct {
+	EventHeader
+	Deploy *sprintly.Deploy
+}
+
+// payload is the webhook payload as posted by Sprintly.
+type payload struct {
+	Model      string                       `json:"model"`
+	Action     string                       `json:"action"`
+	Product    *sprintly.Product            `json:"product"`
+	User       *sprintly.User               `json:"user"`
+	Attributes json.RawMessage              `json:"attributes"`
+	Changes    map[string][]json.RawMessage `json:"changes"`
+}
+
+// commentAttributes are the attributes of a comment, which refer to the item commented on.
+type commentAttributes struct {
+	sprintly.Comment
+	Item *struct {
+		Number int `json:"number"`
+	} `json:"item"`
+}
+
+// ErrMalformed is wrapped by the errors returned for invalid payloads.
+var ErrMalformed = errors.New("webhooks: malformed payload")
+
+// ErrUnsupported is returned by Parse for well-formed payloads of unsupported
+// models or actions. Handler acknowledges such payloads and ignores them.
+var ErrUnsupported = errors.New("webhooks: unsupported event")
+
+// Parse parses the given payload into events.
+//
+// Most payloads produce a single event, an item update changing the status
+// produces ItemUpdated followed by ItemStatusChanged.
+func Parse(data []byte) ([]Event, error) {
+	var p payload
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	if p.Model == "" || p.Action == "" {
+		return nil, fmt.Errorf("%w: model or action missing", ErrMalformed)
+	}
+	if len(p.Attributes) == 0 || string(p.Attributes) == "null" {
+		return nil, fmt.Errorf("%w: attributes missing", ErrMalformed)
+	}
+	header := EventHeader{Model: p.Model, Action: p.Action, Product: p.Product, User: p.User}
+
+	switch {
+	case p.Model == "Item" && p.Action == "created":
+		item, err := parseItem(p.Attributes)
+		if err != nil {
+			return nil, err
+		}
+		return []Event{&ItemCreated{header, item}}, nil
+
+	case p.Model == "Item" && p.Action == "updated":
+		item, err := parseItem(p.Attributes)
+		if err != nil {
+			return nil, err
+		}
+		changes, err := parseChanges(p.Changes)
+		if err != nil {
+			return nil, err
+		}
+		events := []Event{&ItemUpdated{header, item, changes}}
+
+		if change, ok := changes["status"]; ok {
+			from, _ := change.Old.(string)
+			to, _ := change.New.(string)
+			events = append(events, &ItemStatusChanged{
+				EventHeader: header,
+				Item:        item,
+				From:        sprintly.ItemStatus(from),
+				To:          sprintly.ItemStatus(to),
+			})
+		}
+		return events, nil
+
+	case p.Model == "Comment" && p.Action == "created":
+		var attrs commentAttributes
+		if err := json.Unmarshal(p.Attributes, &attrs); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+		}
+		if attrs.Item == nil || attrs.Item.Number == 0 {
+			return nil, fmt.Errorf("%w: comment item missing", ErrMalformed)
+		}
+		return []Event{&CommentAdded{header, attrs.Item.Number, &attrs.Comment}}, nil
+
+	case p.Model == "Deploy" && p.Action == "created":
+		var deploy sprintly.Deploy
+		if err := json.Unmarshal(p.Attributes, &deploy); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+		}
+		if deploy.Environment == "" {
+			return nil, fmt.Errorf("%w: deploy environment missing", ErrMalformed)
+		}
+		return []Event{&DeployCreated{header, &deploy}}, nil
+
+	default:
+		return nil, fmt.Errorf("%w: %v %v", ErrUnsupported, p.Model, p.Action)
+	}
+}
+
+func parseItem(attributes json.RawMessage) (*sprintly.Item, error) {
+	var item sprintly.Item
+	if err := json.Unmarshal(attributes, &item); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	if item.Number == 0 {
+		return nil, fmt.Errorf("%w: item number missing", ErrMalformed)
+	}
+	return &item, nil
+}
+
+func parseChanges(raw map[string][]json.RawMessage) (map[string]Change, error) {
+	changes := make(map[string]Change, len(raw))
+	for field, values := range raw {
+		if len(values) != 2 {
+			return nil, fmt.Errorf("%w: change of %v is not an [old, new] pair", ErrMalformed, field)
+		}
+		var change Change
+		if err := json.Unmarshal(values[0], &change.Old); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+		}
+		if err := json.Unmarshal(values[1], &change.New); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+		}
+		changes[field] = change
+	}
+	return changes, nil
+}
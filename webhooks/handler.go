@@ -0,0 +1,185 @@
+package webhooks
+
+import (
+	"crypto/subtle"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"sync"
+)
+
+// MaxPayloadSize limits the size of the payloads accepted by Handler.
+const MaxPayloadSize = 1 << 20
+
+// Handler is an http.Handler receiving Sprintly webhooks.
+//
+// Malformed payloads are rejected with 400 Bad Request, payloads of unsupported
+// models or actions are acknowledged and ignored. Every callback is called even
+// when some of them fail, 500 Internal Server Error is returned then so that
+// the delivery can be retried. A retry calls all the callbacks again, including
+// those that succeeded before, so the callbacks must be idempotent.
+type Handler struct {
+	// Token, when set, must be passed in the token query parameter,
+	// i.e. the webhook URL configured in Sprintly must end with ?token=<Token>.
+	Token string
+
+	// ErrorLog logs the callback errors, the standard logger is used when nil.
+	ErrorLog *log.Logger
+
+	mu              sync.RWMutex
+	onEvent         []func(Event) error
+	onItemCreated   []func(*ItemCreated) error
+	onItemUpdated   []func(*ItemUpdated) error
+	onStatusChanged []func(*ItemStatusChanged) error
+	onCommentAdded  []func(*CommentAdded) error
+	onDeployCreated []func(*DeployCreated) error
+}
+
+// NewHandler returns a handler with no callbacks registered.
+func NewHandler() *Handler {
+	return &Handler{}
+}
+
+// OnEvent registers a callback called for every event, before the typed callbacks.
+func (h *Handler) OnEvent(fn func(Event) error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onEvent = append(h.onEvent, fn)
+}
+
+// OnItemCreated registers a callback for ItemCreated events.
+func (h *Handler) OnItemCreated(fn func(*ItemCreated) error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onItemCreated = append(h.onItemCreated, fn)
+}
+
+// OnItemUpdated registers a callback for ItemUpdated events.
+func (h *Handler) OnItemUpdated(fn func(*ItemUpdated) error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onItemUpdated = append(h.onItemUpdated, fn)
+}
+
+// OnItemStatusChanged registers a callback for ItemStatusChanged events.
+func (h *Handler) OnItemStatusChanged(fn func(*ItemStatusChanged) error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onStatusChanged = append(h.onStatusChanged, fn)
+}
+
+// OnCommentAdded registers a callback for CommentAdded events.
+func (h *Handler) OnCommentAdded(fn func(*CommentAdded) error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onCommentAdded = append(h.onCommentAdded, fn)
+}
+
+// OnDeployCreated registers a callback for DeployCreated events.
+func (h *Handler) OnDeployCreated(fn func(*DeployCreated) error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onDeployCreated = append(h.onDeployCreated, fn)
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.Token != "" {
+		token := r.URL.Query().Get("token")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(h.Token)) != 1 {
+			http.Error(w, "invalid token", http.StatusUnauthorized)
+			return
+		}
+	}
+
+	data, err := readPayload(w, r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	events, err := Parse(data)
+	switch {
+	case errors.Is(err, ErrUnsupported):
+		w.WriteHeader(http.StatusAccepted)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	failed := false
+	for _, event := range events {
+		if err := h.Dispatch(event); err != nil {
+			h.logf("webhooks: %v %v: %v", event.Header().Model, event.Header().Action, err)
+			failed = true
+		}
+	}
+	if failed {
+		http.Error(w, "callback failed", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Dispatch calls all the callbacks registered for the event,
+// returning the errors of those that failed joined.
+func (h *Handler) Dispatch(event Event) error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	errs := dispatch(h.onEvent, event)
+
+	switch ev := event.(type) {
+	case *ItemCreated:
+		errs = append(errs, dispatch(h.onItemCreated, ev)...)
+	case *ItemUpdated:
+		errs = append(errs, dispatch(h.onItemUpdated, ev)...)
+	case *ItemStatusChanged:
+		errs = append(errs, dispatch(h.onStatusChanged, ev)...)
+	case *CommentAdded:
+		errs = append(errs, dispatch(h.onCommentAdded, ev)...)
+	case *DeployCreated:
+		errs = append(errs, dispatch(h.onDeployCreated, ev)...)
+	}
+	return errors.Join(errs...)
+}
+
+func dispatch[E any](callbacks []func(E) error, event E) []error {
+	var errs []error
+	for _, fn := range callbacks {
+		if err := fn(event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// readPayload reads the payload posted either as the request body,
+// or as the payload field of a form.
+func readPayload(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, MaxPayloadSize)
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "application/x-www-form-urlencoded" {
+		if err := r.ParseForm(); err != nil {
+			return nil, err
+		}
+		return []byte(r.PostForm.Get("payload")), nil
+	}
+	return io.ReadAll(r.Body)
+}
+
+func (h *Handler) logf(format string, args ...interface{}) {
+	if h.ErrorLog != nil {
+		h.ErrorLog.Printf(format, args...)
+	} else {
+		log.Printf(format, args...)
+	}
+}
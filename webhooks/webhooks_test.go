@@ -0,0 +1,310 @@
+package webhooks
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/salsita/go-sprintly/sprintly"
+)
+
+// payloadFile returns the hand-written payload of the given name, see testdata/README.md.
+func payloadFile(t *testing.T, name string) []byte {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join("testdata", name+".json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return content
+}
+
+func post(t *testing.T, h http.Handler, target, contentType string, body []byte) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest("POST", target, bytes.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandler_ItemCreated(t *testing.T) {
+	h := NewHandler()
+
+	var got *ItemCreated
+	h.OnItemCreated(func(ev *ItemCreated) error {
+		got = ev
+		return nil
+	})
+	h.OnItemUpdated(func(*ItemUpdated) error {
+		t.Error("OnItemUpdated called for a new item")
+		return nil
+	})
+
+	rec := post(t, h, "/", "application/json", payloadFile(t, "item_created"))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("status = %v, body = %q", rec.Code, rec.Body)
+	}
+	if got == nil {
+		t.Fatal("OnItemCreated not called")
+	}
+
+	if got.Model != "Item" || got.Action != "created" || got.Product.Id != 1 || got.User.Id != 2 {
+		t.Errorf("unexpected header: %+v", got.EventHeader)
+	}
+	item := got.Item
+	if item.Number != 188 || item.Score != sprintly.ItemScoreMedium || item.What != "receive webhooks" {
+		t.Errorf("unexpected item: %+v", item)
+	}
+	if item.AssignedTo == nil || item.AssignedTo.Email != "ondra@salsitasoft.com" {
+		t.Errorf("AssignedTo = %+v", item.AssignedTo)
+	}
+}
+
+func TestHandler_ItemUpdated(t *testing.T) {
+	h := NewHandler()
+
+	var got *ItemUpdated
+	h.OnItemUpdated(func(ev *ItemUpdated) error {
+		got = ev
+		return nil
+	})
+	h.OnItemStatusChanged(func(*ItemStatusChanged) error {
+		t.Error("OnItemStatusChanged called while the status is the same")
+		return nil
+	})
+
+	rec := post(t, h, "/", "application/json", payloadFile(t, "item_updated"))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("status = %v, body = %q", rec.Code, rec.Body)
+	}
+	if got == nil {
+		t.Fatal("OnItemUpdated not called")
+	}
+
+	if got.Item.Score != sprintly.ItemScoreLarge {
+		t.Errorf("Score = %v", got.Item.Score)
+	}
+	ensureChange(t, got.Changes, "score", "M", "L")
+	if tags := got.Changes["tags"].New.([]interface{}); len(tags) != 3 {
+		t.Errorf("new tags = %v", tags)
+	}
+}
+
+func TestHandler_ItemStatusChanged(t *testing.T) {
+	h := NewHandler()
+
+	var order []string
+	h.OnEvent(func(ev Event) error {
+		order = append(order, "event")
+		return nil
+	})
+	h.OnItemUpdated(func(ev *ItemUpdated) error {
+		order = append(order, "updated")
+		ensureChange(t, ev.Changes, "status", "backlog", "in-progress")
+		return nil
+	})
+	h.OnItemStatusChanged(func(ev *ItemStatusChanged) error {
+		order = append(order, "status")
+		if ev.From != sprintly.ItemStatusBacklog || ev.To != sprintly.ItemStatusInProgress {
+			t.Errorf("status changed from %v to %v", ev.From, ev.To)
+		}
+		if ev.Item.Progress == nil || ev.Item.Progress.StartedAt == nil {
+			t.Errorf("Progress = %+v", ev.Item.Progress)
+		}
+		return nil
+	})
+
+	rec := post(t, h, "/", "application/json", payloadFile(t, "item_status_changed"))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("status = %v, body = %q", rec.Code, rec.Body)
+	}
+	if got := strings.Join(order, ","); got != "event,updated,event,status" {
+		t.Errorf("callbacks called in order %v", got)
+	}
+}
+
+func TestHandler_CommentAdded(t *testing.T) {
+	h := NewHandler()
+
+	var got *CommentAdded
+	h.OnCommentAdded(func(ev *CommentAdded) error {
+		got = ev
+		return nil
+	})
+
+	// Post the payload as a form to check that works too.
+	form := url.Values{"payload": {string(payloadFile(t, "comment_added"))}}
+	rec := post(t, h, "/", "application/x-www-form-urlencoded", []byte(form.Encode()))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("status = %v, body = %q", rec.Code, rec.Body)
+	}
+	if got == nil {
+		t.Fatal("OnCommentAdded not called")
+	}
+
+	if got.ItemNumber != 188 {
+		t.Errorf("ItemNumber = %v", got.ItemNumber)
+	}
+	comment := got.Comment
+	if comment.Id != 4410 || !strings.HasPrefix(comment.Body, "Started on the handler") || comment.CreatedAt == nil {
+		t.Errorf("unexpected comment: %+v", comment)
+	}
+	if comment.CreatedBy == nil || comment.CreatedBy.FirstName != "Ondrej" {
+		t.Errorf("CreatedBy = %+v", comment.CreatedBy)
+	}
+}
+
+func TestHandler_DeployCreated(t *testing.T) {
+	h := NewHandler()
+
+	var got *DeployCreated
+	h.OnDeployCreated(func(ev *DeployCreated) error {
+		got = ev
+		return nil
+	})
+
+	rec := post(t, h, "/", "application/json", payloadFile(t, "deploy_created"))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("status = %v, body = %q", rec.Code, rec.Body)
+	}
+	if got == nil {
+		t.Fatal("OnDeployCreated not called")
+	}
+
+	deploy := got.Deploy
+	if deploy.Environment != "production" || deploy.CreatedAt == nil || len(deploy.Items) != 2 {
+		t.Errorf("unexpected deploy: %+v", deploy)
+	}
+	if deploy.Items[1].Type != "defect" {
+		t.Errorf("second item = %+v", deploy.Items[1])
+	}
+}
+
+func TestHandler_Malformed(t *testing.T) {
+	h := NewHandler()
+	h.OnEvent(func(Event) error {
+		t.Error("callback called for a malformed payload")
+		return nil
+	})
+
+	payloads := map[string][]byte{
+		"truncated":          payloadFile(t, "malformed"),
+		"not an object":      []byte(`["Item", "created"]`),
+		"missing model":      []byte(`{"action": "created", "attributes": {"number": 1}}`),
+		"missing attrs":      []byte(`{"model": "Item", "action": "created"}`),
+		"missing number":     []byte(`{"model": "Item", "action": "created", "attributes": {"title": "x"}}`),
+		"invalid change":     []byte(`{"model": "Item", "action": "updated", "attributes": {"number": 1}, "changes": {"status": ["backlog"]}}`),
+		"comment item":       []byte(`{"model": "Comment", "action": "created", "attributes": {"body": "x"}}`),
+		"deploy environment": []byte(`{"model": "Deploy", "action": "created", "attributes": {"items": []}}`),
+	}
+	for name, payload := range payloads {
+		if rec := post(t, h, "/", "application/json", payload); rec.Code != http.StatusBadRequest {
+			t.Errorf("%v: status = %v, want 400", name, rec.Code)
+		}
+		if _, err := Parse(payload); !errors.Is(err, ErrMalformed) {
+			t.Errorf("%v: Parse error = %v", name, err)
+		}
+	}
+
+	big := bytes.Repeat([]byte(" "), MaxPayloadSize+1)
+	if rec := post(t, h, "/", "application/json", big); rec.Code != http.StatusBadRequest {
+		t.Errorf("payload over the limit: status = %v, want 400", rec.Code)
+	}
+}
+
+func TestHandler_Unsupported(t *testing.T) {
+	h := NewHandler()
+	h.OnEvent(func(Event) error {
+		t.Error("callback called for an unsupported payload")
+		return nil
+	})
+
+	payload := []byte(`{"model": "Item", "action": "deleted", "attributes": {"number": 188}}`)
+	if rec := post(t, h, "/", "application/json", payload); rec.Code != http.StatusAccepted {
+		t.Errorf("status = %v, want 202", rec.Code)
+	}
+	if _, err := Parse(payload); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Parse error = %v", err)
+	}
+}
+
+func TestHandler_Requests(t *testing.T) {
+	h := NewHandler()
+	h.Token = "s3cret"
+	h.ErrorLog = log.New(io.Discard, "", 0)
+	h.OnDeployCreated(func(*DeployCreated) error {
+		return errors.New("chat is down")
+	})
+	payload := payloadFile(t, "deploy_created")
+
+	req := httptest.NewRequest("GET", "/?token=s3cret", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") != "POST" {
+		t.Errorf("GET: status = %v, Allow = %q", rec.Code, rec.Header().Get("Allow"))
+	}
+
+	for _, target := range []string{"/", "/?token=secret"} {
+		if rec := post(t, h, target, "application/json", payload); rec.Code != http.StatusUnauthorized {
+			t.Errorf("%v: status = %v, want 401", target, rec.Code)
+		}
+	}
+
+	if rec := post(t, h, "/?token=s3cret", "application/json", payload); rec.Code != http.StatusInternalServerError {
+		t.Errorf("failing callback: status = %v, want 500", rec.Code)
+	}
+}
+
+func TestHandler_Dispatch(t *testing.T) {
+	h := NewHandler()
+	var called []string
+	h.OnEvent(func(Event) error {
+		called = append(called, "event")
+		return errors.New("log is full")
+	})
+	h.OnItemUpdated(func(*ItemUpdated) error {
+		called = append(called, "updated")
+		return nil
+	})
+	h.OnItemStatusChanged(func(*ItemStatusChanged) error {
+		called = append(called, "status")
+		return errors.New("chat is down")
+	})
+
+	events, err := Parse(payloadFile(t, "item_status_changed"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var errs []error
+	for _, event := range events {
+		if err := h.Dispatch(event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if got := strings.Join(called, ","); got != "event,updated,event,status" {
+		t.Errorf("callbacks called in order %v", got)
+	}
+	if err := errors.Join(errs...); err == nil || !strings.Contains(err.Error(), "chat is down") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func ensureChange(t *testing.T, changes map[string]Change, field string, old, new interface{}) {
+	t.Helper()
+	change, ok := changes[field]
+	if !ok {
+		t.Errorf("change of %v missing", field)
+		return
+	}
+	if change.Old != old || change.New != new {
+		t.Errorf("%v changed from %v to %v, want from %v to %v", field, change.Old, change.New, old, new)
+	}
+}